@@ -0,0 +1,121 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"math"
+)
+
+// ArithmeticOption customizes the behavior of Update.Increment and Update.Decrement
+type ArithmeticOption interface {
+	ApplyArithmetic(o *arithmeticOptions)
+}
+
+type arithmeticOptions struct {
+	floor   *int64
+	ceiling *int64
+}
+
+type arithmeticFunc func(o *arithmeticOptions)
+
+func (fn arithmeticFunc) ApplyArithmetic(o *arithmeticOptions) {
+	fn(o)
+}
+
+// WithFloor fails the update with ErrConditionFailed if the result would be less than
+// floor e.g. inventory never below zero
+func WithFloor(floor int64) ArithmeticOption {
+	return arithmeticFunc(func(o *arithmeticOptions) {
+		o.floor = &floor
+	})
+}
+
+// WithCeiling fails the update with ErrConditionFailed if the result would be greater
+// than ceiling
+func WithCeiling(ceiling int64) ArithmeticOption {
+	return arithmeticFunc(func(o *arithmeticOptions) {
+		o.ceiling = &ceiling
+	})
+}
+
+// Increment adds n to the number attribute identified by name e.g. #Counter.  Missing
+// attributes are treated as zero.
+func (u *Update) Increment(name string, n int64, opts ...ArithmeticOption) *Update {
+	return u.arithmetic(name, n, opts)
+}
+
+// Decrement subtracts n from the number attribute identified by name e.g. #Counter.
+// Missing attributes are treated as zero.
+func (u *Update) Decrement(name string, n int64, opts ...ArithmeticOption) *Update {
+	if n == math.MinInt64 {
+		u.err = fmt.Errorf("unable to decrement %v: %v overflows", name, n)
+		return u
+	}
+	return u.arithmetic(name, -n, opts)
+}
+
+// arithmetic adds delta to the attribute, name.  As condition expressions do not
+// support arithmetic, bounds are translated into a comparison of the current value.
+func (u *Update) arithmetic(name string, delta int64, opts []ArithmeticOption) *Update {
+	var options arithmeticOptions
+	for _, opt := range opts {
+		opt.ApplyArithmetic(&options)
+	}
+
+	u.Set(name+" = if_not_exists("+name+", ?) + ?", 0, delta)
+
+	// current + delta >= floor  =>  current >= floor - delta
+	if floor := options.floor; floor != nil {
+		min, ok := subtract(*floor, delta)
+		if !ok {
+			u.err = fmt.Errorf("unable to guard %v: floor, %v, overflows", name, *floor)
+			return u
+		}
+		u.guard(name, ">=", min, 0 >= min, fmt.Sprintf("%v would fall below %v", name, *floor))
+	}
+
+	// current + delta <= ceiling  =>  current <= ceiling - delta
+	if ceiling := options.ceiling; ceiling != nil {
+		max, ok := subtract(*ceiling, delta)
+		if !ok {
+			u.err = fmt.Errorf("unable to guard %v: ceiling, %v, overflows", name, *ceiling)
+			return u
+		}
+		u.guard(name, "<=", max, 0 <= max, fmt.Sprintf("%v would rise above %v", name, *ceiling))
+	}
+
+	return u
+}
+
+// guard adds the condition, name op v.  If the guard holds for a missing attribute,
+// missing attributes are also allowed.
+func (u *Update) guard(name, op string, v int64, allowMissing bool, message string) {
+	expr := name + " " + op + " ?"
+	if allowMissing {
+		expr = "(attribute_not_exists(" + name + ") or " + expr + ")"
+	}
+	u.Condition(expr, v)
+	u.guards = append(u.guards, message)
+}
+
+// subtract returns a - b and false if the result overflows
+func subtract(a, b int64) (int64, bool) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return 0, false
+	}
+	return c, true
+}
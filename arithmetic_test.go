@@ -0,0 +1,113 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestUpdate_Increment(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+
+	testCases := map[string]struct {
+		Update        *Update
+		WantUpdate    string
+		WantCondition string
+		WantValues    []string
+	}{
+		"increment": {
+			Update:     table.Update("abc").Range("def").Increment("#Count", 2),
+			WantUpdate: "Set #n1 = if_not_exists(#n1, :v1) + :v2",
+			WantValues: []string{"0", "2"},
+		},
+		"decrement with floor": {
+			Update:        table.Update("abc").Range("def").Decrement("#Count", 3, WithFloor(0)),
+			WantUpdate:    "Set #n1 = if_not_exists(#n1, :v1) + :v2",
+			WantCondition: "#n1 >= :v3",
+			WantValues:    []string{"0", "-3", "3"},
+		},
+		"missing allowed by floor": {
+			Update:        table.Update("abc").Range("def").Decrement("#Count", 3, WithFloor(-5)),
+			WantUpdate:    "Set #n1 = if_not_exists(#n1, :v1) + :v2",
+			WantCondition: "(attribute_not_exists(#n1) or #n1 >= :v3)",
+			WantValues:    []string{"0", "-3", "-2"},
+		},
+		"increment with ceiling": {
+			Update:        table.Update("abc").Range("def").Increment("#Count", 1, WithCeiling(10)),
+			WantUpdate:    "Set #n1 = if_not_exists(#n1, :v1) + :v2",
+			WantCondition: "(attribute_not_exists(#n1) or #n1 <= :v3)",
+			WantValues:    []string{"0", "1", "9"},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			input, err := tc.Update.UpdateItemInput()
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := aws.StringValue(input.UpdateExpression), tc.WantUpdate; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := aws.StringValue(input.ConditionExpression), tc.WantCondition; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			for i, want := range tc.WantValues {
+				key := placeholder(valuePlaceholders, ":v", i+1)
+				if got := aws.StringValue(input.ExpressionAttributeValues[key].N); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("overflow", func(t *testing.T) {
+		_, err := table.Update("abc").Range("def").Increment("#Count", 1, WithFloor(math.MinInt64)).UpdateItemInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+		_, err = table.Update("abc").Range("def").Decrement("#Count", math.MinInt64).UpdateItemInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
+func TestUpdate_DecrementConditionFailed(t *testing.T) {
+	var (
+		original = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "boom", nil)
+		table    = New(&Mock{err: original}).MustTable("example", UpdateTable{})
+	)
+
+	err := table.Update("abc").Range("def").Decrement("#Count", 1, WithFloor(0)).Run()
+	if !hasError(err, ErrConditionFailed) {
+		t.Fatalf("got %v; want ConditionFailed", err)
+	}
+	if !IsConditionFailedError(err) {
+		t.Fatalf("got false; want true")
+	}
+	if !errors.Is(err, original) {
+		t.Fatalf("got %v; want %v", err, original)
+	}
+	if got, want := err.Error(), "ConditionFailed: update failed condition: #Count would fall below 0"; len(got) < len(want) || got[:len(want)] != want {
+		t.Fatalf("got %v; want prefix %v", got, want)
+	}
+}
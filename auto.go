@@ -0,0 +1,114 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+const (
+	AutoKSUID = "ksuid"
+	AutoULID  = "ulid"
+	AutoUUID  = "uuid"
+)
+
+// autoGenerators holds the generators supported by the auto= tag option
+var autoGenerators = map[string]func(now time.Time, random io.Reader) (string, error){
+	AutoKSUID: newKSUID,
+	AutoULID:  newULID,
+	AutoUUID:  newUUID,
+}
+
+// crockford holds the base32 alphabet used by ulid
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ulid (https://github.com/ulid/spec) composed of a 48 bit
+// millisecond timestamp followed by 80 bits of randomness
+func newULID(now time.Time, random io.Reader) (string, error) {
+	var data [16]byte
+	ms := uint64(now.UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(data[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(data[2:6], uint32(ms))
+	if _, err := io.ReadFull(random, data[6:]); err != nil {
+		return "", err
+	}
+
+	// 128 bits encode to 26 characters; the leading character holds 3 bits
+	var (
+		hi  = binary.BigEndian.Uint64(data[0:8])
+		lo  = binary.BigEndian.Uint64(data[8:16])
+		buf [26]byte
+	)
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:]), nil
+}
+
+// newUUID returns a random (version 4) uuid
+func newUUID(_ time.Time, random io.Reader) (string, error) {
+	var data [16]byte
+	if _, err := io.ReadFull(random, data[:]); err != nil {
+		return "", err
+	}
+	data[6] = (data[6] & 0x0f) | 0x40
+	data[8] = (data[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", data[0:4], data[4:6], data[6:8], data[8:10], data[10:]), nil
+}
+
+// autoKey identifies a key field whose value is generated when blank
+type autoKey struct {
+	FieldName string // FieldName from struct
+	Generator string // Generator holds the name of the generator e.g. ksuid
+}
+
+// applyAutoKeys assigns generated values to blank string key fields tagged with the
+// auto= option.  Fields are assigned in place, so v must be a pointer to a struct when
+// any auto key is blank; otherwise the generated key would be lost to the caller.
+func applyAutoKeys(spec *tableSpec, v interface{}, clock Clock, random io.Reader) (interface{}, error) {
+	if len(spec.AutoKeys) == 0 {
+		return v, nil
+	}
+
+	isPtr := reflect.ValueOf(v).Kind() == reflect.Ptr
+	value, v, ok := addressableStruct(v)
+	if !ok {
+		return v, nil
+	}
+
+	for _, key := range spec.AutoKeys {
+		field := value.FieldByName(key.FieldName)
+		if !field.IsValid() || field.Kind() != reflect.String || field.String() != "" {
+			continue
+		}
+		if !isPtr {
+			return nil, errorf(ErrValidation, "unable to generate %v for %v: item must be passed by pointer to receive the generated key", key.Generator, key.FieldName)
+		}
+
+		id, err := autoGenerators[key.Generator](clock.Now(), random)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate %v for %v: %w", key.Generator, key.FieldName, err)
+		}
+		field.SetString(id)
+	}
+
+	return v, nil
+}
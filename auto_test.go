@@ -0,0 +1,93 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"crypto/rand"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestAutoGenerators(t *testing.T) {
+	testCases := map[string]*regexp.Regexp{
+		AutoKSUID: regexp.MustCompile(`^[0-9A-Za-z]{27}$`),
+		AutoULID:  regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		AutoUUID:  regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+	}
+
+	for generator, re := range testCases {
+		t.Run(generator, func(t *testing.T) {
+			id, err := autoGenerators[generator](time.Now(), rand.Reader)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !re.MatchString(id) {
+				t.Fatalf("got %v; want match for %v", id, re)
+			}
+		})
+	}
+}
+
+func TestPut_AutoKey(t *testing.T) {
+	type Sample struct {
+		ID   string `ddb:"hash,auto=ulid"`
+		Name string
+	}
+
+	var (
+		mock  = &Mock{}
+		table = New(mock).MustTable("example", Sample{})
+	)
+
+	t.Run("pointer", func(t *testing.T) {
+		sample := Sample{Name: "abc"}
+		if err := table.Put(&sample).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if sample.ID == "" {
+			t.Fatalf("got blank; want generated id")
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["ID"].S), sample.ID; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("value", func(t *testing.T) {
+		if err := table.Put(Sample{Name: "abc"}).Run(); !IsValidationError(err) {
+			t.Fatalf("got %v; want ErrValidation", err)
+		}
+	})
+
+	t.Run("preserves existing", func(t *testing.T) {
+		if err := table.Put(Sample{ID: "abc"}).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["ID"].S), "abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("unsupported generator", func(t *testing.T) {
+		type Invalid struct {
+			ID string `ddb:"hash,auto=blah"`
+		}
+		if _, err := New(mock).Table("example", Invalid{}); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Breaker is consulted before each call to DynamoDB, keyed by table name and operation
+// e.g. GetItem, so calls to a misbehaving table can be short-circuited.  Allow returns
+// an error to reject the call; otherwise the returned done func records whether the
+// call succeeded.  Conditional check failures and canceled contexts are reported as
+// successes as neither reflects the health of the table.
+//
+// The TwoStepCircuitBreaker from github.com/sony/gobreaker can be adapted directly:
+//
+//	type Breakers struct {
+//		mutex    sync.Mutex
+//		breakers map[string]*gobreaker.TwoStepCircuitBreaker
+//	}
+//
+//	func (b *Breakers) Allow(tableName, operation string) (func(success bool), error) {
+//		b.mutex.Lock()
+//		defer b.mutex.Unlock()
+//
+//		key := tableName + "/" + operation
+//		cb, ok := b.breakers[key]
+//		if !ok {
+//			cb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{Name: key})
+//			b.breakers[key] = cb
+//		}
+//		return cb.Allow()
+//	}
+//
+// Transactions, which may span tables, are not consulted.
+type Breaker interface {
+	Allow(tableName, operation string) (done func(success bool), err error)
+}
+
+// breakerAPI decorates the dynamodb api, consulting the breaker prior to each call
+type breakerAPI struct {
+	dynamodbiface.DynamoDBAPI
+	breaker Breaker
+}
+
+// isBreakerFailure returns true if err indicates the table or service is unhealthy
+func isBreakerFailure(err error) bool {
+	switch {
+	case err == nil || errors.Is(err, context.Canceled):
+		return false
+	case isErrorClass(err, classConditionFailed),
+		hasError(err, dynamodb.ErrCodeTransactionCanceledException),
+		hasError(err, request.CanceledErrorCode):
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *breakerAPI) call(tableName *string, operation string, fn func() error) error {
+	table := aws.StringValue(tableName)
+	done, err := b.breaker.Allow(table, operation)
+	if err != nil {
+		return &baseError{
+			code:      ErrCircuitOpen,
+			message:   fmt.Sprintf("%v on table, %v, rejected by breaker", operation, table),
+			cause:     err,
+			tableName: table,
+		}
+	}
+
+	err = fn()
+	done(!isBreakerFailure(err))
+	return err
+}
+
+func (b *breakerAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (output *dynamodb.DeleteItemOutput, err error) {
+	err = b.call(input.TableName, "DeleteItem", func() (err error) {
+		output, err = b.DynamoDBAPI.DeleteItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (b *breakerAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (output *dynamodb.GetItemOutput, err error) {
+	err = b.call(input.TableName, "GetItem", func() (err error) {
+		output, err = b.DynamoDBAPI.GetItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (b *breakerAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (output *dynamodb.PutItemOutput, err error) {
+	err = b.call(input.TableName, "PutItem", func() (err error) {
+		output, err = b.DynamoDBAPI.PutItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (b *breakerAPI) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (output *dynamodb.QueryOutput, err error) {
+	err = b.call(input.TableName, "Query", func() (err error) {
+		output, err = b.DynamoDBAPI.QueryWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (b *breakerAPI) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (output *dynamodb.ScanOutput, err error) {
+	err = b.call(input.TableName, "Scan", func() (err error) {
+		output, err = b.DynamoDBAPI.ScanWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+func (b *breakerAPI) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (output *dynamodb.UpdateItemOutput, err error) {
+	err = b.call(input.TableName, "UpdateItem", func() (err error) {
+		output, err = b.DynamoDBAPI.UpdateItemWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// countingBreaker opens once failures reach max
+type countingBreaker struct {
+	max       int
+	failures  map[string]int
+	successes map[string]int
+}
+
+func (c *countingBreaker) Allow(tableName, operation string) (func(bool), error) {
+	key := tableName + "/" + operation
+	if c.failures[key] >= c.max {
+		return nil, fmt.Errorf("open")
+	}
+	return func(success bool) {
+		if success {
+			c.successes[key]++
+		} else {
+			c.failures[key]++
+		}
+	}, nil
+}
+
+func TestDDB_WithBreaker(t *testing.T) {
+	var (
+		breaker = &countingBreaker{max: 2, failures: map[string]int{}, successes: map[string]int{}}
+		mock    = &Mock{getItem: Example{ID: "abc"}}
+		table   = New(mock).WithBreaker(breaker).MustTable("example", Example{})
+	)
+
+	if err := table.Get("abc").Scan(&Example{}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := breaker.successes["example/GetItem"], 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	mock.err = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "boom", nil)
+	if err := table.Put(Example{ID: "abc"}).Run(); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+	if got, want := breaker.successes["example/PutItem"], 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	mock.err = awserr.New(dynamodb.ErrCodeInternalServerError, "boom", nil)
+	for i := 0; i < 2; i++ {
+		if err := table.Get("abc").Scan(&Example{}); err == nil || IsCircuitOpenError(err) {
+			t.Fatalf("got %v; want service error", err)
+		}
+	}
+
+	err := table.Get("abc").Scan(&Example{})
+	if !IsCircuitOpenError(err) {
+		t.Fatalf("got %v; want ErrCircuitOpen", err)
+	}
+	if got, want := err.(Error).TableName(), "example"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func Test_isBreakerFailure(t *testing.T) {
+	testCases := map[string]struct {
+		Err  error
+		Want bool
+	}{
+		"nil":          {Err: nil, Want: false},
+		"canceled":     {Err: context.Canceled, Want: false},
+		"conditional":  {Err: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil), Want: false},
+		"throttled":    {Err: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil), Want: true},
+		"other errors": {Err: fmt.Errorf("boom"), Want: true},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got := isBreakerFailure(tc.Err); got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	builder "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// bindBuilderExpression rewrites an expression produced by the expression package,
+// replacing its placeholders, #0 and :0, with #? and ? bound to the corresponding
+// names and values.  This allows the expression to be merged with those already
+// accumulated by ddb without placeholders colliding.
+func bindBuilderExpression(expr string, names map[string]*string, values map[string]*dynamodb.AttributeValue) (string, []interface{}, error) {
+	var (
+		sb    strings.Builder
+		args  []interface{}
+		runes = []rune(expr)
+	)
+
+	sb.Grow(len(expr))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r != '#' && r != ':' {
+			sb.WriteRune(r)
+			i++
+			continue
+		}
+
+		start := i
+		for i++; i < len(runes) && isNameRune(runes[i]); i++ {
+		}
+		token := string(runes[start:i])
+
+		switch r {
+		case '#':
+			name, ok := names[token]
+			if !ok {
+				return "", nil, fmt.Errorf("expression name, %v, not found", token)
+			}
+			sb.WriteString("#?")
+			args = append(args, aws.StringValue(name))
+		case ':':
+			value, ok := values[token]
+			if !ok {
+				return "", nil, fmt.Errorf("expression value, %v, not found", token)
+			}
+			sb.WriteString("?")
+			args = append(args, value)
+		}
+	}
+
+	return sb.String(), args, nil
+}
+
+// builderUpdateClauses splits an update expression produced by the expression package
+// into its SET, REMOVE, ADD, and DELETE clauses, keyed by upper case keyword
+func builderUpdateClauses(expr string) map[string]string {
+	clauses := map[string]string{}
+	for _, line := range strings.Split(expr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		keyword, clause := line, ""
+		if i := strings.IndexByte(line, ' '); i > 0 {
+			keyword, clause = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		clauses[strings.ToUpper(keyword)] = clause
+	}
+	return clauses
+}
+
+// withBuilderExpression binds each part of the built expression, e, via the function
+// registered for it; update expressions are split into their SET, REMOVE, ADD, and
+// DELETE clauses.  Parts without a registered function are rejected.
+func withBuilderExpression(e builder.Expression, parts map[string]func(string, ...interface{})) error {
+	available := map[string]*string{
+		"condition":     e.Condition(),
+		"filter":        e.Filter(),
+		"key condition": e.KeyCondition(),
+		"projection":    e.Projection(),
+	}
+	if update := e.Update(); update != nil {
+		for keyword, clause := range builderUpdateClauses(*update) {
+			available[keyword] = aws.String(clause)
+		}
+	}
+
+	// bind in a fixed order so placeholders are assigned deterministically
+	order := []string{"key condition", "condition", "filter", "projection", "SET", "REMOVE", "ADD", "DELETE"}
+	for part, expr := range available {
+		if _, ok := parts[part]; expr != nil && !ok {
+			return fmt.Errorf("%v expressions are not supported here", strings.ToLower(part))
+		}
+	}
+
+	for _, part := range order {
+		expr := available[part]
+		if expr == nil {
+			continue
+		}
+
+		s, args, err := bindBuilderExpression(*expr, e.Names(), e.Values())
+		if err != nil {
+			return err
+		}
+		parts[part](s, args...)
+	}
+
+	return nil
+}
+
+// WithExpression merges an expression built with the expression package,
+// github.com/aws/aws-sdk-go/service/dynamodb/expression, into the query.  The key
+// condition and filter of e are and-ed with any added via KeyCondition and Filter.
+func (q *Query) WithExpression(e builder.Expression) *Query {
+	err := withBuilderExpression(e, map[string]func(string, ...interface{}){
+		"key condition": func(s string, args ...interface{}) { q.KeyCondition(s, args...) },
+		"filter":        func(s string, args ...interface{}) { q.Filter(s, args...) },
+	})
+	if err != nil {
+		q.err = err
+	}
+	return q
+}
+
+// QueryExpr returns a query whose key condition, cond, was built with the expression
+// package.  As cond names no index, it may be built once and queried against the table
+// or any index whose keys it references e.g.
+//
+//	cond := expression.Key("Status").Equal(expression.Value("open"))
+//	err := table.QueryExpr(cond).OnIndex("status").FindAll(&orders)
+//
+// Before the query is run, the attributes referenced by cond are verified to be keys
+// of the table or the index selected by OnIndex.
+func (t *Table) QueryExpr(cond builder.KeyConditionBuilder) *Query {
+	query := t.Query("")
+
+	e, err := builder.NewBuilder().WithKeyCondition(cond).Build()
+	if err != nil {
+		query.err = err
+		return query
+	}
+
+	query.keyNames = make([]string, 0, len(e.Names()))
+	for _, name := range e.Names() {
+		query.keyNames = append(query.keyNames, aws.StringValue(name))
+	}
+	sort.Strings(query.keyNames)
+
+	return query.WithExpression(e)
+}
+
+// OnIndex directs the query to the index, indexName, or to the table when indexName is
+// blank.  Unlike IndexName, the index must be defined by the table model.
+func (q *Query) OnIndex(indexName string) *Query {
+	if indexName != "" && q.spec.index(indexName) == nil {
+		q.err = fmt.Errorf("index, %v, not defined for table, %v", indexName, q.spec.TableName)
+		return q
+	}
+	return q.IndexName(indexName)
+}
+
+// checkKeyCondition verifies that names, the attributes referenced by a key condition,
+// include the hash key of the table or index, indexName, and are otherwise its range key
+func (spec *tableSpec) checkKeyCondition(indexName string, names []string) error {
+	target := "table, " + spec.TableName
+	hashKey, rangeKey := spec.HashKey, spec.RangeKey
+	if indexName != "" {
+		index := spec.index(indexName)
+		if index == nil {
+			return fmt.Errorf("index, %v, not defined for table, %v", indexName, spec.TableName)
+		}
+		target = "index, " + indexName
+		rangeKey = index.RangeKey
+		if index.HashKey != nil {
+			hashKey = index.HashKey
+		}
+	}
+
+	var hasHashKey bool
+	for _, name := range names {
+		switch {
+		case hashKey != nil && name == hashKey.AttributeName:
+			hasHashKey = true
+		case rangeKey != nil && name == rangeKey.AttributeName:
+		default:
+			return fmt.Errorf("key condition references %v, which is not a key of %v", name, target)
+		}
+	}
+	if !hasHashKey {
+		return fmt.Errorf("key condition must reference the hash key of %v", target)
+	}
+	return nil
+}
+
+// WithExpression merges the filter of an expression built with the expression package
+// into the scan; see Query.WithExpression
+func (s *Scan) WithExpression(e builder.Expression) *Scan {
+	err := withBuilderExpression(e, map[string]func(string, ...interface{}){
+		"filter": func(expr string, args ...interface{}) { s.Filter(expr, args...) },
+	})
+	if err != nil {
+		s.err = err
+	}
+	return s
+}
+
+// WithExpression merges the condition and update of an expression built with the
+// expression package into the update; see Query.WithExpression
+func (u *Update) WithExpression(e builder.Expression) *Update {
+	err := withBuilderExpression(e, map[string]func(string, ...interface{}){
+		"condition": func(s string, args ...interface{}) { u.Condition(s, args...) },
+		"SET":       func(s string, args ...interface{}) { u.Set(s, args...) },
+		"REMOVE":    func(s string, args ...interface{}) { u.Remove(s, args...) },
+		"ADD":       func(s string, args ...interface{}) { u.Add(s, args...) },
+		"DELETE":    func(s string, args ...interface{}) { u.Delete(s, args...) },
+	})
+	if err != nil {
+		u.err = err
+	}
+	return u
+}
+
+// WithExpression merges the condition of an expression built with the expression
+// package into the delete; see Query.WithExpression
+func (d *Delete) WithExpression(e builder.Expression) *Delete {
+	err := withBuilderExpression(e, map[string]func(string, ...interface{}){
+		"condition": func(s string, args ...interface{}) { d.Condition(s, args...) },
+	})
+	if err != nil {
+		d.err = err
+	}
+	return d
+}
+
+// WithExpression merges the condition of an expression built with the expression
+// package into the put; see Query.WithExpression
+func (p *Put) WithExpression(e builder.Expression) *Put {
+	err := withBuilderExpression(e, map[string]func(string, ...interface{}){
+		"condition": func(s string, args ...interface{}) { p.Condition(s, args...) },
+	})
+	if err != nil {
+		p.err = err
+	}
+	return p
+}
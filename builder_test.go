@@ -0,0 +1,145 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	builder "github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestUpdate_WithExpression(t *testing.T) {
+	e, err := builder.NewBuilder().
+		WithCondition(builder.Name("Name").Equal(builder.Value("old"))).
+		WithUpdate(builder.Set(builder.Name("Name"), builder.Value("new")).
+			Remove(builder.Name("Tmp")).
+			Add(builder.Name("Count"), builder.Value(1))).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	table := New(nil).MustTable("example", Example{})
+	input, err := table.Update("abc").
+		Set("#Other = ?", "first").
+		WithExpression(e).
+		UpdateItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	assertEqual(t, input, "testdata/update_with_expression.json")
+}
+
+func TestQuery_WithExpression(t *testing.T) {
+	e, err := builder.NewBuilder().
+		WithKeyCondition(builder.Key("ID").Equal(builder.Value("abc"))).
+		WithFilter(builder.Name("Name").BeginsWith("a")).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	table := New(nil).MustTable("example", Example{})
+	input, err := table.Query("").
+		Filter("#Name <> ?", "ab").
+		WithExpression(e).
+		QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.KeyConditionExpression), "#n2 = :v2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.FilterExpression), "#n1 <> :v1 and begins_with (#n1, :v3)"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		e, err := builder.NewBuilder().
+			WithUpdate(builder.Set(builder.Name("Name"), builder.Value("new"))).
+			Build()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		if _, err := table.Scan().WithExpression(e).ScanInput(0, 1); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func Test_bindBuilderExpression(t *testing.T) {
+	_, _, err := bindBuilderExpression("#0 = :0", nil, nil)
+	if err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
+
+func TestTable_QueryExpr(t *testing.T) {
+	type Order struct {
+		ID      string `ddb:"hash"`
+		Date    string `ddb:"range"`
+		Status  string `ddb:"gsi_hash:status"`
+		Amount  int    `ddb:"gsi_range:status"`
+		Account string `ddb:"gsi_hash:account"`
+	}
+
+	var (
+		table = New(nil).MustTable("orders", Order{})
+		cond  = builder.Key("Status").Equal(builder.Value("open"))
+	)
+
+	input, err := table.QueryExpr(cond).OnIndex("status").QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.IndexName), "status"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.KeyConditionExpression), "#n1 = :v1"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeNames["#n1"]), "Status"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("table", func(t *testing.T) {
+		cond := builder.Key("ID").Equal(builder.Value("abc")).And(builder.Key("Date").BeginsWith("2020"))
+		input, err := table.QueryExpr(cond).OnIndex("").QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if input.IndexName != nil {
+			t.Fatalf("got %v; want nil", *input.IndexName)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		cond := builder.Key("Status").Equal(builder.Value("open")).And(builder.Key("Amount").GreaterThan(builder.Value(5)))
+		if _, err := table.QueryExpr(cond).OnIndex("status").QueryInput(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		for _, indexName := range []string{"", "account", "missing"} {
+			if _, err := table.QueryExpr(cond).OnIndex(indexName).QueryInput(); err == nil {
+				t.Fatalf("got nil; want err for %q", indexName)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"io"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// Clock supplies the current time along with the timers used to wait between
+// attempts.  Use DDB.WithClock to control retry and backoff timing in tests.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock implements Clock using package time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock sets the clock used for transaction backoff, retries, and the timestamps of
+// generated ids and tokens.  Defaults to the system clock.
+func (d *DDB) WithClock(clock Clock) *DDB {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	dup := d.clone()
+	dup.clock = clock
+	return dup
+}
+
+// WithRand sets the source of randomness used to generate transaction tokens and auto
+// keys.  Defaults to crypto/rand.Reader.
+func (d *DDB) WithRand(random io.Reader) *DDB {
+	if random == nil {
+		random = defaultRandom
+	}
+	dup := d.clone()
+	dup.random = random
+	return dup
+}
+
+// requestToken returns the token for a transaction; a ksuid unless overridden by
+// WithTokenFunc
+func (d *DDB) requestToken() string {
+	if d.tokenFunc != nil {
+		return d.tokenFunc()
+	}
+	if token, err := newKSUID(d.clock.Now(), d.random); err == nil {
+		return token
+	}
+	return makeRequestToken()
+}
+
+// withClock returns a copy of the retry policy that waits using clock
+func (r retryPolicy) withClock(clock Clock) retryPolicy {
+	r.clock = clock
+	return r
+}
+
+// newKSUID returns a ksuid for the time, now, with a payload read from random
+func newKSUID(now time.Time, random io.Reader) (string, error) {
+	payload := make([]byte, 16)
+	if _, err := io.ReadFull(random, payload); err != nil {
+		return "", err
+	}
+	id, err := ksuid.FromParts(now, payload)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// fakeClock returns a fixed time and records each wait without sleeping
+type fakeClock struct {
+	now   time.Time
+	step  time.Duration // step, if set, advances now after each call to Now
+	waits []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	now := f.now
+	f.now = f.now.Add(f.step)
+	return now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.waits = append(f.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- f.now.Add(d)
+	return ch
+}
+
+func TestDDB_WithClock(t *testing.T) {
+	t.Run("transaction backoff", func(t *testing.T) {
+		var (
+			clock = &fakeClock{now: time.Unix(1600000000, 0)}
+			mock  = &conflictMock{Mock: &Mock{}, conflicts: 2}
+			db    = New(mock).WithClock(clock)
+			table = db.MustTable("example", Example{})
+		)
+
+		_, err := db.TransactWriteItemsWithContext(context.Background(), table.Put(Example{ID: "abc"}))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(clock.waits), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := clock.waits[1], getTimeout(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("deterministic ids", func(t *testing.T) {
+		type Auto struct {
+			ID string `ddb:"hash,auto=ulid"`
+		}
+
+		newDB := func() *DDB {
+			return New(nil).
+				WithClock(&fakeClock{now: time.Unix(1600000000, 0)}).
+				WithRand(bytes.NewReader(make([]byte, 64)))
+		}
+
+		var ids []string
+		for i := 0; i < 2; i++ {
+			mock := &Mock{}
+			db := newDB()
+			db.api = mock
+			if err := db.MustTable("example", Auto{}).Put(&Auto{}).Run(); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			ids = append(ids, aws.StringValue(mock.putInput.Item["ID"].S))
+		}
+		if ids[0] != ids[1] {
+			t.Fatalf("got %v; want equal ids", ids)
+		}
+		if got, want := ids[0], "01EJ3PX0000000000000000000"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if got, want := newDB().requestToken(), newDB().requestToken(); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
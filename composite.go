@@ -0,0 +1,210 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compositePart is either literal text or a reference to a field of the model
+type compositePart struct {
+	literal   string
+	fieldName string
+}
+
+// compositeKey describes a string field computed from other fields of the model e.g.
+// ddb:"gsi_hash:status,compose=STATUS#{Status}#{Created}"
+type compositeKey struct {
+	FieldName     string
+	AttributeName string
+	Parts         []compositePart
+}
+
+// parseComposite parses a template of literal text and {Field} references
+func parseComposite(template string) ([]compositePart, error) {
+	var parts []compositePart
+	for s := template; s != ""; {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			parts = append(parts, compositePart{literal: s})
+			break
+		}
+		if open > 0 {
+			parts = append(parts, compositePart{literal: s[:open]})
+		}
+
+		end := strings.IndexByte(s[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated field reference in compose template, %v", template)
+		}
+		fieldName := strings.TrimSpace(s[open+1 : open+end])
+		if fieldName == "" {
+			return nil, fmt.Errorf("empty field reference in compose template, %v", template)
+		}
+		parts = append(parts, compositePart{fieldName: fieldName})
+		s = s[open+end+1:]
+	}
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("compose template may not be blank")
+	}
+	return parts, nil
+}
+
+// components returns the number of field references in the key
+func (c compositeKey) components() int {
+	var n int
+	for _, part := range c.Parts {
+		if part.fieldName != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// compose returns the key formed from values, one per field reference.  When fewer
+// values are provided, the key is truncated before the first missing reference, making
+// it suitable for begins_with.  ok is false if any value provided is a zero value.
+func (c compositeKey) compose(values []reflect.Value) (key string, ok bool) {
+	var (
+		sb    strings.Builder
+		index int
+	)
+	for _, part := range c.Parts {
+		if part.fieldName == "" {
+			sb.WriteString(part.literal)
+			continue
+		}
+		if index == len(values) {
+			break
+		}
+
+		s, ok := composeValue(values[index])
+		if !ok {
+			return "", false
+		}
+		sb.WriteString(s)
+		index++
+	}
+	return sb.String(), true
+}
+
+// composeFrom returns the key formed from the fields of the struct, value
+func (c compositeKey) composeFrom(value reflect.Value) (string, bool) {
+	var values []reflect.Value
+	for _, part := range c.Parts {
+		if part.fieldName != "" {
+			values = append(values, value.FieldByName(part.fieldName))
+		}
+	}
+	return c.compose(values)
+}
+
+// composeValue formats a component of a composite key.  Times are formatted in UTC as
+// RFC 3339 so keys sort chronologically.  Zero values return false.
+func composeValue(v reflect.Value) (string, bool) {
+	if !v.IsValid() || v.IsZero() {
+		return "", false
+	}
+	if v.Kind() == reflect.Ptr {
+		return composeValue(v.Elem())
+	}
+	if tm, ok := v.Interface().(time.Time); ok {
+		return tm.UTC().Format(time.RFC3339), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	default:
+		return fmt.Sprint(v.Interface()), true
+	}
+}
+
+// applyComposites assigns each composite key field from its components.  Fields whose
+// components are incomplete are set to blank so the item is omitted from sparse indexes.
+func applyComposites(spec *tableSpec, v interface{}) interface{} {
+	if len(spec.Composites) == 0 {
+		return v
+	}
+
+	value, v, ok := addressableStruct(v)
+	if !ok {
+		return v
+	}
+
+	for _, c := range spec.Composites {
+		key, _ := c.composeFrom(value)
+		value.FieldByName(c.FieldName).SetString(key)
+	}
+
+	return v
+}
+
+// composite returns the composite key with the given field or attribute name
+func (spec *tableSpec) composite(name string) (compositeKey, bool) {
+	for _, c := range spec.Composites {
+		if c.FieldName == name || c.AttributeName == name {
+			return c, true
+		}
+	}
+	return compositeKey{}, false
+}
+
+// ComposedKey adds a key condition on the composite key attribute, name, formed from
+// values in template order e.g. given
+//
+//	GSI1 string `ddb:"gsi_hash:status,compose=STATUS#{Status}#{Created}"`
+//
+// ComposedKey("GSI1", "open", created) matches #GSI1 = "STATUS#open#<created>".  When
+// fewer values are provided than the template references, the condition matches keys
+// beginning with the partial key e.g. ComposedKey("GSI1", "open") matches keys
+// beginning with "STATUS#open#".
+func (q *Query) ComposedKey(name string, values ...interface{}) *Query {
+	c, ok := q.spec.composite(name)
+	if !ok {
+		q.err = fmt.Errorf("%v is not a composite key of %v", name, q.spec.TableName)
+		return q
+	}
+	if n := c.components(); len(values) == 0 || len(values) > n {
+		q.err = fmt.Errorf("composite key, %v, requires between 1 and %v values: got %v", name, n, len(values))
+		return q
+	}
+
+	var components []reflect.Value
+	for _, v := range values {
+		components = append(components, reflect.ValueOf(v))
+	}
+	key, ok := c.compose(components)
+	if !ok {
+		q.err = fmt.Errorf("composite key, %v, may not contain zero values", name)
+		return q
+	}
+
+	if len(values) < c.components() {
+		return q.KeyCondition("begins_with(#?, ?)", c.AttributeName, key)
+	}
+	return q.KeyCondition("#? = ?", c.AttributeName, key)
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+type CompositeOrder struct {
+	ID      string `ddb:"hash"`
+	Status  string
+	Created time.Time
+	GSI1    string `ddb:"gsi_hash:status,compose=STATUS#{Status}#{Created}"`
+}
+
+func Test_parseComposite(t *testing.T) {
+	parts, err := parseComposite("STATUS#{Status}#{Created}")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := []compositePart{
+		{literal: "STATUS#"},
+		{fieldName: "Status"},
+		{literal: "#"},
+		{fieldName: "Created"},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Fatalf("got %v; want %v", parts, want)
+	}
+
+	for _, template := range []string{"STATUS#{Status", "{}"} {
+		if _, err := parseComposite(template); err == nil {
+			t.Fatalf("got nil; want err for %v", template)
+		}
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		type Invalid struct {
+			ID   string `ddb:"hash"`
+			GSI1 string `ddb:"gsi_hash:a,compose=X#{Missing}"`
+		}
+		if _, err := inspect("example", Invalid{}); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func TestPut_Composite(t *testing.T) {
+	var (
+		created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		mock    = &Mock{}
+		table   = New(mock).MustTable("orders", CompositeOrder{})
+	)
+
+	order := &CompositeOrder{ID: "abc", Status: "open", Created: created}
+	if err := table.Put(order).Run(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(mock.putInput.Item["GSI1"].S), "STATUS#open#2020-01-02T03:04:05Z"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("sparse", func(t *testing.T) {
+		if err := table.Put(CompositeOrder{ID: "abc", Created: created}).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, ok := mock.putInput.Item["GSI1"]; ok {
+			t.Fatalf("got GSI1; want omitted")
+		}
+	})
+}
+
+func TestUpdate_SetAllComposite(t *testing.T) {
+	table := New(nil).MustTable("orders", CompositeOrder{})
+
+	input, err := table.Update("abc").
+		SetAll(CompositeOrder{ID: "abc", Status: "open", Created: time.Unix(0, 0)}).
+		UpdateItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.UpdateExpression), "Set #n1 = :v1, #n2 = :v2, #n3 = :v3"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeValues[":v3"].S), "STATUS#open#1970-01-01T00:00:00Z"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("incomplete", func(t *testing.T) {
+		input, err := table.Update("abc").SetAll(CompositeOrder{Status: "open"}).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := aws.StringValue(input.UpdateExpression); strings.Contains(got, "Remove") || len(input.ExpressionAttributeNames) != 1 {
+			t.Fatalf("got %v %v; want only Status set", got, input.ExpressionAttributeNames)
+		}
+	})
+
+	t.Run("incomplete with zero values", func(t *testing.T) {
+		input, err := table.Update("abc").SetAll(CompositeOrder{Status: "open"}, WithZeroValues()).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := aws.StringValue(input.UpdateExpression); !strings.HasSuffix(got, "Remove #n3") {
+			t.Fatalf("got %v; want GSI1 removed", got)
+		}
+	})
+}
+
+func TestQuery_ComposedKey(t *testing.T) {
+	var (
+		created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		table   = New(nil).MustTable("orders", CompositeOrder{})
+	)
+
+	testCases := map[string]struct {
+		Values []interface{}
+		Expr   string
+		Value  string
+	}{
+		"complete": {
+			Values: []interface{}{"open", created},
+			Expr:   "#n1 = :v1",
+			Value:  "STATUS#open#2020-01-02T03:04:05Z",
+		},
+		"prefix": {
+			Values: []interface{}{"open"},
+			Expr:   "begins_with(#n1, :v1)",
+			Value:  "STATUS#open#",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			input, err := table.Query("").IndexName("status").ComposedKey("GSI1", tc.Values...).QueryInput()
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := aws.StringValue(input.KeyConditionExpression), tc.Expr; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := aws.StringValue(input.ExpressionAttributeValues[":v1"].S), tc.Value; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, values := range [][]interface{}{nil, {"open", created, "extra"}, {""}} {
+			if _, err := table.Query("").ComposedKey("GSI1", values...).QueryInput(); err == nil {
+				t.Fatalf("got nil; want err for %v", values)
+			}
+		}
+		if _, err := table.Query("").ComposedKey("Status", "open").QueryInput(); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
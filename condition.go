@@ -0,0 +1,69 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"sync"
+)
+
+// namedCondition holds a condition expression along with its values
+type namedCondition struct {
+	expr   string
+	values []interface{}
+}
+
+// conditions holds the named conditions defined on a table.  conditions are shared by
+// copies of the table so conditions defined on one are available to all.
+type conditions struct {
+	mutex  sync.RWMutex
+	byName map[string]namedCondition
+}
+
+func (c *conditions) define(name, expr string, values []interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.byName == nil {
+		c.byName = map[string]namedCondition{}
+	}
+	c.byName[name] = namedCondition{
+		expr:   expr,
+		values: values,
+	}
+}
+
+func (c *conditions) lookup(name string) (namedCondition, error) {
+	if c != nil {
+		c.mutex.RLock()
+		defer c.mutex.RUnlock()
+
+		if condition, ok := c.byName[name]; ok {
+			return condition, nil
+		}
+	}
+	return namedCondition{}, errorf(ErrUndefinedCondition, "condition, %v, is not defined", name)
+}
+
+// DefineCondition defines a condition that Put, Update, and Delete may reference by
+// name via ConditionNamed.  Conditions use the same syntax as Condition and allow
+// invariants shared by many writes to be expressed once e.g.
+//
+//	table.DefineCondition("notArchived", "attribute_not_exists(#ArchivedAt)")
+//
+// Defining a condition with an existing name replaces it.
+func (t *Table) DefineCondition(name, expr string, values ...interface{}) *Table {
+	t.conditions.define(name, expr, values)
+	return t
+}
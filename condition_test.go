@@ -0,0 +1,78 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestTable_DefineCondition(t *testing.T) {
+	table := New(&Mock{}).MustTable("example", DeleteTable{}).
+		DefineCondition("positive", "#Field > ?", 0).
+		DefineCondition("exists", "attribute_exists(#ID)")
+
+	t.Run("put", func(t *testing.T) {
+		input, err := table.Put(DeleteTable{ID: "abc", Date: "2006-01-02"}).ConditionNamed("positive").PutItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ConditionExpression), "#n1 > :v1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		input, err := table.Update("abc").Range("2006-01-02").
+			Set("#Field = ?", 1).
+			ConditionNamed("exists").
+			ConditionNamed("positive").
+			UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := aws.StringValue(input.ConditionExpression); got == "" {
+			t.Fatalf("got blank; want condition")
+		}
+		if got, want := len(input.ExpressionAttributeValues), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		input, err := table.Delete("abc").Range("2006-01-02").ConditionNamed("exists").DeleteItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ConditionExpression), "attribute_exists(#n1)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("shared by copies", func(t *testing.T) {
+		err := table.WithSingleflight().Delete("abc").Range("2006-01-02").ConditionNamed("exists").Run()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		err := table.Put(DeleteTable{ID: "abc", Date: "2006-01-02"}).ConditionNamed("missing").Run()
+		if !IsUndefinedConditionError(err) {
+			t.Fatalf("got %v; want UndefinedCondition", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// contention counts writes that failed their condition expression.  The rate of
+// failures indicates the contention between writers using optimistic locking.
+type contention struct {
+	parent   *contention // parent, if set, is also credited with failures
+	failures int64
+}
+
+func (c *contention) add() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.failures, 1)
+	if c.parent != nil {
+		c.parent.add()
+	}
+}
+
+func (c *contention) load() int64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&c.failures)
+}
+
+// reset zeroes the failures and returns the count prior to the reset
+func (c *contention) reset() int64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.SwapInt64(&c.failures, 0)
+}
+
+// record credits a failure if err is a ConditionalCheckFailedException
+func (c *contention) record(err error) {
+	if isErrorClass(err, classConditionFailed) {
+		c.add()
+	}
+}
+
+// contentionSink is implemented by writes that attribute conditional check failures to
+// the Table that created them
+type contentionSink interface {
+	tableContention() *contention
+}
+
+// attributeContention credits each transaction item canceled by its condition to the
+// table of that item
+func attributeContention(err error, items []interface{}) {
+	var tce *dynamodb.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return
+	}
+	for i, reason := range tce.CancellationReasons {
+		if i >= len(items) || aws.StringValue(reason.Code) != "ConditionalCheckFailed" {
+			continue
+		}
+		if sink, ok := items[i].(contentionSink); ok {
+			sink.tableContention().add()
+		}
+	}
+}
+
+// ConditionalCheckFailures returns the number of writes to the table, including those
+// within transactions, that failed their condition expression
+func (t *Table) ConditionalCheckFailures() int64 {
+	return t.conflicts.load()
+}
+
+// ConditionalCheckFailures returns the number of writes across all tables created by
+// this client that failed their condition expression
+func (d *DDB) ConditionalCheckFailures() int64 {
+	return d.conflicts.load()
+}
+
+// ResetConditionalCheckFailures zeroes the failures counted across all tables and
+// returns the count prior to the reset.  Per table counters are unaffected.
+func (d *DDB) ResetConditionalCheckFailures() int64 {
+	return d.conflicts.reset()
+}
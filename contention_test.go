@@ -0,0 +1,81 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestTable_ConditionalCheckFailures(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes", func(t *testing.T) {
+		var (
+			mock  = &Mock{err: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "boom", nil)}
+			db    = New(mock)
+			table = db.MustTable("example", Example{})
+			other = db.MustTable("other", Example{})
+		)
+
+		_ = table.Put(Example{ID: "abc"}).AttributeNotExists("id").RunWithContext(ctx)
+		_ = table.Update("abc").Set("#Name = ?", "name").Condition("attribute_exists(#ID)").RunWithContext(ctx)
+		_ = other.Delete("abc").AttributeExists("id").RunWithContext(ctx)
+
+		mock.err = awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "boom", nil)
+		_ = table.Put(Example{ID: "abc"}).RunWithContext(ctx)
+
+		if got, want := table.ConditionalCheckFailures(), int64(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := other.ConditionalCheckFailures(), int64(1); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := db.ResetConditionalCheckFailures(), int64(3); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := db.ConditionalCheckFailures(), int64(0); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := table.ConditionalCheckFailures(), int64(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		var (
+			db     = New(&cancelMock{Mock: &Mock{}, index: 1})
+			first  = db.MustTable("first", Example{})
+			second = db.MustTable("second", QueryExample{})
+		)
+
+		_, err := db.TransactWriteItemsWithContext(ctx, first.Put(Example{ID: "abc"}), second.Delete("def").Range("2020"))
+		if err == nil {
+			t.Fatalf("got nil; want err")
+		}
+		if got, want := first.ConditionalCheckFailures(), int64(0); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := second.ConditionalCheckFailures(), int64(1); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := db.ConditionalCheckFailures(), int64(1); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
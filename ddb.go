@@ -16,14 +16,16 @@ package ddb
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/segmentio/ksuid"
@@ -36,11 +38,12 @@ const (
 
 var (
 	defaultContext = context.Background()
-	r              = rand.New(rand.NewSource(time.Now().UnixNano()))
+	defaultRandom  = rand.Reader
 )
 
 type ConsumedCapacity struct {
 	mux           sync.Mutex
+	parent        *ConsumedCapacity // parent, if set, is also credited with consumed capacity
 	capacityUnits float64
 	ReadUnits     int64
 	WriteUnits    int64
@@ -68,6 +71,10 @@ func (c *ConsumedCapacity) add(in *dynamodb.ConsumedCapacity) {
 		c.capacityUnits += *in.CapacityUnits
 		c.mux.Unlock()
 	}
+
+	if c.parent != nil {
+		c.parent.add(in)
+	}
 }
 
 func (c *ConsumedCapacity) safeClone() ConsumedCapacity {
@@ -81,11 +88,74 @@ func (c *ConsumedCapacity) safeClone() ConsumedCapacity {
 	}
 }
 
+// reset zeroes the consumed capacity and returns the values prior to the reset
+func (c *ConsumedCapacity) reset() ConsumedCapacity {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	capacityUnits := c.capacityUnits
+	c.capacityUnits = 0
+
+	return ConsumedCapacity{
+		ReadUnits:     atomic.SwapInt64(&c.ReadUnits, 0),
+		WriteUnits:    atomic.SwapInt64(&c.WriteUnits, 0),
+		capacityUnits: capacityUnits,
+	}
+}
+
 type Table struct {
-	ddb       *DDB
-	spec      *tableSpec
-	tableName string
-	consumed  *ConsumedCapacity
+	ddb        *DDB
+	spec       *tableSpec
+	tableName  string
+	consumed   *ConsumedCapacity
+	conflicts  *contention                      // conflicts counts writes that failed their condition
+	conditions *conditions                      // conditions holds the named conditions defined on the table
+	flight     *flightGroup                     // flight, if set, coalesces concurrent Gets for the same item
+	queryCache *queryCache                      // queryCache, if set, holds query pages for a short ttl
+	view       []string                         // view, if set, holds the attributes fetched by Gets and Queries
+	tenant     func(ctx context.Context) string // tenant, if set, identifies the tenant requests are scoped to
+}
+
+// clone returns a shallow copy of the table; the copy shares the capacity, contention,
+// conditions, and any flight group or query cache of the original
+func (t *Table) clone() *Table {
+	dup := *t
+	return &dup
+}
+
+// newExpression returns an expression bound to the table attributes and encoder
+func (t *Table) newExpression() *expression {
+	expr := newExpression(t.spec.Attributes...)
+	expr.encoder = t.ddb.encoder
+	expr.names = t.ddb.names
+	return expr
+}
+
+// WithSingleflight returns a copy of the table whose Gets are coalesced; concurrent Gets
+// for the same item result in a single call to DynamoDB with the result shared by all
+// callers.  As the call is made with the context of the first caller, canceling that
+// context fails the Gets waiting on it.
+func (t *Table) WithSingleflight() *Table {
+	dup := t.clone()
+	dup.flight = newFlightGroup()
+	return dup
+}
+
+// WithQueryCache returns a copy of the table whose Query pages are cached for ttl.  The
+// cache is keyed by the complete query input, including index, expressions, values, and
+// start key.  Cached pages do not reflect writes made within the ttl; use
+// InvalidateQueryCache or Query.InvalidateCache to discard them explicitly.
+func (t *Table) WithQueryCache(ttl time.Duration) *Table {
+	dup := t.clone()
+	dup.queryCache = newQueryCache(ttl, t.ddb.clock.Now)
+	return dup
+}
+
+// InvalidateQueryCache discards all pages held by the query cache
+func (t *Table) InvalidateQueryCache() {
+	if t.queryCache != nil {
+		t.queryCache.clear()
+	}
 }
 
 func (t *Table) ConsumedCapacity() ConsumedCapacity {
@@ -101,6 +171,26 @@ type DDB struct {
 	tokenFunc  func() string
 	txAttempts int                     // txAttempts refers to max number of times an Transact* will be attempted
 	txTimeout  func(int) time.Duration // txTimeout provides the getTimeout given a duration
+	encoder    encoder                 // encoder holds options for encoding items and values
+	validator  Validator               // validator, if set, validates values prior to writes
+	capacity   string                  // capacity holds the ReturnConsumedCapacity used by requests
+	consumed   *ConsumedCapacity       // consumed aggregates the capacity consumed by all tables
+	conflicts  *contention             // conflicts aggregates the conditional check failures of all tables
+	pageRetry  retryPolicy             // pageRetry determines how throttled Query and Scan pages are retried
+	netRetry   retryPolicy             // netRetry determines how idempotent requests failing with network errors are retried
+	noContext  contextFactory          // noContext supplies the context for methods called without one
+	logger     Logger                  // logger, if set, receives warnings about requests that succeed at additional cost
+	names      nameResolution          // names determines how #names within expressions are matched to attributes
+	clock      Clock                   // clock supplies the time for backoff, retries, and generated ids
+	random     io.Reader               // random supplies the randomness for generated ids and tokens
+}
+
+// clone returns a copy of the DDB for the With* options to customize.  Options must
+// modify the copy rather than construct a new DDB so that no customization is lost
+// regardless of the order in which options are applied.
+func (d *DDB) clone() *DDB {
+	dup := *d
+	return &dup
 }
 
 func (d *DDB) Table(tableName string, model interface{}) (*Table, error) {
@@ -110,10 +200,12 @@ func (d *DDB) Table(tableName string, model interface{}) (*Table, error) {
 	}
 
 	return &Table{
-		ddb:       d,
-		spec:      spec,
-		tableName: tableName,
-		consumed:  &ConsumedCapacity{},
+		ddb:        d,
+		spec:       spec,
+		tableName:  tableName,
+		consumed:   &ConsumedCapacity{parent: d.consumed},
+		conflicts:  &contention{parent: d.conflicts},
+		conditions: &conditions{},
 	}, nil
 }
 
@@ -125,11 +217,47 @@ func (d *DDB) MustTable(tableName string, model interface{}) *Table {
 	return table
 }
 
-// WithTokenFunc allows the generator func for dynamodb transactions to be overwritten
-func (d *DDB) WithTokenFunc(fn func() string) *DDB {
-	if fn == nil {
-		fn = makeRequestToken
+// ConsumedCapacity returns the capacity consumed across all tables created by this client
+func (d *DDB) ConsumedCapacity() ConsumedCapacity {
+	return d.consumed.safeClone()
+}
+
+// ResetConsumedCapacity zeroes the capacity consumed across all tables and returns the
+// capacity consumed prior to the reset.  Per table counters are unaffected.
+func (d *DDB) ResetConsumedCapacity() ConsumedCapacity {
+	return d.consumed.reset()
+}
+
+// FlushConsumedCapacity invokes fn every interval with the capacity consumed across all
+// tables since the previous flush.  FlushConsumedCapacity blocks until the context is
+// canceled, at which point any remaining capacity is flushed.  Unlike request timing,
+// the interval is always measured by the system clock; a test clock, such as that of
+// ddbtest.Deterministic, fires immediately and would flush in a busy loop.
+//
+//	go db.FlushConsumedCapacity(ctx, time.Minute, func(c *ddb.ConsumedCapacity) {
+//		log.Printf("read=%v write=%v", c.ReadUnits, c.WriteUnits)
+//	})
+func (d *DDB) FlushConsumedCapacity(ctx context.Context, interval time.Duration, fn func(c *ConsumedCapacity)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c := d.consumed.reset()
+			fn(&c)
+			return
+		case <-ticker.C:
+			c := d.consumed.reset()
+			fn(&c)
+		}
 	}
+}
+
+// WithTokenFunc allows the generator func for dynamodb transactions to be overwritten;
+// nil restores the default ksuid generated from the clock and rand of the DDB.  Unlike
+// the other options, the DDB is modified in place and returned.
+func (d *DDB) WithTokenFunc(fn func() string) *DDB {
 	d.tokenFunc = fn
 	return d
 }
@@ -140,12 +268,9 @@ func (d *DDB) WithTransactAttempts(n int) *DDB {
 	if n < 0 || n >= 10 {
 		panic(fmt.Errorf("WithTransactAttempts requires 0 < n < 10: got %v", n))
 	}
-	return &DDB{
-		api:        d.api,
-		tokenFunc:  d.tokenFunc,
-		txAttempts: n,
-		txTimeout:  d.txTimeout,
-	}
+	dup := d.clone()
+	dup.txAttempts = n
+	return dup
 }
 
 // WithTransactTimeout allows the timeout progression to be customized.  By default
@@ -154,12 +279,166 @@ func (d *DDB) WithTransactTimeout(fn func(i int) time.Duration) *DDB {
 	if fn == nil {
 		fn = getTimeout
 	}
-	return &DDB{
-		api:        d.api,
-		tokenFunc:  d.tokenFunc,
-		txAttempts: d.txAttempts,
-		txTimeout:  fn,
+	dup := d.clone()
+	dup.txTimeout = fn
+	return dup
+}
+
+// WithEmptyValues determines how empty strings, binary values, and collections are
+// encoded by Put and by values bound to expressions.  Defaults to EmptyAsNull
+func (d *DDB) WithEmptyValues(mode EmptyValues) *DDB {
+	dup := d.clone()
+	dup.encoder.emptyValues = mode
+	return dup
+}
+
+// WithCaseInsensitiveNames matches #names within expressions to the field or attribute
+// names of the model regardless of case e.g. #name resolves to the attribute, Name.
+// Exact matches are preferred.
+func (d *DDB) WithCaseInsensitiveNames() *DDB {
+	dup := d.clone()
+	dup.names.caseInsensitive = true
+	return dup
+}
+
+// WithStrictNames fails requests whose expressions contain #names that match no
+// attribute of the model with ErrUnknownAttribute.  By default, unknown names are passed
+// through to DynamoDB as is.  Names bound via #? and names nested within document
+// paths e.g. #Attrs.#color are not checked.
+func (d *DDB) WithStrictNames() *DDB {
+	dup := d.clone()
+	dup.names.strict = true
+	return dup
+}
+
+// WithValidator validates models passed to Put, and struct values bound to Update
+// expressions, before they are marshalled.  Failures are returned as *ValidationError
+func (d *DDB) WithValidator(validator Validator) *DDB {
+	dup := d.clone()
+	dup.validator = validator
+	return dup
+}
+
+// WithReturnConsumedCapacity sets the ReturnConsumedCapacity of requests to one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes.  Defaults to Total.  Use
+// None to reduce response overhead when capacity is not being tracked.
+func (d *DDB) WithReturnConsumedCapacity(v string) *DDB {
+	dup := d.clone()
+	dup.capacity = v
+	return dup
+}
+
+// WithSlowLogThreshold logs DynamoDB calls that take longer than threshold along with
+// the operation, table, expressions, item count, and consumed capacity.  Calls are timed
+// by the clock of the DDB, so apply WithClock first.
+func (d *DDB) WithSlowLogThreshold(threshold time.Duration, logger Logger) *DDB {
+	dup := d.clone()
+	dup.api = newSlowLogAPI(d.api, threshold, logger, d.clock)
+	return dup
+}
+
+// WithLogger logs warnings about requests that succeed but cost more than intended,
+// such as queries of local secondary indexes that fetch unprojected attributes from
+// the base table
+func (d *DDB) WithLogger(logger Logger) *DDB {
+	dup := d.clone()
+	dup.logger = logger
+	return dup
+}
+
+// WithBaseContext sets the function used to supply the context for methods called
+// without one e.g. Run, Each, or First; defaults to context.Background.  Use it to
+// attach deadlines or tracing to callers that have yet to migrate to the WithContext
+// variants.
+func (d *DDB) WithBaseContext(fn func() context.Context) *DDB {
+	dup := d.clone()
+	dup.noContext.base = fn
+	return dup
+}
+
+// WithNoContextHook calls fn with the name of the method, e.g. "Query.Each", each time
+// a method is called without a context, to help locate callers that should be migrated
+// to the WithContext variants
+func (d *DDB) WithNoContextHook(fn func(method string)) *DDB {
+	dup := d.clone()
+	dup.noContext.hook = fn
+	return dup
+}
+
+// WithDefaultTimeout bounds calls of the DynamoDB operation, op, e.g. "GetItem" or
+// "Query", to timeout when the caller's context has no deadline.  Contexts with a deadline
+// are passed through unchanged.  Each page of a Query or Scan is bounded separately.
+func (d *DDB) WithDefaultTimeout(op string, timeout time.Duration) *DDB {
+	if _, ok := timeoutOperations[op]; !ok {
+		panic(fmt.Errorf("WithDefaultTimeout: unsupported operation, %v", op))
+	}
+	if timeout <= 0 {
+		panic(fmt.Errorf("WithDefaultTimeout requires timeout > 0: got %v", timeout))
+	}
+	dup := d.clone()
+	dup.api = &timeoutAPI{DynamoDBAPI: d.api, operation: op, timeout: timeout}
+	return dup
+}
+
+// WithBreaker consults the Breaker before each call to DynamoDB.  Rejected calls return
+// an error with the code, ErrCircuitOpen
+func (d *DDB) WithBreaker(breaker Breaker) *DDB {
+	dup := d.clone()
+	dup.api = &breakerAPI{DynamoDBAPI: d.api, breaker: breaker}
+	return dup
+}
+
+// WithPageRetry overrides how pages read by Query and Scan are retried when DynamoDB
+// throttles the request.  Each page is attempted up to n times with backoff(attempt)
+// between attempts; use 1 to disable retries.  Defaults to 4 attempts with exponential
+// backoff.  Once attempts are exhausted, Each returns a *ResumeError from which the
+// iteration may be resumed.
+func (d *DDB) WithPageRetry(n int, backoff func(attempt int) time.Duration) *DDB {
+	if n < 1 {
+		panic(fmt.Errorf("WithPageRetry requires n >= 1: got %v", n))
+	}
+	if backoff == nil {
+		backoff = getTimeout
+	}
+	dup := d.clone()
+	dup.pageRetry = retryPolicy{attempts: n, backoff: backoff}
+	return dup
+}
+
+// WithNetworkRetry retries idempotent requests that fail with a transient network
+// error, such as a connection reset or timeout, up to n attempts with backoff(attempt)
+// between attempts.  Idempotent requests are GetItem, Query and Scan pages, and
+// transactions, which are retried with the same ClientRequestToken.  Defaults to a
+// single attempt, leaving retries to the aws sdk.
+func (d *DDB) WithNetworkRetry(n int, backoff func(attempt int) time.Duration) *DDB {
+	if n < 1 {
+		panic(fmt.Errorf("WithNetworkRetry requires n >= 1: got %v", n))
 	}
+	if backoff == nil {
+		backoff = getTimeout
+	}
+	dup := d.clone()
+	dup.netRetry = retryPolicy{attempts: n, backoff: backoff, retryable: isNetworkError}
+	return dup
+}
+
+// WithRequestOptions applies opts to every DynamoDB call made by ddb, after the
+// options ddb itself supplies.  Use it to configure the aws sdk per DDB rather than
+// per session e.g. DisableSDKRetries or UseHTTPClient.  When called multiple times,
+// the options are applied in order.
+func (d *DDB) WithRequestOptions(opts ...request.Option) *DDB {
+	dup := d.clone()
+	dup.api = newRequestOptionsAPI(d.api, opts)
+	return dup
+}
+
+// returnConsumedCapacity returns the ReturnConsumedCapacity for a request, defaulting
+// to dynamodb.ReturnConsumedCapacityTotal when v is blank
+func returnConsumedCapacity(v string) *string {
+	if v == "" {
+		v = dynamodb.ReturnConsumedCapacityTotal
+	}
+	return aws.String(v)
 }
 
 // GetTx encapsulates a transactional get operation
@@ -170,24 +449,37 @@ type GetTx interface {
 	Tx() (*dynamodb.TransactGetItem, error)
 }
 
+// contextDecoder is implemented by GetTx values whose Decode should observe the context
+// of the transaction, e.g. to pass it to AfterGet hooks
+type contextDecoder interface {
+	decodeWithContext(ctx context.Context, v *dynamodb.ItemResponse) error
+}
+
 // TransactGetItemsWithContext wraps the get operations using a TransactGetItems
 func (d *DDB) TransactGetItemsWithContext(ctx context.Context, gets ...GetTx) (err error) {
 	input := dynamodb.TransactGetItemsInput{
-		TransactItems: make([]*dynamodb.TransactGetItem, 0, len(gets)),
+		ReturnConsumedCapacity: returnConsumedCapacity(d.capacity),
+		TransactItems:          make([]*dynamodb.TransactGetItem, 0, len(gets)),
 	}
+	sinks := make([]interface{}, 0, len(gets))
 	for _, get := range gets {
 		v, err := get.Tx()
 		if err != nil {
 			return err
 		}
 		input.TransactItems = append(input.TransactItems, v)
+		sinks = append(sinks, get)
 	}
 
 	var e error
 
 loop:
 	for attempt := 1; attempt <= d.txAttempts; attempt++ {
-		output, err := d.api.TransactGetItemsWithContext(ctx, &input)
+		var output *dynamodb.TransactGetItemsOutput
+		err := d.netRetry.withClock(d.clock).do(ctx, func() (err error) {
+			output, err = d.api.TransactGetItemsWithContext(ctx, &input)
+			return err
+		})
 		if err != nil {
 			var tce *dynamodb.TransactionCanceledException
 			if ok := errors.As(err, &tce); ok {
@@ -197,32 +489,89 @@ loop:
 						select {
 						case <-ctx.Done():
 							return ctx.Err()
-						case <-time.After(timeout):
+						case <-d.clock.After(timeout):
 							e = err
 							continue loop
 						}
 					}
 				}
 			}
-			return err
+			return wrapTransactError(err, "TransactGetItems", sinks, func(i int) map[string]*dynamodb.AttributeValue {
+				return input.TransactItems[i].Get.Key
+			})
 		}
 
+		attributeCapacity(capacitySinks(sinks...), output.ConsumedCapacity)
+
+		var missing []MissingItem
 		for i, item := range output.Responses {
 			get := gets[i]
-			if err := get.Decode(item); err != nil {
-				return err
+			decode := get.Decode
+			if cd, ok := get.(contextDecoder); ok {
+				decode = func(v *dynamodb.ItemResponse) error { return cd.decodeWithContext(ctx, v) }
+			}
+			if err := decode(item); err != nil {
+				var e Error
+				if !IsItemNotFoundError(err) || !errors.As(err, &e) {
+					return err
+				}
+
+				hashKey, rangeKey := e.Keys()
+				missing = append(missing, MissingItem{
+					Index:     i,
+					TableName: e.TableName(),
+					HashKey:   hashKey,
+					RangeKey:  rangeKey,
+				})
 			}
 		}
+		if len(missing) > 0 {
+			return newTransactGetError(len(gets), missing)
+		}
 
 		return nil
 	}
 
-	return e
+	return wrapTransactError(e, "TransactGetItems", sinks, func(i int) map[string]*dynamodb.AttributeValue {
+		return input.TransactItems[i].Get.Key
+	})
 }
 
 // TransactGetItems allows TransactGetItems to be called without a context
 func (d *DDB) TransactGetItems(items ...GetTx) error {
-	return d.TransactGetItemsWithContext(defaultContext, items...)
+	return d.TransactGetItemsWithContext(d.noContext.context("DDB.TransactGetItems"), items...)
+}
+
+// capacitySink is implemented by operations that attribute consumed capacity to the
+// Table that created them
+type capacitySink interface {
+	tableCapacity() (tableName string, consumed *ConsumedCapacity)
+}
+
+// capacitySinks returns the consumed capacity of each table referenced by items
+func capacitySinks(items ...interface{}) map[string]*ConsumedCapacity {
+	sinks := map[string]*ConsumedCapacity{}
+	for _, item := range items {
+		if sink, ok := item.(capacitySink); ok {
+			tableName, consumed := sink.tableCapacity()
+			if _, ok := sinks[tableName]; !ok && consumed != nil {
+				sinks[tableName] = consumed
+			}
+		}
+	}
+	return sinks
+}
+
+// attributeCapacity credits the capacity consumed by a transaction to each table
+func attributeCapacity(sinks map[string]*ConsumedCapacity, capacity []*dynamodb.ConsumedCapacity) {
+	for _, c := range capacity {
+		if c == nil {
+			continue
+		}
+		if sink, ok := sinks[aws.StringValue(c.TableName)]; ok {
+			sink.add(c)
+		}
+	}
 }
 
 // WriteTx converts ddb operations into instances of *dynamodb.TransactWriteItem
@@ -230,64 +579,188 @@ type WriteTx interface {
 	Tx() (*dynamodb.TransactWriteItem, error)
 }
 
+// TransactOptions customizes a single call to TransactWriteItemsWithOptions.  Zero
+// values fall back to the options of the DDB.
+type TransactOptions struct {
+	Attempts               int                             // Attempts, if positive, overrides WithTransactAttempts
+	Timeout                func(attempt int) time.Duration // Timeout, if set, overrides WithTransactTimeout
+	Token                  string                          // Token, if set, is used as the ClientRequestToken in place of WithTokenFunc
+	ReturnConsumedCapacity string                          // ReturnConsumedCapacity, if set, overrides WithReturnConsumedCapacity
+}
+
 // TransactWriteItemsWithContext applies the provided operations in a dynamodb transaction.
 // Subject to the limits of of TransactWriteItems.
 func (d *DDB) TransactWriteItemsWithContext(ctx context.Context, items ...WriteTx) (*dynamodb.TransactWriteItemsOutput, error) {
-	token := d.tokenFunc()
+	return d.TransactWriteItemsWithOptions(ctx, TransactOptions{}, items...)
+}
+
+// TransactWriteItemsWithOptions is identical to TransactWriteItemsWithContext except
+// that opts override the transaction options of the DDB for this call only.  Set
+// Token to retry a transaction idempotently across process restarts.
+func (d *DDB) TransactWriteItemsWithOptions(ctx context.Context, opts TransactOptions, items ...WriteTx) (*dynamodb.TransactWriteItemsOutput, error) {
+	if opts.Attempts < 0 || opts.Attempts >= 10 {
+		return nil, fmt.Errorf("TransactOptions requires 0 <= Attempts < 10: got %v", opts.Attempts)
+	}
+	if opts.Attempts == 0 {
+		opts.Attempts = d.txAttempts
+	}
+	if opts.Timeout == nil {
+		opts.Timeout = d.txTimeout
+	}
+	if opts.Token == "" {
+		opts.Token = d.requestToken()
+	}
+	if opts.ReturnConsumedCapacity == "" {
+		opts.ReturnConsumedCapacity = d.capacity
+	}
+
 	input := dynamodb.TransactWriteItemsInput{
-		ClientRequestToken: aws.String(token),
-		TransactItems:      make([]*dynamodb.TransactWriteItem, 0, len(items)),
+		ClientRequestToken:     aws.String(opts.Token),
+		ReturnConsumedCapacity: returnConsumedCapacity(opts.ReturnConsumedCapacity),
+		TransactItems:          make([]*dynamodb.TransactWriteItem, 0, len(items)),
 	}
 
+	sinks := make([]interface{}, 0, len(items))
 	for _, item := range items {
 		v, err := item.Tx()
 		if err != nil {
 			return nil, err
 		}
 		input.TransactItems = append(input.TransactItems, v)
+		sinks = append(sinks, item)
 	}
 
 	var e error
 
 loop:
-	for attempt := 1; attempt <= d.txAttempts; attempt++ {
-		output, err := d.api.TransactWriteItemsWithContext(ctx, &input)
+	for attempt := 1; attempt <= opts.Attempts; attempt++ {
+		var output *dynamodb.TransactWriteItemsOutput
+		err := d.netRetry.withClock(d.clock).do(ctx, func() (err error) {
+			output, err = d.api.TransactWriteItemsWithContext(ctx, &input)
+			return err
+		})
 		if err != nil {
 			var tce *dynamodb.TransactionCanceledException
 			if ok := errors.As(err, &tce); ok {
 				for _, reason := range tce.CancellationReasons {
 					if code := aws.StringValue(reason.Code); code == "TransactionConflictException" || code == "TransactionConflict" {
-						timeout := d.txTimeout(attempt)
+						timeout := opts.Timeout(attempt)
 						select {
 						case <-ctx.Done():
 							return nil, ctx.Err()
-						case <-time.After(timeout):
+						case <-d.clock.After(timeout):
 							e = err
 							continue loop
 						}
 					}
 				}
 			}
-			return nil, err
+			attributeContention(err, sinks)
+			return nil, wrapTransactError(err, "TransactWriteItems", sinks, func(i int) map[string]*dynamodb.AttributeValue {
+				return transactWriteKey(input.TransactItems[i])
+			})
 		}
 
+		attributeCapacity(capacitySinks(sinks...), output.ConsumedCapacity)
+
 		return output, nil
 	}
 
-	return nil, e
+	return nil, wrapTransactError(e, "TransactWriteItems", sinks, func(i int) map[string]*dynamodb.AttributeValue {
+		return transactWriteKey(input.TransactItems[i])
+	})
+}
+
+// specSource is implemented by operations that know the spec of their table
+type specSource interface {
+	tableSpec() *tableSpec
+}
+
+// transactWriteKey returns the key, or for a put, the item, of a transaction item
+func transactWriteKey(item *dynamodb.TransactWriteItem) map[string]*dynamodb.AttributeValue {
+	switch {
+	case item.ConditionCheck != nil:
+		return item.ConditionCheck.Key
+	case item.Delete != nil:
+		return item.Delete.Key
+	case item.Put != nil:
+		return item.Put.Item
+	case item.Update != nil:
+		return item.Update.Key
+	default:
+		return nil
+	}
+}
+
+// wrapTransactError wraps err in an *OperationError identifying the first item that
+// caused the transaction to be canceled, if any.  key returns the key of the ith item.
+func wrapTransactError(err error, operation string, items []interface{}, key func(i int) map[string]*dynamodb.AttributeValue) error {
+	index := -1
+	var tce *dynamodb.TransactionCanceledException
+	if errors.As(err, &tce) {
+		for i, reason := range tce.CancellationReasons {
+			if code := aws.StringValue(reason.Code); code != "" && code != "None" {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 || index >= len(items) {
+		return wrapAWSError(err, operation, nil, "", nil)
+	}
+
+	var spec *tableSpec
+	if v, ok := items[index].(specSource); ok {
+		spec = v.tableSpec()
+	}
+	wrapped := wrapAWSError(err, operation, spec, "", key(index))
+	if oe, ok := wrapped.(*OperationError); ok {
+		oe.Item = index
+	}
+	return wrapped
 }
 
 func (d *DDB) TransactWriteItems(items ...WriteTx) (*dynamodb.TransactWriteItemsOutput, error) {
-	return d.TransactWriteItemsWithContext(defaultContext, items...)
+	return d.TransactWriteItemsWithContext(d.noContext.context("DDB.TransactWriteItems"), items...)
 }
 
 func New(api dynamodbiface.DynamoDBAPI) *DDB {
 	return &DDB{
 		api:        api,
-		tokenFunc:  makeRequestToken,
 		txAttempts: defaultMaxAttempts,
 		txTimeout:  getTimeout,
+		consumed:   &ConsumedCapacity{},
+		conflicts:  &contention{},
+		pageRetry:  retryPolicy{attempts: defaultMaxAttempts, backoff: getTimeout},
+		clock:      systemClock{},
+		random:     defaultRandom,
+	}
+}
+
+// contextFactory supplies the context used by methods called without one
+type contextFactory struct {
+	base func() context.Context // base, if set, returns the context; defaults to context.Background
+	hook func(method string)    // hook, if set, is notified of each call made without a context
+}
+
+// background returns the base context without notifying the hook; used by methods, such
+// as Tx, that have no context accepting variant
+func (c contextFactory) background() context.Context {
+	if c.base != nil {
+		if ctx := c.base(); ctx != nil {
+			return ctx
+		}
+	}
+	return defaultContext
+}
+
+// context notifies the hook that method was called without a context and returns the
+// base context
+func (c contextFactory) context(method string) context.Context {
+	if c.hook != nil {
+		c.hook(method)
 	}
+	return c.background()
 }
 
 // getTimeout returns a timeout equal to attempt^2*defaultTimeout e.g. exponential backoff
@@ -15,8 +15,13 @@
 package ddb
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
@@ -27,6 +32,65 @@ func TestConsumedCapacity_add(t *testing.T) {
 	})
 }
 
+func TestDDB_ConsumedCapacity(t *testing.T) {
+	var (
+		mock = &Mock{readUnits: 2, getItem: Example{ID: "abc"}}
+		db   = New(mock)
+		a    = db.MustTable("a", Example{})
+		b    = db.MustTable("b", Example{})
+	)
+
+	if err := a.Get("abc").Scan(&Example{}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := b.Get("abc").Scan(&Example{}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := a.ConsumedCapacity().ReadUnits, int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := db.ConsumedCapacity().ReadUnits, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got, want := db.ResetConsumedCapacity().ReadUnits, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := db.ConsumedCapacity().ReadUnits, int64(0); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := a.ConsumedCapacity().ReadUnits, int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestDDB_FlushConsumedCapacity(t *testing.T) {
+	var (
+		mock  = &Mock{readUnits: 3, getItem: Example{ID: "abc"}}
+		db    = New(mock)
+		table = db.MustTable("example", Example{})
+	)
+
+	if err := table.Get("abc").Scan(&Example{}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var got int64
+	db.FlushConsumedCapacity(ctx, time.Hour, func(c *ConsumedCapacity) {
+		got += c.ReadUnits
+	})
+	if want := int64(3); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := db.ConsumedCapacity().ReadUnits, int64(0); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
 func TestTable_DDB(t *testing.T) {
 	var (
 		mock  = &Mock{}
@@ -39,6 +103,64 @@ func TestTable_DDB(t *testing.T) {
 	}
 }
 
+func TestDDB_WithReturnConsumedCapacity(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		table := New(&Mock{}).MustTable("example", Example{})
+
+		input, err := table.Get("abc").GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ReturnConsumedCapacity), dynamodb.ReturnConsumedCapacityTotal; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		put, err := table.Put(Example{ID: "abc"}).PutItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := put.ReturnConsumedCapacity; got != nil {
+			t.Fatalf("got %v; want nil", *got)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		db := New(&Mock{}).WithReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityNone)
+		table := db.MustTable("example", Example{})
+
+		query, err := table.Query("#ID = ?", "abc").QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(query.ReturnConsumedCapacity), dynamodb.ReturnConsumedCapacityNone; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		put, err := table.Put(Example{ID: "abc"}).PutItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(put.ReturnConsumedCapacity), dynamodb.ReturnConsumedCapacityNone; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("per call override", func(t *testing.T) {
+		db := New(&Mock{}).WithReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityNone)
+		table := db.MustTable("example", Example{})
+
+		input, err := table.Get("abc").
+			ReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityIndexes).
+			GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ReturnConsumedCapacity), dynamodb.ReturnConsumedCapacityIndexes; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
 func TestDDB_TransactWriteItems(t *testing.T) {
 	t.Run("delete", func(t *testing.T) {
 		var (
@@ -103,3 +225,295 @@ func Test_makeRequestToken(t *testing.T) {
 		t.Fatalf("got blank; want not blank")
 	}
 }
+
+// transactGetMock returns the provided responses from TransactGetItems
+type transactGetMock struct {
+	*Mock
+	responses []*dynamodb.ItemResponse
+}
+
+func (m *transactGetMock) TransactGetItemsWithContext(aws.Context, *dynamodb.TransactGetItemsInput, ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	return &dynamodb.TransactGetItemsOutput{Responses: m.responses}, nil
+}
+
+func TestDDB_TransactGetItems(t *testing.T) {
+	type Sample struct {
+		ID   string `ddb:"hash"`
+		Date string `ddb:"range"`
+	}
+
+	found, err := marshalMap(Sample{ID: "b", Date: "2"})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var (
+		mock = &transactGetMock{
+			Mock:      &Mock{},
+			responses: []*dynamodb.ItemResponse{{}, {Item: found}, {}},
+		}
+		db    = New(mock)
+		table = db.MustTable("example", Sample{})
+		a, b  Sample
+		c     Sample
+	)
+
+	err = db.TransactGetItems(
+		table.Get("a").Range("1").ScanTx(&a),
+		table.Get("b").Range("2").ScanTx(&b),
+		table.Get("c").Range("3").ScanTx(&c),
+	)
+	if !IsItemNotFoundError(err) {
+		t.Fatalf("got %v; want ErrItemNotFound", err)
+	}
+
+	var tge *TransactGetError
+	if !errors.As(err, &tge) {
+		t.Fatalf("got %T; want *TransactGetError", err)
+	}
+	if got, want := len(tge.Missing), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i, want := range []struct {
+		Index int
+		Hash  string
+		Range string
+	}{
+		{Index: 0, Hash: "a", Range: "1"},
+		{Index: 2, Hash: "c", Range: "3"},
+	} {
+		got := tge.Missing[i]
+		if got.Index != want.Index || aws.StringValue(got.HashKey.S) != want.Hash || aws.StringValue(got.RangeKey.S) != want.Range {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got.TableName != "example" {
+			t.Fatalf("got %v; want example", got.TableName)
+		}
+	}
+
+	if got, want := b, (Sample{ID: "b", Date: "2"}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+// transactCapacityMock returns the provided consumed capacity from transactions
+type transactCapacityMock struct {
+	*Mock
+	capacity []*dynamodb.ConsumedCapacity
+}
+
+func (m *transactCapacityMock) TransactWriteItemsWithContext(aws.Context, *dynamodb.TransactWriteItemsInput, ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	return &dynamodb.TransactWriteItemsOutput{ConsumedCapacity: m.capacity}, nil
+}
+
+func (m *transactCapacityMock) TransactGetItemsWithContext(_ aws.Context, input *dynamodb.TransactGetItemsInput, _ ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	item, err := marshalMap(Example{ID: "abc"})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.TransactGetItemsOutput{ConsumedCapacity: m.capacity}
+	for range input.TransactItems {
+		output.Responses = append(output.Responses, &dynamodb.ItemResponse{Item: item})
+	}
+	return output, nil
+}
+
+func TestDDB_TransactConsumedCapacity(t *testing.T) {
+	var (
+		mock = &transactCapacityMock{
+			Mock: &Mock{},
+			capacity: []*dynamodb.ConsumedCapacity{
+				{TableName: aws.String("a"), ReadCapacityUnits: aws.Float64(4), WriteCapacityUnits: aws.Float64(4)},
+				{TableName: aws.String("b"), ReadCapacityUnits: aws.Float64(2), WriteCapacityUnits: aws.Float64(2)},
+				{TableName: aws.String("unknown"), WriteCapacityUnits: aws.Float64(8)},
+			},
+		}
+		db = New(mock)
+		a  = db.MustTable("a", Example{})
+		b  = db.MustTable("b", Example{})
+	)
+
+	_, err := db.TransactWriteItems(
+		a.Put(Example{ID: "abc"}),
+		a.Delete("def"),
+		b.Update("abc").Set("#Name = ?", "name"),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := a.ConsumedCapacity().WriteUnits, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := b.ConsumedCapacity().WriteUnits, int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := db.ConsumedCapacity().WriteUnits, int64(6); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var v1, v2 Example
+	err = db.TransactGetItems(
+		a.Get("abc").ScanTx(&v1),
+		b.Get("abc").ScanTx(&v2),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := a.ConsumedCapacity().ReadUnits, int64(8); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := db.ConsumedCapacity().ReadUnits, int64(12); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestDDB_WithBaseContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	var (
+		methods []string
+		mock    = &deadlineMock{}
+		db      = New(mock).
+			WithBaseContext(func() context.Context { return ctx }).
+			WithNoContextHook(func(method string) { methods = append(methods, method) })
+		table = db.MustTable("example", Example{})
+	)
+
+	if err := table.Put(Example{ID: "abc"}).Run(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want, _ := ctx.Deadline()
+	if !mock.deadline.Equal(want) {
+		t.Fatalf("got %v; want %v", mock.deadline, want)
+	}
+	if got, want := methods, []string{"Put.Run"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := table.Put(Example{ID: "abc"}).RunWithContext(context.Background()); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(methods), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestDDB_WithTokenFunc(t *testing.T) {
+	db := New(&Mock{})
+	if got := db.WithTokenFunc(func() string { return "abc" }); got != db {
+		t.Fatalf("got copy; want same *DDB")
+	}
+	if got, want := db.requestToken(), "abc"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestDDB_OptionsChain(t *testing.T) {
+	var (
+		base    = func() context.Context { return context.Background() }
+		backoff = func(int) time.Duration { return time.Millisecond }
+		token   = func() string { return "abc" }
+		db      = New(&Mock{})
+	)
+
+	got := db.WithBaseContext(base).
+		WithPageRetry(2, backoff).
+		WithTransactAttempts(3).
+		WithTokenFunc(token).
+		WithEmptyValues(EmptyAsEmpty).
+		WithReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityIndexes)
+
+	if got.noContext.base == nil {
+		t.Fatalf("got nil; want base context retained")
+	}
+	if got, want := got.pageRetry.attempts, 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.txAttempts, 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.tokenFunc(), "abc"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.encoder.emptyValues, EmptyAsEmpty; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := got.capacity, dynamodb.ReturnConsumedCapacityIndexes; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if db.tokenFunc != nil || db.txAttempts != defaultMaxAttempts || db.noContext.base != nil {
+		t.Fatalf("got original modified; want unchanged")
+	}
+}
+
+// conflictMock cancels the first conflicts transactions with a TransactionConflict
+type conflictMock struct {
+	*Mock
+	attempts  int
+	conflicts int
+}
+
+func (c *conflictMock) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.attempts++
+	c.writeInput = input
+	if c.attempts <= c.conflicts {
+		return nil, &dynamodb.TransactionCanceledException{
+			CancellationReasons: []*dynamodb.CancellationReason{{Code: aws.String("TransactionConflict")}},
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func TestDDB_TransactWriteItemsWithOptions(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		noDelay = func(int) time.Duration { return 0 }
+	)
+
+	t.Run("override", func(t *testing.T) {
+		var (
+			mock  = &conflictMock{Mock: &Mock{}, conflicts: 4}
+			db    = New(mock).WithTransactAttempts(2)
+			table = db.MustTable("example", Example{})
+		)
+
+		opts := TransactOptions{Attempts: 5, Timeout: noDelay, Token: "token"}
+		if _, err := db.TransactWriteItemsWithOptions(ctx, opts, table.Delete("abc")); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := mock.attempts, 5; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(mock.writeInput.ClientRequestToken), "token"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		var (
+			mock  = &conflictMock{Mock: &Mock{}, conflicts: 4}
+			db    = New(mock).WithTransactAttempts(2).WithTransactTimeout(noDelay)
+			table = db.MustTable("example", Example{})
+		)
+
+		if _, err := db.TransactWriteItemsWithOptions(ctx, TransactOptions{}, table.Delete("abc")); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+		if got, want := mock.attempts, 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		db := New(&Mock{})
+		if _, err := db.TransactWriteItemsWithOptions(ctx, TransactOptions{Attempts: -1}); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
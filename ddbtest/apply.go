@@ -0,0 +1,45 @@
+package ddbtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/savaki/ddb"
+)
+
+// Apply replays the records of event against table in order.  INSERT and MODIFY records
+// put the NewImage; REMOVE records delete the item identified by Keys or, when Keys is
+// not set as with EventBuilder, the OldImage.  This allows stream driven projections to
+// be tested end to end from events produced by EventBuilder.
+func Apply(ctx context.Context, table *ddb.Table, event ddb.Event) error {
+	for i, record := range event.Records {
+		if err := apply(ctx, table, record); err != nil {
+			return fmt.Errorf("unable to apply record %v, %v: %w", i, record.EventName, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, table *ddb.Table, record ddb.Record) error {
+	switch record.EventName {
+	case dynamodbstreams.OperationTypeInsert, dynamodbstreams.OperationTypeModify:
+		if len(record.Change.NewImage) == 0 {
+			return fmt.Errorf("no NewImage; stream view type must include new images")
+		}
+		return table.Put(record.Change.NewImage).RunWithContext(ctx)
+
+	case dynamodbstreams.OperationTypeRemove:
+		keys := record.Change.Keys
+		if len(keys) == 0 {
+			keys = record.Change.OldImage
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("no Keys or OldImage")
+		}
+		return table.Delete(keys).RunWithContext(ctx)
+
+	default:
+		return fmt.Errorf("unsupported event name")
+	}
+}
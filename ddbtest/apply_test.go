@@ -0,0 +1,68 @@
+package ddbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/savaki/ddb"
+)
+
+// memoryAPI holds items in memory keyed by the ID attribute
+type memoryAPI struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (m *memoryAPI) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	m.items[aws.StringValue(input.Item["ID"].S)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *memoryAPI) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	delete(m.items, aws.StringValue(input.Key["ID"].S))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+type Keyed struct {
+	ID   string `ddb:"hash"`
+	Name string
+}
+
+func TestApply(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		api   = &memoryAPI{items: map[string]map[string]*dynamodb.AttributeValue{}}
+		table = ddb.New(api).MustTable("example", Keyed{})
+	)
+
+	event, err := New().
+		Insert(Keyed{ID: "1"}).
+		Insert(Keyed{ID: "2"}).
+		Modify(Keyed{ID: "2"}, Keyed{ID: "2", Name: "New"}).
+		Remove(Keyed{ID: "1"}).
+		Build()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if err := Apply(ctx, table, event); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(api.items), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(api.items["2"]["Name"].S), "New"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		event := ddb.Event{Records: []ddb.Record{{EventName: "BOGUS"}}}
+		if err := Apply(ctx, table, event); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
@@ -82,6 +82,31 @@ func (b *EventBuilder) Remove(oldItem interface{}) *EventBuilder {
 	return b
 }
 
+// Expire adds the removal of oldItem by TTL
+func (b *EventBuilder) Expire(oldItem interface{}) *EventBuilder {
+	fn := func() (ddb.Record, error) {
+		oldImage, err := dynamodbattribute.MarshalMap(oldItem)
+		if err != nil {
+			return ddb.Record{}, err
+		}
+
+		return ddb.Record{
+			Change: ddb.Change{
+				OldImage: oldImage,
+			},
+			EventName: dynamodbstreams.OperationTypeRemove,
+			UserIdentity: &ddb.UserIdentity{
+				PrincipalID: "dynamodb.amazonaws.com",
+				Type:        "Service",
+			},
+		}, nil
+	}
+
+	b.fns = append(b.fns, fn)
+
+	return b
+}
+
 func (b *EventBuilder) Build() (event ddb.Event, err error) {
 	for _, fn := range b.fns {
 		record, err := fn()
@@ -23,3 +23,19 @@ func TestEventBuilder_Remove(t *testing.T) {
 		t.Errorf("expected %v, got %v", want, got)
 	}
 }
+
+func TestEventBuilder_Expire(t *testing.T) {
+	event, err := New().
+		Remove(Sample{ID: "1"}).
+		Expire(Sample{ID: "2"}).
+		Build()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if event.Records[0].IsExpired() {
+		t.Errorf("expected removal not to be expired")
+	}
+	if !event.Records[1].IsExpired() {
+		t.Errorf("expected removal by ttl to be expired")
+	}
+}
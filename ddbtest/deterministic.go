@@ -0,0 +1,59 @@
+package ddbtest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/savaki/ddb"
+)
+
+// deterministicEpoch holds the time at which deterministic clocks start
+var deterministicEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// deterministicClock starts at deterministicEpoch and advances only when waited upon
+// so backoff completes immediately while still appearing to take time
+type deterministicClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *deterministicClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *deterministicClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// deterministicRand produces the same sequence of bytes for each DDB
+type deterministicRand struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+func (r *deterministicRand) Read(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Read(p)
+}
+
+// Deterministic returns a copy of db whose transaction tokens, auto keys, and timestamps
+// are the same from run to run and whose backoff does not sleep.  The clock starts at
+// 2020-01-01T00:00:00Z and advances only by the backoff waited upon.  This allows
+// golden file assertions of inputs that include generated values e.g.
+// ClientRequestToken.  As values depend on the order of calls, tests should not share
+// a deterministic DDB between goroutines.
+func Deterministic(db *ddb.DDB) *ddb.DDB {
+	return db.
+		WithClock(&deterministicClock{now: deterministicEpoch}).
+		WithRand(&deterministicRand{rand: rand.New(rand.NewSource(1))})
+}
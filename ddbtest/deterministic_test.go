@@ -0,0 +1,54 @@
+package ddbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/savaki/ddb"
+)
+
+// transactAPI records the token of each transaction
+type transactAPI struct {
+	dynamodbiface.DynamoDBAPI
+	tokens []string
+}
+
+func (t *transactAPI) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	t.tokens = append(t.tokens, aws.StringValue(input.ClientRequestToken))
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func TestDeterministic(t *testing.T) {
+	type Auto struct {
+		ID string `ddb:"hash,auto=uuid"`
+	}
+
+	run := func() []string {
+		api := &transactAPI{}
+		db := Deterministic(ddb.New(api))
+		table := db.MustTable("example", Auto{})
+		for i := 0; i < 2; i++ {
+			if _, err := db.TransactWriteItemsWithContext(context.Background(), table.Put(Auto{ID: "abc"})); err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+		}
+		return api.tokens
+	}
+
+	first, second := run(), run()
+	if got, want := len(first), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if first[0] == first[1] {
+		t.Fatalf("got duplicate tokens, %v; want distinct", first[0])
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("got %v; want %v", second[i], first[i])
+		}
+	}
+}
@@ -0,0 +1,569 @@
+package ddbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Mock is a dynamodbiface.DynamoDBAPI that answers GetItem, Query, and Scan calls from
+// the expectations declared by ExpectGet, ExpectQuery, and ExpectScan e.g.
+//
+//	mock := ddbtest.NewMock()
+//	ddbtest.ExpectQuery(mock).WithKeyCondition("#PK = ?", "abc").WithIndex("gsi1").Return(items...)
+//	table := ddb.New(mock).MustTable("example", Example{})
+//
+// Expressions are compared after names and values are bound so tests assert what a
+// request means rather than how ddb numbered its placeholders.  Each expectation
+// answers a single call, in the order declared; calls that match no expectation fail.
+type Mock struct {
+	dynamodbiface.DynamoDBAPI
+	mutex        sync.Mutex
+	expectations []*expectation
+}
+
+// NewMock returns a Mock with no expectations
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// ExpectationsWereMet returns an error describing each expectation that has not
+// answered a call
+func (m *Mock) ExpectationsWereMet() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var unmet []string
+	for _, e := range m.expectations {
+		if e.err != nil {
+			return e.err
+		}
+		if !e.met {
+			unmet = append(unmet, e.String())
+		}
+	}
+	if len(unmet) > 0 {
+		return fmt.Errorf("ddbtest: expectations were not met: %v", strings.Join(unmet, "; "))
+	}
+	return nil
+}
+
+// expect registers an expectation of a call to operation
+func (m *Mock) expect(operation string) *expectation {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	e := &expectation{operation: operation}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// answer returns the first unmet expectation matching c
+func (m *Mock) answer(c call) (*expectation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.expectations {
+		if e.met || !e.matches(c) {
+			continue
+		}
+		if e.err != nil {
+			return nil, e.err
+		}
+		e.met = true
+		return e, nil
+	}
+	return nil, fmt.Errorf("ddbtest: unexpected %v", c)
+}
+
+func (m *Mock) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	e, err := m.answer(call{
+		operation: "GetItem",
+		table:     aws.StringValue(input.TableName),
+		key:       input.Key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e.returnErr != nil {
+		return nil, e.returnErr
+	}
+
+	var output dynamodb.GetItemOutput
+	if len(e.items) > 0 {
+		output.Item = e.items[0]
+	}
+	return &output, nil
+}
+
+func (m *Mock) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	e, err := m.answer(call{
+		operation:    "Query",
+		table:        aws.StringValue(input.TableName),
+		index:        aws.StringValue(input.IndexName),
+		keyCondition: resolve(input.KeyConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues),
+		filter:       resolve(input.FilterExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e.returnErr != nil {
+		return nil, e.returnErr
+	}
+
+	return &dynamodb.QueryOutput{
+		Count: aws.Int64(int64(len(e.items))),
+		Items: e.items,
+	}, nil
+}
+
+func (m *Mock) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	e, err := m.answer(call{
+		operation: "Scan",
+		table:     aws.StringValue(input.TableName),
+		index:     aws.StringValue(input.IndexName),
+		filter:    resolve(input.FilterExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e.returnErr != nil {
+		return nil, e.returnErr
+	}
+
+	return &dynamodb.ScanOutput{
+		Count: aws.Int64(int64(len(e.items))),
+		Items: e.items,
+	}, nil
+}
+
+// call holds the bound content of a request
+type call struct {
+	operation    string
+	table        string
+	index        string
+	keyCondition string
+	filter       string
+	key          map[string]*dynamodb.AttributeValue
+}
+
+func (c call) String() string {
+	var b strings.Builder
+	b.WriteString(c.operation)
+	if c.table != "" {
+		fmt.Fprintf(&b, " on table, %v", c.table)
+	}
+	if c.index != "" {
+		fmt.Fprintf(&b, ", index, %v", c.index)
+	}
+	if c.keyCondition != "" {
+		fmt.Fprintf(&b, ", key condition, %v", c.keyCondition)
+	}
+	if c.filter != "" {
+		fmt.Fprintf(&b, ", filter, %v", c.filter)
+	}
+	if c.key != nil {
+		fmt.Fprintf(&b, ", key, %v", encodeValue(c.key))
+	}
+	return b.String()
+}
+
+// expectation holds the content a request must have to match along with the response.
+// Unset fields match any request.
+type expectation struct {
+	operation    string
+	table        *string
+	index        *string
+	keyCondition *string
+	filter       *string
+	key          map[string]*dynamodb.AttributeValue
+	items        []map[string]*dynamodb.AttributeValue
+	returnErr    error // returnErr, if set, is returned to the caller
+	err          error // err, if set, records a mistake in declaring the expectation
+	met          bool
+}
+
+func (e *expectation) matches(c call) bool {
+	equal := func(want *string, got string) bool {
+		return want == nil || *want == got
+	}
+	if e.operation != c.operation {
+		return false
+	}
+	if !equal(e.table, c.table) || !equal(e.index, c.index) {
+		return false
+	}
+	if !equal(e.keyCondition, c.keyCondition) || !equal(e.filter, c.filter) {
+		return false
+	}
+	if e.key != nil && encodeValue(e.key) != encodeValue(c.key) {
+		return false
+	}
+	return true
+}
+
+func (e *expectation) String() string {
+	c := call{operation: e.operation, key: e.key}
+	if e.table != nil {
+		c.table = *e.table
+	}
+	if e.index != nil {
+		c.index = *e.index
+	}
+	if e.keyCondition != nil {
+		c.keyCondition = *e.keyCondition
+	}
+	if e.filter != nil {
+		c.filter = *e.filter
+	}
+	return c.String()
+}
+
+func (e *expectation) bind(target **string, expr string, values []interface{}) {
+	bound, err := bind(expr, values)
+	if err != nil {
+		e.err = err
+		return
+	}
+	*target = &bound
+}
+
+func (e *expectation) setKey(name string, value interface{}) {
+	av, err := marshal(value)
+	if err != nil {
+		e.err = err
+		return
+	}
+	if e.key == nil {
+		e.key = map[string]*dynamodb.AttributeValue{}
+	}
+	e.key[name] = av
+}
+
+func (e *expectation) returnItems(items []interface{}) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		m, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			e.err = fmt.Errorf("ddbtest: unable to marshal item, %T: %w", item, err)
+			return
+		}
+		e.items = append(e.items, m)
+	}
+}
+
+// QueryExpectation declares the content of an expected Query and its response
+type QueryExpectation struct {
+	e *expectation
+}
+
+// ExpectQuery declares a Query the mock expects to receive
+func ExpectQuery(m *Mock) *QueryExpectation {
+	return &QueryExpectation{e: m.expect("Query")}
+}
+
+// WithTable requires the query be made against the table, name
+func (q *QueryExpectation) WithTable(name string) *QueryExpectation {
+	q.e.table = &name
+	return q
+}
+
+// WithIndex requires the query be made against the index, name; use "" to require the
+// base table
+func (q *QueryExpectation) WithIndex(name string) *QueryExpectation {
+	q.e.index = &name
+	return q
+}
+
+// WithKeyCondition requires the key condition of the query to equal expr, written as
+// for ddb e.g. "#PK = ?", once names and values are bound
+func (q *QueryExpectation) WithKeyCondition(expr string, values ...interface{}) *QueryExpectation {
+	q.e.bind(&q.e.keyCondition, expr, values)
+	return q
+}
+
+// WithFilter requires the filter of the query to equal expr once names and values are
+// bound.  Filters added separately to the query are joined with and.
+func (q *QueryExpectation) WithFilter(expr string, values ...interface{}) *QueryExpectation {
+	q.e.bind(&q.e.filter, expr, values)
+	return q
+}
+
+// Return answers the query with items in a single page
+func (q *QueryExpectation) Return(items ...interface{}) *QueryExpectation {
+	q.e.returnItems(items)
+	return q
+}
+
+// ReturnError answers the query with err
+func (q *QueryExpectation) ReturnError(err error) *QueryExpectation {
+	q.e.returnErr = err
+	return q
+}
+
+// ScanExpectation declares the content of an expected Scan and its response.  A
+// parallel scan makes one call per segment, each requiring its own expectation.
+type ScanExpectation struct {
+	e *expectation
+}
+
+// ExpectScan declares a Scan the mock expects to receive
+func ExpectScan(m *Mock) *ScanExpectation {
+	return &ScanExpectation{e: m.expect("Scan")}
+}
+
+// WithTable requires the scan be made against the table, name
+func (s *ScanExpectation) WithTable(name string) *ScanExpectation {
+	s.e.table = &name
+	return s
+}
+
+// WithIndex requires the scan be made against the index, name; use "" to require the
+// base table
+func (s *ScanExpectation) WithIndex(name string) *ScanExpectation {
+	s.e.index = &name
+	return s
+}
+
+// WithFilter requires the filter of the scan to equal expr once names and values are
+// bound
+func (s *ScanExpectation) WithFilter(expr string, values ...interface{}) *ScanExpectation {
+	s.e.bind(&s.e.filter, expr, values)
+	return s
+}
+
+// Return answers the scan with items in a single page
+func (s *ScanExpectation) Return(items ...interface{}) *ScanExpectation {
+	s.e.returnItems(items)
+	return s
+}
+
+// ReturnError answers the scan with err
+func (s *ScanExpectation) ReturnError(err error) *ScanExpectation {
+	s.e.returnErr = err
+	return s
+}
+
+// GetExpectation declares the content of an expected GetItem and its response
+type GetExpectation struct {
+	e *expectation
+}
+
+// ExpectGet declares a GetItem the mock expects to receive.  Without Return, the item
+// is not found.
+func ExpectGet(m *Mock) *GetExpectation {
+	return &GetExpectation{e: m.expect("GetItem")}
+}
+
+// WithTable requires the get be made against the table, name
+func (g *GetExpectation) WithTable(name string) *GetExpectation {
+	g.e.table = &name
+	return g
+}
+
+// WithKey requires the key attribute, name, to hold value; call once for each key
+// attribute
+func (g *GetExpectation) WithKey(name string, value interface{}) *GetExpectation {
+	g.e.setKey(name, value)
+	return g
+}
+
+// Return answers the get with item
+func (g *GetExpectation) Return(item interface{}) *GetExpectation {
+	g.e.returnItems([]interface{}{item})
+	return g
+}
+
+// ReturnError answers the get with err
+func (g *GetExpectation) ReturnError(err error) *GetExpectation {
+	g.e.returnErr = err
+	return g
+}
+
+// isNameRune returns true if r may be part of an attribute name or placeholder
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// scanName returns the name at the start of runes
+func scanName(runes []rune) string {
+	n := 0
+	for n < len(runes) && isNameRune(runes[n]) {
+		n++
+	}
+	return string(runes[:n])
+}
+
+// bind renders expr, written as for ddb, with #Name replaced by Name, #? replaced by
+// the next value as a name, and ? replaced by the next value encoded as an attribute
+// value
+func bind(expr string, values []interface{}) (string, error) {
+	var (
+		b     strings.Builder
+		runes = []rune(expr)
+		next  = 0
+	)
+	value := func() (interface{}, error) {
+		if next >= len(values) {
+			return nil, fmt.Errorf("ddbtest: not enough values for expression, %v", expr)
+		}
+		v := values[next]
+		next++
+		return v, nil
+	}
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '#' && i+1 < len(runes) && runes[i+1] == '?':
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprint(&b, v)
+			i++
+		case r == '#':
+			name := scanName(runes[i+1:])
+			b.WriteString(name)
+			i += len([]rune(name))
+		case r == '?':
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			av, err := marshal(v)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(encodeValue(av))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if next != len(values) {
+		return "", fmt.Errorf("ddbtest: got %v values for expression, %v; want %v", len(values), expr, next)
+	}
+
+	return normalize(b.String()), nil
+}
+
+// resolve renders expr with each name and value placeholder replaced by the name or
+// encoded value it refers to
+func resolve(expr *string, names map[string]*string, values map[string]*dynamodb.AttributeValue) string {
+	if expr == nil {
+		return ""
+	}
+
+	var (
+		b     strings.Builder
+		runes = []rune(*expr)
+	)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '#' && r != ':' {
+			b.WriteRune(r)
+			continue
+		}
+
+		placeholder := string(r) + scanName(runes[i+1:])
+		switch {
+		case r == '#' && names[placeholder] != nil:
+			b.WriteString(aws.StringValue(names[placeholder]))
+		case r == ':' && values[placeholder] != nil:
+			b.WriteString(encodeValue(values[placeholder]))
+		default:
+			b.WriteString(placeholder)
+		}
+		i += len([]rune(placeholder)) - 1
+	}
+
+	return normalize(b.String())
+}
+
+// normalize collapses whitespace so expressions compare by content
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func marshal(v interface{}) (*dynamodb.AttributeValue, error) {
+	if av, ok := v.(*dynamodb.AttributeValue); ok {
+		return av, nil
+	}
+	av, err := dynamodbattribute.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("ddbtest: unable to marshal value, %v: %w", v, err)
+	}
+	return av, nil
+}
+
+// encodeValue returns a compact, stable encoding of an attribute value or map of
+// attribute values
+func encodeValue(v interface{}) string {
+	var simple interface{}
+	switch v := v.(type) {
+	case *dynamodb.AttributeValue:
+		simple = simplify(v)
+	case map[string]*dynamodb.AttributeValue:
+		if v != nil {
+			simple = simplifyMap(v)
+		}
+	}
+	data, err := json.Marshal(simple)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// simplify returns av with unset fields omitted
+func simplify(av *dynamodb.AttributeValue) interface{} {
+	if av == nil {
+		return nil
+	}
+
+	m := map[string]interface{}{}
+	switch {
+	case av.S != nil:
+		m["S"] = *av.S
+	case av.N != nil:
+		m["N"] = *av.N
+	case av.B != nil:
+		m["B"] = av.B
+	case av.BOOL != nil:
+		m["BOOL"] = *av.BOOL
+	case av.NULL != nil:
+		m["NULL"] = *av.NULL
+	case av.SS != nil:
+		m["SS"] = aws.StringValueSlice(av.SS)
+	case av.NS != nil:
+		m["NS"] = aws.StringValueSlice(av.NS)
+	case av.BS != nil:
+		m["BS"] = av.BS
+	case av.L != nil:
+		var list []interface{}
+		for _, item := range av.L {
+			list = append(list, simplify(item))
+		}
+		m["L"] = list
+	case av.M != nil:
+		m["M"] = simplifyMap(av.M)
+	}
+	return m
+}
+
+func simplifyMap(item map[string]*dynamodb.AttributeValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		m[k] = simplify(v)
+	}
+	return m
+}
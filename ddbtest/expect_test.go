@@ -0,0 +1,174 @@
+package ddbtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/savaki/ddb"
+)
+
+type Order struct {
+	PK     string `ddb:"hash"`
+	SK     string `ddb:"range"`
+	Status string `ddb:"gsi_hash:gsi1"`
+	Total  int
+}
+
+func TestExpectQuery(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = NewMock()
+		want  = []Order{{PK: "abc", SK: "1", Total: 10}, {PK: "abc", SK: "2", Total: 20}}
+		table = ddb.New(mock).MustTable("orders", Order{})
+	)
+
+	ExpectQuery(mock).
+		WithTable("orders").
+		WithIndex("").
+		WithKeyCondition("#PK = ? and begins_with(#SK, ?)", "abc", "").
+		WithFilter("#Total > ?", 5).
+		Return(want[0], want[1])
+
+	var got []Order
+	err := table.Query("#PK = ? and begins_with(#SK, ?)", "abc", "").
+		Filter("#Total > ?", 5).
+		FindAllWithContext(ctx, &got)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	t.Run("index", func(t *testing.T) {
+		mock := NewMock()
+		table := ddb.New(mock).MustTable("orders", Order{})
+		ExpectQuery(mock).WithKeyCondition("#Status = ?", "open").WithIndex("gsi1").Return(want[0])
+
+		var got []Order
+		if err := table.Query("#Status = ?", "open").IndexName("gsi1").FindAllWithContext(ctx, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		mock := NewMock()
+		table := ddb.New(mock).MustTable("orders", Order{})
+		ExpectQuery(mock).WithKeyCondition("#PK = ?", "abc")
+
+		var got []Order
+		if err := table.Query("#PK = ?", "def").FindAllWithContext(ctx, &got); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+		if err := mock.ExpectationsWereMet(); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func TestExpectScan(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = NewMock()
+		boom  = errors.New("boom")
+		table = ddb.New(mock).MustTable("orders", Order{})
+	)
+
+	ExpectScan(mock).WithFilter("#Status = ?", "open").Return(Order{PK: "abc", SK: "1"})
+	ExpectScan(mock).WithFilter("#Status = ?", "closed").ReturnError(boom)
+
+	var count int
+	err := table.Scan().Filter("#Status = ?", "open").EachWithContext(ctx, func(item ddb.Item) (bool, error) {
+		count++
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := count, 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	err = table.Scan().Filter("#Status = ?", "closed").EachWithContext(ctx, func(item ddb.Item) (bool, error) {
+		return true, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
+
+func TestExpectGet(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = NewMock()
+		want  = Order{PK: "abc", SK: "1", Total: 10}
+		table = ddb.New(mock).MustTable("orders", Order{})
+	)
+
+	ExpectGet(mock).WithTable("orders").WithKey("PK", "abc").WithKey("SK", "1").Return(want)
+	ExpectGet(mock).WithKey("PK", "abc").WithKey("SK", "2")
+
+	var got Order
+	if err := table.Get("abc").Range("1").ScanWithContext(ctx, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	err := table.Get("abc").Range("2").ScanWithContext(ctx, &got)
+	if !ddb.IsItemNotFoundError(err) {
+		t.Fatalf("got %v; want item not found", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	testCases := map[string]struct {
+		Expr   string
+		Values []interface{}
+		Want   string
+	}{
+		"names": {
+			Expr:   "#PK = ?  and  #a.#b > ?",
+			Values: []interface{}{"abc", 1},
+			Want:   `PK = {"S":"abc"} and a.b > {"N":"1"}`,
+		},
+		"bound name": {
+			Expr:   "attribute_exists(#?)",
+			Values: []interface{}{"Status"},
+			Want:   `attribute_exists(Status)`,
+		},
+	}
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := bind(tc.Expr, tc.Values)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+
+	if _, err := bind("#PK = ?", nil); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+	if _, err := bind("#PK = ?", []interface{}{"a", "b"}); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
@@ -0,0 +1,81 @@
+package ddbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/savaki/ddb"
+)
+
+// Snapshot holds the items of a table at a point in time; see TakeSnapshot
+type Snapshot struct {
+	Items []map[string]*dynamodb.AttributeValue
+}
+
+// TakeSnapshot captures every item in table with a consistent scan so the table may
+// later be reset with Restore e.g. between test cases run against DynamoDB Local
+func TakeSnapshot(ctx context.Context, table *ddb.Table) (Snapshot, error) {
+	var snapshot Snapshot
+	err := table.Scan().ConsistentRead(true).EachWithContext(ctx, func(item ddb.Item) (bool, error) {
+		snapshot.Items = append(snapshot.Items, item.Raw())
+		return true, nil
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("unable to snapshot table: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Restore returns table to the state captured by snapshot.  Items not in the snapshot
+// are deleted and items that differ from the snapshot are put back; items left
+// unchanged since the snapshot are not written.
+func Restore(ctx context.Context, table *ddb.Table, snapshot Snapshot) error {
+	want := map[string]map[string]*dynamodb.AttributeValue{}
+	for _, item := range snapshot.Items {
+		id, err := identify(item)
+		if err != nil {
+			return err
+		}
+		want[id] = item
+	}
+
+	var stale []map[string]*dynamodb.AttributeValue
+	err := table.Scan().ConsistentRead(true).EachWithContext(ctx, func(item ddb.Item) (bool, error) {
+		id, err := identify(item.Raw())
+		if err != nil {
+			return false, err
+		}
+		if _, ok := want[id]; ok {
+			delete(want, id) // unchanged
+			return true, nil
+		}
+		stale = append(stale, item.Raw())
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to restore table: %w", err)
+	}
+
+	for _, item := range stale {
+		if err := table.Delete(item).RunWithContext(ctx); err != nil {
+			return fmt.Errorf("unable to restore table: %w", err)
+		}
+	}
+	for _, item := range want {
+		if err := table.Put(item).RunWithContext(ctx); err != nil {
+			return fmt.Errorf("unable to restore table: %w", err)
+		}
+	}
+	return nil
+}
+
+// identify returns a string that is equal for items with equal attributes
+func identify(item map[string]*dynamodb.AttributeValue) (string, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode item: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,109 @@
+package ddbtest
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/savaki/ddb"
+)
+
+func (m *memoryAPI) ScanWithContext(_ aws.Context, _ *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	var ids []string
+	for id := range m.items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var output dynamodb.ScanOutput
+	for _, id := range ids {
+		output.Items = append(output.Items, m.items[id])
+	}
+	output.Count = aws.Int64(int64(len(output.Items)))
+	return &output, nil
+}
+
+// countingAPI counts the items written to memoryAPI
+type countingAPI struct {
+	*memoryAPI
+	puts, deletes int
+}
+
+func (c *countingAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	c.puts++
+	return c.memoryAPI.PutItemWithContext(ctx, input, opts...)
+}
+
+func (c *countingAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	c.deletes++
+	return c.memoryAPI.DeleteItemWithContext(ctx, input, opts...)
+}
+
+func TestSnapshot(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		api   = &countingAPI{memoryAPI: &memoryAPI{items: map[string]map[string]*dynamodb.AttributeValue{}}}
+		table = ddb.New(api).MustTable("example", Keyed{})
+	)
+
+	for _, item := range []Keyed{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}, {ID: "3", Name: "c"}} {
+		if err := table.Put(item).RunWithContext(ctx); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+
+	snapshot, err := TakeSnapshot(ctx, table)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(snapshot.Items), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// modify 2, remove 3, and add 4
+	if err := table.Put(Keyed{ID: "2", Name: "changed"}).RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Delete("3").RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Put(Keyed{ID: "4"}).RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	api.puts, api.deletes = 0, 0
+	if err := Restore(ctx, table, snapshot); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := api.puts, 2; got != want {
+		t.Fatalf("got %v puts; want %v", got, want)
+	}
+	if got, want := api.deletes, 2; got != want {
+		t.Fatalf("got %v deletes; want %v", got, want)
+	}
+
+	var got []Keyed
+	err = table.Scan().EachWithContext(ctx, func(item ddb.Item) (bool, error) {
+		var v Keyed
+		if err := item.Unmarshal(&v); err != nil {
+			return false, err
+		}
+		got = append(got, v)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := []Keyed{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}, {ID: "3", Name: "c"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
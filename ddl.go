@@ -268,7 +268,7 @@ func (t *Table) CreateTableIfNotExists(ctx context.Context, opts ...TableOption)
 		if v, ok := err.(awserr.Error); ok && v.Code() == dynamodb.ErrCodeResourceInUseException {
 			return nil
 		}
-		return err
+		return wrapAWSError(err, "CreateTable", t.spec, "", nil)
 	}
 
 	return nil
@@ -283,8 +283,55 @@ func (t *Table) DeleteTableIfExists(ctx context.Context) error {
 			return nil
 		}
 
-		return err
+		return wrapAWSError(err, "DeleteTable", t.spec, "", nil)
 	}
 
 	return nil
 }
+
+// TableStats holds the approximate size of a table and its indexes as reported by
+// DescribeTable.  DynamoDB refreshes these values roughly every six hours.
+type TableStats struct {
+	ItemCount      int64
+	TableSizeBytes int64
+	Indexes        map[string]IndexStats // keyed by index name; includes global and local secondary indexes
+}
+
+// IndexStats holds the approximate size of a secondary index
+type IndexStats struct {
+	ItemCount      int64
+	IndexSizeBytes int64
+}
+
+// ApproxStats returns the approximate item count and size of the table and its
+// secondary indexes.  Each call issues a fresh DescribeTable.
+func (t *Table) ApproxStats(ctx context.Context) (TableStats, error) {
+	input := dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	}
+	output, err := t.ddb.api.DescribeTableWithContext(ctx, &input)
+	if err != nil {
+		return TableStats{}, wrapAWSError(err, "DescribeTable", t.spec, "", nil)
+	}
+
+	table := output.Table
+	stats := TableStats{
+		ItemCount:      aws.Int64Value(table.ItemCount),
+		TableSizeBytes: aws.Int64Value(table.TableSizeBytes),
+		Indexes:        map[string]IndexStats{},
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		stats.Indexes[aws.StringValue(index.IndexName)] = IndexStats{
+			ItemCount:      aws.Int64Value(index.ItemCount),
+			IndexSizeBytes: aws.Int64Value(index.IndexSizeBytes),
+		}
+	}
+	for _, index := range table.LocalSecondaryIndexes {
+		stats.Indexes[aws.StringValue(index.IndexName)] = IndexStats{
+			ItemCount:      aws.Int64Value(index.ItemCount),
+			IndexSizeBytes: aws.Int64Value(index.IndexSizeBytes),
+		}
+	}
+
+	return stats, nil
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -28,8 +29,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
 type Sample struct {
@@ -234,6 +237,66 @@ func TestDeleteTable(t *testing.T) {
 	})
 }
 
+// describeMock returns output from DescribeTable
+type describeMock struct {
+	dynamodbiface.DynamoDBAPI
+	output *dynamodb.DescribeTableOutput
+	err    error
+}
+
+func (d *describeMock) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return d.output, d.err
+}
+
+func TestTable_ApproxStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ok", func(t *testing.T) {
+		mock := &describeMock{
+			output: &dynamodb.DescribeTableOutput{
+				Table: &dynamodb.TableDescription{
+					ItemCount:      aws.Int64(10),
+					TableSizeBytes: aws.Int64(1000),
+					GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+						{IndexName: aws.String("gsi"), ItemCount: aws.Int64(4), IndexSizeBytes: aws.Int64(400)},
+					},
+					LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndexDescription{
+						{IndexName: aws.String("lsi"), ItemCount: aws.Int64(10), IndexSizeBytes: aws.Int64(900)},
+					},
+				},
+			},
+		}
+		table := New(mock).MustTable("example", Example{})
+		got, err := table.ApproxStats(ctx)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		want := TableStats{
+			ItemCount:      10,
+			TableSizeBytes: 1000,
+			Indexes: map[string]IndexStats{
+				"gsi": {ItemCount: 4, IndexSizeBytes: 400},
+				"lsi": {ItemCount: 10, IndexSizeBytes: 900},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mock := &describeMock{
+			err: awserr.New(dynamodb.ErrCodeResourceNotFoundException, "boom", nil),
+		}
+		table := New(mock).MustTable("example", Example{})
+		_, err := table.ApproxStats(ctx)
+		if !errors.Is(err, mock.err) {
+			t.Fatalf("got %v; want %v", err, mock.err)
+		}
+	})
+}
+
 func TestTable_CreateTableIfNotExists_Live(t *testing.T) {
 	if !runIntegrationTests {
 		t.SkipNow()
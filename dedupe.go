@@ -0,0 +1,81 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// DefaultDedupeLimit holds the default number of primary keys remembered by Query.Dedupe
+const DefaultDedupeLimit = 100000
+
+// deduper remembers up to limit primary keys, discarding the oldest keys first
+type deduper struct {
+	names []string
+	limit int
+	seen  map[string]struct{}
+	order []string
+}
+
+func newDeduper(spec *tableSpec, limit int) *deduper {
+	var names []string
+	for _, key := range []*keySpec{spec.HashKey, spec.RangeKey} {
+		if key != nil {
+			names = append(names, key.AttributeName)
+		}
+	}
+
+	return &deduper{
+		names: names,
+		limit: limit,
+		seen:  map[string]struct{}{},
+	}
+}
+
+// isDuplicate returns true if an item with the same primary key has already been seen.
+// Items missing a key attribute, e.g. because of a projection, cannot be compared and
+// return ErrValidation rather than being mistaken for one another.
+func (d *deduper) isDuplicate(raw map[string]*dynamodb.AttributeValue) (bool, error) {
+	key := make([]*dynamodb.AttributeValue, 0, len(d.names))
+	for _, name := range d.names {
+		v, ok := raw[name]
+		if !ok || v == nil {
+			return false, errorf(ErrValidation, "unable to dedupe item: missing key attribute, %v", name)
+		}
+		key = append(key, v)
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return false, fmt.Errorf("unable to encode primary key: %w", err)
+	}
+
+	id := string(data)
+	if _, ok := d.seen[id]; ok {
+		return true, nil
+	}
+
+	if d.limit > 0 && len(d.order) >= d.limit {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.seen[id] = struct{}{}
+	d.order = append(d.order, id)
+
+	return false, nil
+}
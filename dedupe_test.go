@@ -0,0 +1,99 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestQuery_Dedupe(t *testing.T) {
+	items := []interface{}{
+		QueryExample{ID: "abc", Date: "1"},
+		QueryExample{ID: "abc", Date: "2"},
+		QueryExample{ID: "abc", Date: "1"},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		var (
+			mock  = &Mock{queryItems: items}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		var got []QueryExample
+		if err := table.Query("#ID = ?", "abc").FindAll(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 3; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		var (
+			mock  = &Mock{queryItems: items}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		var got []QueryExample
+		if err := table.Query("#ID = ?", "abc").Dedupe().FindAll(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestDeduper(t *testing.T) {
+	spec, err := inspect("example", QueryExample{})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	item := func(date string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{
+			"ID":   {S: aws.String("abc")},
+			"Date": {S: aws.String(date)},
+		}
+	}
+
+	d := newDeduper(spec, 2)
+	for i, tc := range []struct {
+		Date string
+		Want bool
+	}{
+		{Date: "1", Want: false},
+		{Date: "1", Want: true},
+		{Date: "2", Want: false},
+		{Date: "3", Want: false}, // evicts 1
+		{Date: "1", Want: false},
+		{Date: "3", Want: true},
+	} {
+		got, err := d.isDuplicate(item(tc.Date))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got != tc.Want {
+			t.Fatalf("%v: got %v; want %v", i, got, tc.Want)
+		}
+	}
+
+	if _, err := d.isDuplicate(map[string]*dynamodb.AttributeValue{"ID": {S: aws.String("abc")}}); !IsValidationError(err) {
+		t.Fatalf("got %v; want ErrValidation", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// fieldDefault holds the value assigned to a zero field on Put
+type fieldDefault struct {
+	FieldName string        // FieldName from struct
+	Value     reflect.Value // Value holds the parsed default
+}
+
+// parseDefault converts the text of a default= tag option into a value of the field type
+func parseDefault(field reflect.StructField, text string) (reflect.Value, error) {
+	v := reflect.New(field.Type).Elem()
+	switch field.Type.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, field.Type.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid default for field %v: %w", field.Name, err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, field.Type.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid default for field %v: %w", field.Name, err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, field.Type.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid default for field %v: %w", field.Name, err)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid default for field %v: %w", field.Name, err)
+		}
+		v.SetBool(b)
+	default:
+		return reflect.Value{}, fmt.Errorf("default option not supported for field %v of type %v", field.Name, field.Type)
+	}
+	return v, nil
+}
+
+// applyDefaults assigns tag defaults to zero valued fields.  Fields are assigned in
+// place when v is a pointer to a struct; otherwise a modified copy is returned.
+func applyDefaults(spec *tableSpec, v interface{}) interface{} {
+	if len(spec.Defaults) == 0 {
+		return v
+	}
+
+	value, v, ok := addressableStruct(v)
+	if !ok {
+		return v
+	}
+
+	for _, def := range spec.Defaults {
+		field := value.FieldByName(def.FieldName)
+		if !field.IsValid() || !field.IsZero() {
+			continue
+		}
+		field.Set(def.Value)
+	}
+
+	return v
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+type Status string
+
+type DefaultExample struct {
+	ID      string  `ddb:"hash"`
+	Status  Status  `ddb:"default=active"`
+	Ratio   float64 `ddb:",default=0.5"`
+	Retries int     `ddb:"default=3"`
+	Enabled bool    `ddb:"default=true"`
+}
+
+func TestPut_Defaults(t *testing.T) {
+	var (
+		mock  = &Mock{}
+		table = New(mock).MustTable("example", DefaultExample{})
+	)
+
+	t.Run("zero fields", func(t *testing.T) {
+		example := DefaultExample{ID: "abc"}
+		if err := table.Put(&example).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		want := DefaultExample{ID: "abc", Status: "active", Ratio: 0.5, Retries: 3, Enabled: true}
+		if got := example; got != want {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["Status"].S), "active"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("preserves values", func(t *testing.T) {
+		example := DefaultExample{ID: "abc", Status: "pending", Retries: 1}
+		if err := table.Put(example).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["Status"].S), "pending"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["Retries"].N), "1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		type Invalid struct {
+			ID    string `ddb:"hash"`
+			Count int    `ddb:"default=abc"`
+		}
+		if _, err := New(mock).Table("example", Invalid{}); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
@@ -28,10 +28,18 @@ type Delete struct {
 	hashKey                             interface{}
 	rangeKey                            interface{}
 	table                               *ConsumedCapacity
+	capacity                            string
 	request                             *ConsumedCapacity
 	err                                 error
 	expr                                *expression
 	returnValuesOnConditionCheckFailure string
+	modify                              []func(*dynamodb.DeleteItemInput)
+	noContext                           contextFactory
+	stats                               *Stats
+	clock                               Clock // clock times the request for Stats
+	conflicts                           *contention
+	conditions                          *conditions
+	hooked                              bool // hooked is true once BeforeDelete has been applied
 }
 
 func (d *Delete) Condition(expr string, values ...interface{}) *Delete {
@@ -42,12 +50,48 @@ func (d *Delete) Condition(expr string, values ...interface{}) *Delete {
 	return d
 }
 
+// ConditionNamed adds the condition defined on the table as name; see Table.DefineCondition
+func (d *Delete) ConditionNamed(name string) *Delete {
+	condition, err := d.conditions.lookup(name)
+	if err != nil {
+		d.err = err
+		return d
+	}
+	return d.Condition(condition.expr, condition.values...)
+}
+
+// AttributeExists adds a condition that the attribute, name, exists e.g. #Field
+func (d *Delete) AttributeExists(name string) *Delete {
+	expr, values := attributeFunc("attribute_exists", name)
+	return d.Condition(expr, values...)
+}
+
+// AttributeNotExists adds a condition that the attribute, name, does not exist e.g. #Field
+func (d *Delete) AttributeNotExists(name string) *Delete {
+	expr, values := attributeFunc("attribute_not_exists", name)
+	return d.Condition(expr, values...)
+}
+
 // ConsumedCapacity captures consumed capacity to the property provided
 func (d *Delete) ConsumedCapacity(capture *ConsumedCapacity) *Delete {
 	d.request = capture
 	return d
 }
 
+// Stats captures the attempts, duration, and consumed capacity of the request to the
+// property provided
+func (d *Delete) Stats(capture *Stats) *Delete {
+	d.stats = capture
+	return d
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (d *Delete) ReturnConsumedCapacity(v string) *Delete {
+	d.capacity = v
+	return d
+}
+
 func (d *Delete) DeleteItemInput() (*dynamodb.DeleteItemInput, error) {
 	if d.err != nil {
 		return nil, d.err
@@ -59,14 +103,27 @@ func (d *Delete) DeleteItemInput() (*dynamodb.DeleteItemInput, error) {
 	}
 
 	conditionExpression := d.expr.ConditionExpression()
-	return &dynamodb.DeleteItemInput{
+	input := dynamodb.DeleteItemInput{
 		ConditionExpression:       conditionExpression,
 		ExpressionAttributeNames:  d.expr.Names,
 		ExpressionAttributeValues: d.expr.Values,
 		Key:                       key,
-		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		ReturnConsumedCapacity:    returnConsumedCapacity(d.capacity),
 		TableName:                 aws.String(d.spec.TableName),
-	}, nil
+	}
+	for _, fn := range d.modify {
+		fn(&input)
+	}
+
+	return &input, nil
+}
+
+// Modify registers a function to alter the DeleteItemInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  When called multiple times, the
+// functions are applied in order.
+func (d *Delete) Modify(fn func(input *dynamodb.DeleteItemInput)) *Delete {
+	d.modify = append(d.modify, fn)
+	return d
 }
 
 // Use ReturnValuesOnConditionCheckFailure to get the item attributes if the
@@ -85,29 +142,56 @@ func (d *Delete) Range(rangeKey interface{}) *Delete {
 }
 
 func (d *Delete) RunWithContext(ctx context.Context) error {
+	if err := beforeDelete(ctx, d); err != nil {
+		return err
+	}
+
 	input, err := d.DeleteItemInput()
 	if err != nil {
 		return err
 	}
 
-	output, err := d.api.DeleteItemWithContext(ctx, input)
+	defer d.stats.since(d.clock, d.clock.Now())
+	d.stats.attempt(false)
+	output, err := d.api.DeleteItemWithContext(ctx, input, d.stats.options()...)
 	if err != nil {
-		return err
+		d.conflicts.record(err)
+		return wrapAWSError(err, "DeleteItem", d.spec, "", input.Key)
 	}
 
 	d.table.add(output.ConsumedCapacity)
 	if d.request != nil {
 		d.request.add(output.ConsumedCapacity)
 	}
+	d.stats.consumed(output.ConsumedCapacity)
 
 	return nil
 }
 
 func (d *Delete) Run() error {
-	return d.RunWithContext(defaultContext)
+	return d.RunWithContext(d.noContext.context("Delete.Run"))
+}
+
+// tableSpec implements specSource
+func (d *Delete) tableSpec() *tableSpec {
+	return d.spec
+}
+
+// tableCapacity implements capacitySink
+func (d *Delete) tableCapacity() (string, *ConsumedCapacity) {
+	return d.spec.TableName, d.table
+}
+
+// tableContention implements contentionSink
+func (d *Delete) tableContention() *contention {
+	return d.conflicts
 }
 
 func (d *Delete) Tx() (*dynamodb.TransactWriteItem, error) {
+	if err := beforeDelete(d.noContext.background(), d); err != nil {
+		return nil, err
+	}
+
 	input, err := d.DeleteItemInput()
 	if err != nil {
 		return nil, err
@@ -129,12 +213,19 @@ func (d *Delete) Tx() (*dynamodb.TransactWriteItem, error) {
 	return &writeItem, nil
 }
 
+// Delete deletes the item with the given hash key.  hashKey may also be a key struct
+// or key map; see Table.Get.
 func (t *Table) Delete(hashKey interface{}) *Delete {
 	return &Delete{
-		api:     t.ddb.api,
-		spec:    t.spec,
-		hashKey: hashKey,
-		table:   t.consumed,
-		expr:    newExpression(t.spec.Attributes...),
+		api:        t.ddb.api,
+		spec:       t.spec,
+		hashKey:    hashKey,
+		table:      t.consumed,
+		conflicts:  t.conflicts,
+		conditions: t.conditions,
+		capacity:   t.ddb.capacity,
+		noContext:  t.ddb.noContext,
+		clock:      t.ddb.clock,
+		expr:       t.newExpression(),
 	}
 }
@@ -15,6 +15,7 @@
 package ddb
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -50,13 +51,13 @@ func TestDelete_Condition(t *testing.T) {
 
 	t.Run("multiple", func(t *testing.T) {
 		var (
-			item  = DeleteTable{ID: "abc"}
+			item  = DeleteTable{ID: "abc", Date: "2006-01-02"}
 			mock  = &Mock{}
 			db    = New(mock)
 			table = db.MustTable("example", DeleteTable{})
 		)
 
-		del := table.Delete(item.ID)
+		del := table.Delete(item.ID).Range(item.Date)
 		del.Condition("#Field > ?", 0)
 		del.Condition("#Field < ?", 10)
 		err := del.Run()
@@ -77,9 +78,9 @@ func TestDelete_Condition(t *testing.T) {
 			table    = db.MustTable("example", DeleteTable{})
 		)
 
-		del := table.Delete("blah")
+		del := table.Delete("blah").Range("2006-01-02")
 		err := del.Run()
-		if err != original {
+		if !errors.Is(err, original) {
 			t.Fatalf("got %v; want %v", err, original)
 		}
 	})
@@ -96,7 +97,7 @@ func TestDelete_ConsumedCapacity(t *testing.T) {
 		capacity ConsumedCapacity
 	)
 
-	del := table.Delete("blah").ConsumedCapacity(&capacity)
+	del := table.Delete("blah").Range("2006-01-02").ConsumedCapacity(&capacity)
 	err := del.Run()
 	if err != nil {
 		t.Fatalf("got %v; want nil", err)
@@ -0,0 +1,134 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// MarshalDynamoJSON encodes the struct or map, v, as a DynamoDB JSON item, the format
+// used by table exports, the console, and streams e.g.
+//
+//	{"ID":{"S":"abc"},"Count":{"N":"3"}}
+//
+// Only the populated type of each attribute value is written.
+func MarshalDynamoJSON(v interface{}) ([]byte, error) {
+	item, err := marshalMap(v)
+	if err != nil {
+		return nil, wrapf(err, ErrUnableToMarshalItem, "unable to encode %T", v)
+	}
+
+	m := make(map[string]interface{}, len(item))
+	for k, av := range item {
+		m[k] = dynamoJSONValue(av)
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalDynamoJSON decodes a DynamoDB JSON item, as produced by MarshalDynamoJSON,
+// into v
+func UnmarshalDynamoJSON(data []byte, v interface{}) error {
+	var item map[string]*dynamodb.AttributeValue
+	if err := json.Unmarshal(data, &item); err != nil {
+		return fmt.Errorf("unable to decode dynamodb json: %w", err)
+	}
+
+	return unmarshalMap(item, v)
+}
+
+// dynamoJSONValue returns the attribute value with only its populated type such
+// that it encodes as DynamoDB JSON
+func dynamoJSONValue(av *dynamodb.AttributeValue) interface{} {
+	switch {
+	case av == nil:
+		return map[string]bool{"NULL": true}
+	case av.B != nil:
+		return map[string][]byte{"B": av.B}
+	case av.BOOL != nil:
+		return map[string]bool{"BOOL": *av.BOOL}
+	case av.BS != nil:
+		return map[string][][]byte{"BS": av.BS}
+	case av.L != nil:
+		values := make([]interface{}, 0, len(av.L))
+		for _, item := range av.L {
+			values = append(values, dynamoJSONValue(item))
+		}
+		return map[string]interface{}{"L": values}
+	case av.M != nil:
+		values := make(map[string]interface{}, len(av.M))
+		for k, item := range av.M {
+			values[k] = dynamoJSONValue(item)
+		}
+		return map[string]interface{}{"M": values}
+	case av.N != nil:
+		return map[string]string{"N": *av.N}
+	case av.NS != nil:
+		return map[string][]*string{"NS": av.NS}
+	case av.S != nil:
+		return map[string]string{"S": *av.S}
+	case av.SS != nil:
+		return map[string][]*string{"SS": av.SS}
+	default:
+		return map[string]bool{"NULL": true}
+	}
+}
+
+// RawAttribute holds an attribute value as is, without unmarshalling it, so items may be
+// copied between tables or passed on to clients without knowledge of their schema e.g.
+//
+//	var item map[string]ddb.RawAttribute
+//	if err := source.Get(id).Scan(&item); err != nil { ... }
+//	if err := target.Put(item).Run(); err != nil { ... }
+//
+// RawAttribute encodes as DynamoDB JSON.
+type RawAttribute struct {
+	Value *dynamodb.AttributeValue
+}
+
+// MarshalDynamoDBAttributeValue implements dynamodbattribute.Marshaler
+func (r RawAttribute) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	if r.Value == nil {
+		item.NULL = aws.Bool(true)
+		return nil
+	}
+	*item = *r.Value
+	return nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements dynamodbattribute.Unmarshaler
+func (r *RawAttribute) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	r.Value = item
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (r RawAttribute) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dynamoJSONValue(r.Value))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *RawAttribute) UnmarshalJSON(data []byte) error {
+	var item dynamodb.AttributeValue
+	if err := json.Unmarshal(data, &item); err != nil {
+		return fmt.Errorf("unable to decode dynamodb json: %w", err)
+	}
+	r.Value = &item
+	return nil
+}
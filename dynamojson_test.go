@@ -0,0 +1,119 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDynamoJSON(t *testing.T) {
+	type Nested struct {
+		Color string
+	}
+	type Sample struct {
+		ID     string `ddb:"hash"`
+		Count  int
+		Active bool
+		Data   []byte
+		Tags   []string `dynamodbav:",stringset"`
+		Items  []Nested
+		Attrs  map[string]int
+		Empty  *string
+	}
+
+	want := Sample{
+		ID:     "abc",
+		Count:  3,
+		Active: true,
+		Data:   []byte("hello"),
+		Tags:   []string{"a"},
+		Items:  []Nested{{Color: "red"}},
+		Attrs:  map[string]int{"size": 2},
+	}
+
+	data, err := MarshalDynamoJSON(want)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	const wantJSON = `{"Active":{"BOOL":true},"Attrs":{"M":{"size":{"N":"2"}}},"Count":{"N":"3"},"Data":{"B":"aGVsbG8="},"Empty":{"NULL":true},"ID":{"S":"abc"},"Items":{"L":[{"M":{"Color":{"S":"red"}}}]},"Tags":{"SS":["a"]}}`
+	if got := string(data); got != wantJSON {
+		t.Fatalf("got %v; want %v", got, wantJSON)
+	}
+
+	var got Sample
+	if err := UnmarshalDynamoJSON(data, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v; want %#v", got, want)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if err := UnmarshalDynamoJSON([]byte(`[`), &got); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func TestRawAttribute(t *testing.T) {
+	var (
+		mock   = &Mock{getItem: Example{ID: "abc", Name: "name"}}
+		db     = New(mock)
+		source = db.MustTable("source", Example{})
+		target = db.MustTable("target", Example{})
+	)
+
+	var item map[string]RawAttribute
+	if err := source.Get("abc").Scan(&item); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := target.Put(item).Run(); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want, err := marshalMap(Example{ID: "abc", Name: "name"})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got := mock.putInput.Item; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		type Envelope struct {
+			ID    string
+			Value RawAttribute
+		}
+
+		data, err := json.Marshal(Envelope{ID: "abc", Value: item["Name"]})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := string(data), `{"ID":"abc","Value":{"S":"name"}}`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		var got Envelope
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got.Value, item["Name"]) {
+			t.Fatalf("got %v; want %v", got.Value, item["Name"])
+		}
+	})
+}
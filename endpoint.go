@@ -0,0 +1,78 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// reRegionalEndpoint matches the host of a regional DynamoDB endpoint
+var reRegionalEndpoint = regexp.MustCompile(`^dynamodb(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// WithEndpoint returns a copy of the table whose requests are sent to the scheme and
+// host of endpoint e.g. DynamoDB Local or another region during a migration.  When
+// endpoint is a regional DynamoDB endpoint, requests are signed for that region.
+//
+// The copy receives its own singleflight group and query cache, with the same ttl, when
+// the table has them so results from one endpoint are never returned by the other.
+// Transactions are sent by the DDB and so continue to use its endpoint.
+func (t *Table) WithEndpoint(endpoint string) *Table {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		panic(fmt.Errorf("WithEndpoint requires a valid url: %v", err))
+	}
+	if u.Scheme == "" || u.Host == "" {
+		panic(fmt.Errorf("WithEndpoint requires a scheme and host: got %v", endpoint))
+	}
+
+	db := t.ddb.clone()
+	db.api = newRequestOptionsAPI(db.api, []request.Option{endpointOption(u)})
+
+	dup := t.clone()
+	dup.ddb = db
+	if t.flight != nil {
+		dup.flight = newFlightGroup()
+	}
+	if t.queryCache != nil {
+		dup.queryCache = newQueryCache(t.queryCache.ttl, db.clock.Now)
+	}
+	return dup
+}
+
+// endpointOption returns a request option that sends the request to the scheme and host
+// of u, signing it for the region of u when u is a regional DynamoDB endpoint
+func endpointOption(u *url.URL) request.Option {
+	var region string
+	if match := reRegionalEndpoint.FindStringSubmatch(u.Hostname()); match != nil {
+		region = match[1]
+	}
+
+	return func(r *request.Request) {
+		r.ClientInfo.Endpoint = u.Scheme + "://" + u.Host
+		if r.HTTPRequest != nil && r.HTTPRequest.URL != nil {
+			r.HTTPRequest.URL.Scheme = u.Scheme
+			r.HTTPRequest.URL.Host = u.Host
+		}
+		if region != "" {
+			r.ClientInfo.SigningRegion = region
+			r.Config.Region = aws.String(region)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestTable_WithEndpoint(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		targets []string
+		server  = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			targets = append(targets, req.Header.Get("X-Amz-Target"))
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			_, _ = w.Write([]byte(`{"Item":{"ID":{"S":"abc"},"Name":{"S":"local"}}}`))
+		}))
+	)
+	defer server.Close()
+
+	s, err := session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("blah", "blah", "")).
+		WithRegion("us-west-2").
+		WithEndpoint("http://127.0.0.1:1").
+		WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	table := New(dynamodb.New(s)).MustTable("example", Example{}).WithEndpoint(server.URL)
+
+	var got Example
+	if err := table.Get("abc").ScanWithContext(ctx, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := (Example{ID: "abc", Name: "local"}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(targets), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := targets[0], "DynamoDB_20120810.GetItem"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("singleflight and query cache", func(t *testing.T) {
+		source := New(nil).MustTable("example", Example{}).WithSingleflight().WithQueryCache(time.Minute)
+		dup := source.WithEndpoint(server.URL)
+		if dup.flight == nil || dup.flight == source.flight {
+			t.Fatalf("got %p; want new singleflight group", dup.flight)
+		}
+		if dup.queryCache == nil || dup.queryCache == source.queryCache {
+			t.Fatalf("got %p; want new query cache", dup.queryCache)
+		}
+		if got, want := dup.queryCache.ttl, time.Minute; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatalf("got nil; want panic")
+			}
+		}()
+		table.WithEndpoint("localhost:8000")
+	})
+}
+
+func TestEndpointOption(t *testing.T) {
+	s, err := session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("blah", "blah", "")).
+		WithRegion("us-west-2"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	testCases := map[string]struct {
+		Endpoint string
+		Host     string
+		Region   string
+	}{
+		"local": {
+			Endpoint: "http://localhost:8000",
+			Host:     "localhost:8000",
+			Region:   "us-west-2",
+		},
+		"regional": {
+			Endpoint: "https://dynamodb.eu-west-1.amazonaws.com",
+			Host:     "dynamodb.eu-west-1.amazonaws.com",
+			Region:   "eu-west-1",
+		},
+	}
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			u, err := url.Parse(tc.Endpoint)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+
+			req, _ := dynamodb.New(s).GetItemRequest(&dynamodb.GetItemInput{})
+			req.ApplyOptions(endpointOption(u))
+			if got, want := req.HTTPRequest.URL.Host, tc.Host; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := req.ClientInfo.SigningRegion, tc.Region; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
@@ -16,16 +16,33 @@ package ddb
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 const (
+	ErrCircuitOpen          = "CircuitOpen"
+	ErrConditionFailed      = "ConditionFailed"
 	ErrInvalidFieldName     = "InvalidFieldName"
+	ErrInvalidFilter        = "InvalidFilter"
+	ErrInvalidTenant        = "InvalidTenant"
 	ErrItemNotFound         = "ItemNotFound"
 	ErrMismatchedValueCount = "MismatchedValueCount"
+	ErrMissingKey           = "MissingKey"
+	ErrMissingTenant        = "MissingTenant"
+	ErrOverlappingPaths     = "OverlappingPaths"
+	ErrThrottled            = "Throttled"
+	ErrUndefinedCondition   = "UndefinedCondition"
 	ErrUnableToMarshalItem  = "UnableToMarshalItem"
+	ErrUnauthorized         = "Unauthorized"
+	ErrUnknownAttribute     = "UnknownAttribute"
+	ErrUnreachable          = "Unreachable"
+	ErrValidation           = "Validation"
 )
 
 // Error provides a unified error definition that includes a code and message
@@ -47,10 +64,84 @@ type coder interface {
 	Code() string
 }
 
+// errorCoder is implemented by the api errors of aws-sdk-go-v2 (smithy.APIError)
+type errorCoder interface {
+	ErrorCode() string
+}
+
 type wrapper interface {
 	Unwrap() error
 }
 
+// origErrer is implemented by awserr.Error, which exposes its cause via OrigErr
+type origErrer interface {
+	OrigErr() error
+}
+
+// errorClass identifies a family of related errors
+type errorClass int
+
+const (
+	classAccessDenied errorClass = iota
+	classConditionFailed
+	classInternalServerError
+	classItemCollectionSizeLimitExceeded
+	classRequestLimitExceeded
+	classThrottling
+	classUnauthorized
+)
+
+// errorCodes maps each class of error to the ddb and aws error codes it includes.  All
+// classification of errors by code, whether by the Is* helpers, retries, contention
+// tracking, the circuit breaker, or Ping, is derived from this table.  Codes are
+// matched for both aws-sdk-go and aws-sdk-go-v2 errors.
+var errorCodes = map[errorClass][]string{
+	classAccessDenied: {
+		"AccessDeniedException",
+	},
+	classConditionFailed: {
+		ErrConditionFailed,
+		dynamodb.ErrCodeConditionalCheckFailedException,
+	},
+	classInternalServerError: {
+		dynamodb.ErrCodeInternalServerError,
+		"ServiceUnavailable",
+	},
+	classItemCollectionSizeLimitExceeded: {
+		dynamodb.ErrCodeItemCollectionSizeLimitExceededException,
+	},
+	classRequestLimitExceeded: {
+		dynamodb.ErrCodeRequestLimitExceeded,
+	},
+	classThrottling: {
+		ErrThrottled,
+		dynamodb.ErrCodeProvisionedThroughputExceededException,
+		dynamodb.ErrCodeRequestLimitExceeded,
+		"ThrottlingException",
+	},
+	classUnauthorized: {
+		ErrUnauthorized,
+		"AccessDeniedException",
+		"ExpiredTokenException",
+		"IncompleteSignature",
+		"InvalidClientTokenId",
+		"InvalidSignatureException",
+		"MissingAuthenticationToken",
+		"NoCredentialProviders",
+		"UnrecognizedClientException",
+	},
+}
+
+// isErrorClass returns true if any error in the cause chain has a code of the class
+func isErrorClass(err error, class errorClass) bool {
+	for _, code := range errorCodes[class] {
+		if hasError(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
 func hasError(err error, code string) bool {
 	if err == nil {
 		return false
@@ -60,6 +151,10 @@ func hasError(err error, code string) bool {
 		return true
 	}
 
+	if v, ok := err.(errorCoder); ok && v.ErrorCode() == code {
+		return true
+	}
+
 	if item, ok := err.(causer); ok {
 		return hasError(item.Cause(), code)
 	}
@@ -68,9 +163,19 @@ func hasError(err error, code string) bool {
 		return hasError(item.Unwrap(), code)
 	}
 
+	if item, ok := err.(origErrer); ok {
+		return hasError(item.OrigErr(), code)
+	}
+
 	return false
 }
 
+// IsConditionFailedError returns true if any error in the cause chain contains the code,
+// ErrConditionFailed, or is a ConditionalCheckFailedException
+func IsConditionFailedError(err error) bool {
+	return isErrorClass(err, classConditionFailed)
+}
+
 // IsItemNotFoundError returns true if any error in the cause change contains the code, ErrItemNotFound
 func IsItemNotFoundError(err error) bool {
 	return hasError(err, ErrItemNotFound)
@@ -84,6 +189,96 @@ func IsInvalidFieldNameError(err error) bool {
 	return hasError(err, ErrInvalidFieldName)
 }
 
+// IsInvalidFilterError returns true if any error in the cause chain contains the code, ErrInvalidFilter
+func IsInvalidFilterError(err error) bool {
+	return hasError(err, ErrInvalidFilter)
+}
+
+// IsCircuitOpenError returns true if any error in the cause chain contains the code, ErrCircuitOpen
+func IsCircuitOpenError(err error) bool {
+	return hasError(err, ErrCircuitOpen)
+}
+
+// IsMissingKeyError returns true if any error in the cause chain contains the code, ErrMissingKey
+func IsMissingKeyError(err error) bool {
+	return hasError(err, ErrMissingKey)
+}
+
+// IsUndefinedConditionError returns true if any error in the cause chain contains the code, ErrUndefinedCondition
+func IsUndefinedConditionError(err error) bool {
+	return hasError(err, ErrUndefinedCondition)
+}
+
+// IsInvalidTenantError returns true if any error in the cause chain contains the code, ErrInvalidTenant
+func IsInvalidTenantError(err error) bool {
+	return hasError(err, ErrInvalidTenant)
+}
+
+// IsMissingTenantError returns true if any error in the cause chain contains the code, ErrMissingTenant
+func IsMissingTenantError(err error) bool {
+	return hasError(err, ErrMissingTenant)
+}
+
+// IsThrottledError returns true if any error in the cause chain contains the code, ErrThrottled
+//
+// Deprecated: use IsThrottlingError, which also recognizes the throttling codes of aws.
+func IsThrottledError(err error) bool {
+	return hasError(err, ErrThrottled)
+}
+
+// IsAccessDeniedError returns true if the caller lacks permission for the request
+func IsAccessDeniedError(err error) bool {
+	return isErrorClass(err, classAccessDenied)
+}
+
+// IsInternalServerError returns true if DynamoDB failed to process the request
+func IsInternalServerError(err error) bool {
+	return isErrorClass(err, classInternalServerError)
+}
+
+// IsItemCollectionSizeLimitExceededError returns true if a write would grow an item
+// collection of a table with a local secondary index beyond 10GB
+func IsItemCollectionSizeLimitExceededError(err error) bool {
+	return isErrorClass(err, classItemCollectionSizeLimitExceeded)
+}
+
+// IsRequestLimitExceededError returns true if the account request limit was exceeded
+func IsRequestLimitExceededError(err error) bool {
+	return isErrorClass(err, classRequestLimitExceeded)
+}
+
+// IsOverlappingPathsError returns true if any error in the cause chain contains the code, ErrOverlappingPaths
+func IsOverlappingPathsError(err error) bool {
+	return hasError(err, ErrOverlappingPaths)
+}
+
+// IsThrottlingError returns true if the request was throttled, whether reported by
+// DynamoDB or by ddb, with the code ErrThrottled, once retries were exhausted
+func IsThrottlingError(err error) bool {
+	return isErrorClass(err, classThrottling)
+}
+
+// IsUnknownAttributeError returns true if any error in the cause chain contains the code, ErrUnknownAttribute
+func IsUnknownAttributeError(err error) bool {
+	return hasError(err, ErrUnknownAttribute)
+}
+
+// IsUnauthorizedError returns true if the request was not authenticated or authorized,
+// whether reported by DynamoDB, the aws sdk, or Ping with the code, ErrUnauthorized
+func IsUnauthorizedError(err error) bool {
+	return isErrorClass(err, classUnauthorized)
+}
+
+// IsUnreachableError returns true if any error in the cause chain contains the code, ErrUnreachable
+func IsUnreachableError(err error) bool {
+	return hasError(err, ErrUnreachable)
+}
+
+// IsValidationError returns true if any error in the cause chain contains the code, ErrValidation
+func IsValidationError(err error) bool {
+	return hasError(err, ErrValidation)
+}
+
 type baseError struct {
 	code      string
 	message   string
@@ -126,6 +321,173 @@ func (b *baseError) Unwrap() error {
 	return b.cause
 }
 
+// FieldError describes a single field that failed validation
+type FieldError struct {
+	Field   string // Field holds the name of the struct field
+	Tag     string // Tag holds the validation rule that failed, if known
+	Message string // Message holds the human readable description
+}
+
+// ValidationError is returned by Put and Update when the configured Validator
+// rejects a value.  Use errors.As to access the field details.
+type ValidationError struct {
+	*baseError
+	Fields []FieldError
+}
+
+// MissingItem identifies an item requested by TransactGetItems that was not found
+type MissingItem struct {
+	Index     int                      // Index of the GetTx within the transaction
+	TableName string                   // TableName of the requested item
+	HashKey   *dynamodb.AttributeValue // HashKey of the requested item
+	RangeKey  *dynamodb.AttributeValue // RangeKey of the requested item, if any
+}
+
+// TransactGetError is returned by TransactGetItems when one or more items were not
+// found.  The items that were found are still decoded.  As the code is ErrItemNotFound,
+// IsItemNotFoundError returns true; use errors.As to identify the missing items.
+type TransactGetError struct {
+	*baseError
+	Missing []MissingItem
+}
+
+func newTransactGetError(total int, missing []MissingItem) *TransactGetError {
+	var items []string
+	for _, item := range missing {
+		key := keyToString(item.HashKey)
+		if item.RangeKey != nil {
+			key += "#" + keyToString(item.RangeKey)
+		}
+		items = append(items, fmt.Sprintf("[%v] %v in table, %v", item.Index, key, item.TableName))
+	}
+
+	return &TransactGetError{
+		baseError: &baseError{
+			code:    ErrItemNotFound,
+			message: fmt.Sprintf("%v of %v items not found: %v", len(missing), total, strings.Join(items, ", ")),
+		},
+		Missing: missing,
+	}
+}
+
+// ResumeError is returned by Query.Each and Scan.Each when iteration is interrupted
+// after some pages were processed.  Every item prior to ResumeKey has been passed to
+// the callback; pass ResumeToken to StartToken to continue from the page that failed.
+type ResumeError struct {
+	*baseError
+	Segment     int64                               // Segment of a parallel scan that failed; 0 for Query
+	ResumeKey   map[string]*dynamodb.AttributeValue // ResumeKey holds the start key of the page that failed; nil for the first page
+	ResumeToken string                              // ResumeToken holds ResumeKey as a base64 encoded string
+}
+
+func newResumeError(cause error, code, tableName string, segment int64, resumeKey map[string]*dynamodb.AttributeValue) error {
+	token, err := encodeStartToken(resumeKey)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("iteration of table, %v, interrupted", tableName)
+	if resumeKey != nil {
+		message += " after " + FormatItem(resumeKey)
+	}
+
+	return &ResumeError{
+		baseError: &baseError{
+			code:      code,
+			message:   message,
+			cause:     cause,
+			tableName: tableName,
+		},
+		Segment:     segment,
+		ResumeKey:   resumeKey,
+		ResumeToken: token,
+	}
+}
+
+// OperationError wraps an error returned by aws with the operation, table, index, and,
+// where available, the key of the request that failed.  The code and message are those
+// of the aws error and OperationError implements awserr.Error, so existing checks of
+// the aws code continue to work; use errors.As to access the underlying typed error
+// e.g. *dynamodb.TransactionCanceledException.
+type OperationError struct {
+	*baseError
+	Operation string // Operation holds the DynamoDB operation e.g. PutItem or Query
+	IndexName string // IndexName holds the index queried or scanned, if any
+	Item      int    // Item holds the index of the failed item within a transaction; -1 otherwise
+}
+
+func (e *OperationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.code)
+	sb.WriteString(": ")
+	sb.WriteString(e.Operation)
+	if e.Item >= 0 {
+		fmt.Fprintf(&sb, " item [%v]", e.Item)
+	}
+	if e.tableName != "" {
+		sb.WriteString(" on table, ")
+		sb.WriteString(e.tableName)
+	}
+	if e.IndexName != "" {
+		sb.WriteString(", index, ")
+		sb.WriteString(e.IndexName)
+	}
+	if e.hashKey != nil {
+		sb.WriteString(", key, ")
+		sb.WriteString(keyToString(e.hashKey))
+		if e.rangeKey != nil {
+			sb.WriteString("#")
+			sb.WriteString(keyToString(e.rangeKey))
+		}
+	}
+	sb.WriteString(" failed")
+	if e.message != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.message)
+	}
+	return sb.String()
+}
+
+// OrigErr implements awserr.Error
+func (e *OperationError) OrigErr() error {
+	return e.cause
+}
+
+// wrapAWSError wraps err, if an aws error, in an *OperationError describing the request
+// that failed.  spec and key are optional.  Errors already wrapped are returned as is.
+func wrapAWSError(err error, operation string, spec *tableSpec, indexName string, key map[string]*dynamodb.AttributeValue) error {
+	if err == nil {
+		return nil
+	}
+
+	var oe *OperationError
+	if errors.As(err, &oe) {
+		return err
+	}
+	var ae awserr.Error
+	if !errors.As(err, &ae) {
+		return err
+	}
+
+	e := &OperationError{
+		baseError: &baseError{
+			code:    ae.Code(),
+			message: ae.Message(),
+			cause:   err,
+		},
+		Operation: operation,
+		IndexName: indexName,
+		Item:      -1,
+	}
+	if spec != nil {
+		e.tableName = spec.TableName
+		if key != nil && spec.HashKey != nil {
+			e.hashKey, e.rangeKey, _ = getMetadata(key, spec)
+		}
+	}
+	return e
+}
+
 func errorf(code, message string, args ...interface{}) Error {
 	return &baseError{
 		code:    code,
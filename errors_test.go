@@ -15,8 +15,15 @@
 package ddb
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func TestIsItemNotFoundError(t *testing.T) {
@@ -88,3 +95,174 @@ func TestIsInvalidFieldNameError(t *testing.T) {
 		t.Fatalf("got false; want true")
 	}
 }
+
+func TestErrorClasses(t *testing.T) {
+	testCases := map[string]struct {
+		Is   func(err error) bool
+		Err  error
+		Want bool
+	}{
+		"access denied": {
+			Is:   IsAccessDeniedError,
+			Err:  awserr.New("AccessDeniedException", "denied", nil),
+			Want: true,
+		},
+		"internal server error": {
+			Is:   IsInternalServerError,
+			Err:  awserr.New(dynamodb.ErrCodeInternalServerError, "oops", nil),
+			Want: true,
+		},
+		"item collection size": {
+			Is:   IsItemCollectionSizeLimitExceededError,
+			Err:  awserr.New(dynamodb.ErrCodeItemCollectionSizeLimitExceededException, "full", nil),
+			Want: true,
+		},
+		"request limit": {
+			Is:   IsRequestLimitExceededError,
+			Err:  awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "slow down", nil),
+			Want: true,
+		},
+		"throttling": {
+			Is:   IsThrottlingError,
+			Err:  awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil),
+			Want: true,
+		},
+		"throttling via ddb code": {
+			Is:   IsThrottlingError,
+			Err:  errorf(ErrThrottled, "throttled"),
+			Want: true,
+		},
+		"throttling wrapped": {
+			Is:   IsThrottlingError,
+			Err:  fmt.Errorf("page failed: %w", awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "", nil)),
+			Want: true,
+		},
+		"throttling as cause of aws error": {
+			Is:   IsThrottlingError,
+			Err:  awserr.New(request.ErrCodeRequestError, "failed", awserr.New("ThrottlingException", "", nil)),
+			Want: true,
+		},
+		"throttling via sdk v2": {
+			Is:   IsThrottlingError,
+			Err:  fmt.Errorf("operation error: %w", apiError("ThrottlingException")),
+			Want: true,
+		},
+		"condition failed": {
+			Is:   IsConditionFailedError,
+			Err:  awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "failed", nil),
+			Want: true,
+		},
+		"condition failed via ddb code": {
+			Is:   IsConditionFailedError,
+			Err:  errorf(ErrConditionFailed, "failed"),
+			Want: true,
+		},
+		"condition failed via sdk v2": {
+			Is:   IsConditionFailedError,
+			Err:  apiError(dynamodb.ErrCodeConditionalCheckFailedException),
+			Want: true,
+		},
+		"unauthorized": {
+			Is:   IsUnauthorizedError,
+			Err:  awserr.New("UnrecognizedClientException", "bad token", nil),
+			Want: true,
+		},
+		"mismatched": {
+			Is:  IsAccessDeniedError,
+			Err: awserr.New(dynamodb.ErrCodeInternalServerError, "oops", nil),
+		},
+		"nil": {
+			Is: IsThrottlingError,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got, want := tc.Is(tc.Err), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+// apiError mimics the api errors of aws-sdk-go-v2, which report their code via ErrorCode
+type apiError string
+
+func (a apiError) Error() string     { return "api error " + string(a) }
+func (a apiError) ErrorCode() string { return string(a) }
+
+// cancelMock cancels each transaction, failing the condition of the item at index
+type cancelMock struct {
+	*Mock
+	index int
+}
+
+func (c *cancelMock) TransactWriteItemsWithContext(aws.Context, *dynamodb.TransactWriteItemsInput, ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	var reasons []*dynamodb.CancellationReason
+	for i := 0; i <= c.index; i++ {
+		code := "None"
+		if i == c.index {
+			code = "ConditionalCheckFailed"
+		}
+		reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String(code)})
+	}
+	return nil, &dynamodb.TransactionCanceledException{
+		Message_:            aws.String("transaction canceled"),
+		CancellationReasons: reasons,
+	}
+}
+
+func TestOperationError(t *testing.T) {
+	t.Run("transaction", func(t *testing.T) {
+		var (
+			db     = New(&cancelMock{Mock: &Mock{}, index: 1})
+			first  = db.MustTable("first", Example{})
+			second = db.MustTable("second", QueryExample{})
+		)
+
+		_, err := db.TransactWriteItems(first.Put(Example{ID: "abc"}), second.Delete("def").Range("2020"))
+		var oe *OperationError
+		if !errors.As(err, &oe) {
+			t.Fatalf("got %T; want *OperationError", err)
+		}
+		if got, want := oe.Item, 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := oe.TableName(), "second"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := oe.Error(), "TransactionCanceledException: TransactWriteItems item [1] on table, second, key, def#2020 failed: transaction canceled"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		var tce *dynamodb.TransactionCanceledException
+		if !errors.As(err, &tce) {
+			t.Fatalf("got %T; want *dynamodb.TransactionCanceledException", err)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var (
+			mock  = &Mock{err: awserr.New(dynamodb.ErrCodeResourceNotFoundException, "no index", nil)}
+			table = New(mock).MustTable("example", Example{})
+		)
+
+		err := table.Query("#Name = ?", "abc").IndexName("name").Each(func(Item) (bool, error) { return true, nil })
+		var oe *OperationError
+		if !errors.As(err, &oe) {
+			t.Fatalf("got %T; want *OperationError", err)
+		}
+		if got, want := oe.IndexName, "name"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := oe.Code(), dynamodb.ErrCodeResourceNotFoundException; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("other errors unchanged", func(t *testing.T) {
+		if got := wrapAWSError(io.EOF, "GetItem", nil, "", nil); got != io.EOF {
+			t.Fatalf("got %v; want %v", got, io.EOF)
+		}
+	})
+}
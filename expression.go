@@ -15,20 +15,39 @@
 package ddb
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
+// nameResolution determines how #names within expressions are matched to attributes
+type nameResolution struct {
+	caseInsensitive bool // caseInsensitive matches names regardless of case
+	strict          bool // strict rejects names that match no attribute
+}
+
+// expression accumulates the names, values, and clauses of a request.  Mutations are
+// serialized so clauses may be added from multiple goroutines, however the
+// expression must not be modified once the request is being executed.
 type expression struct {
+	mutex      sync.Mutex // mutex serializes changes to Names, Values, and the clauses
 	attributes []*attributeSpec
+	encoder    encoder
+	validate   func(v interface{}) error // validate, if set, is applied to struct values
+	names      nameResolution            // names determines how #names are matched to attributes
 	Names      map[string]*string
 	Values     map[string]*dynamodb.AttributeValue
 	index      int64
+	namesHint  int // namesHint sizes Names when allocated
+	valuesHint int // valuesHint sizes Values when allocated
 
 	Adds       *strings.Builder
 	Conditions *strings.Builder
@@ -44,9 +63,32 @@ func newExpression(attributes ...*attributeSpec) *expression {
 	}
 }
 
+// namePlaceholders and valuePlaceholders cache the most commonly used placeholders
+// to avoid formatting them on each use
+var (
+	namePlaceholders  = makePlaceholders("#n", 32)
+	valuePlaceholders = makePlaceholders(":v", 32)
+)
+
+func makePlaceholders(prefix string, n int) []string {
+	placeholders := make([]string, n+1)
+	for i := 1; i <= n; i++ {
+		placeholders[i] = prefix + strconv.Itoa(i)
+	}
+	return placeholders
+}
+
+// placeholder returns the i-th placeholder with the given prefix e.g. #n1
+func placeholder(cache []string, prefix string, i int) string {
+	if i > 0 && i < len(cache) {
+		return cache[i]
+	}
+	return prefix + strconv.Itoa(i)
+}
+
 func (e *expression) addExpressionAttributeName(name string) string {
 	if e.Names == nil {
-		e.Names = map[string]*string{}
+		e.Names = make(map[string]*string, e.namesHint)
 	}
 
 	// use existing attribute name where possible
@@ -56,26 +98,50 @@ func (e *expression) addExpressionAttributeName(name string) string {
 		}
 	}
 
-	key := "#n" + strconv.Itoa(len(e.Names)+1)
+	key := placeholder(namePlaceholders, "#n", len(e.Names)+1)
+	if attr := e.lookupAttribute(name); attr != nil {
+		e.Names[key] = aws.String(attr.AttributeName)
+		return key
+	}
+
+	e.Names[key] = aws.String(name)
+	return key
+}
+
+// lookupAttribute returns the attribute whose field or attribute name matches name
+func (e *expression) lookupAttribute(name string) *attributeSpec {
 	for _, attr := range e.attributes {
 		switch name {
 		case attr.AttributeName, attr.FieldName:
-			e.Names[key] = aws.String(attr.AttributeName)
-			return key
+			return attr
 		}
 	}
+	if e.names.caseInsensitive {
+		for _, attr := range e.attributes {
+			if strings.EqualFold(name, attr.AttributeName) || strings.EqualFold(name, attr.FieldName) {
+				return attr
+			}
+		}
+	}
+	return nil
+}
 
-	e.Names[key] = aws.String(name)
-	return key
+// checkAttributeName ensures name refers to an attribute when strict name resolution
+// is enabled
+func (e *expression) checkAttributeName(name string) error {
+	if !e.names.strict || len(e.attributes) == 0 || e.lookupAttribute(name) != nil {
+		return nil
+	}
+	return unknownAttribute(name, e.attributes)
 }
 
 func (e *expression) addExpressionAttributeValue(item *dynamodb.AttributeValue) string {
 	if e.Values == nil {
-		e.Values = map[string]*dynamodb.AttributeValue{}
+		e.Values = make(map[string]*dynamodb.AttributeValue, e.valuesHint)
 	}
 
 	id := atomic.AddInt64(&e.index, 1)
-	name := ":v" + strconv.FormatInt(id, 10)
+	name := placeholder(valuePlaceholders, ":v", int(id))
 	e.Values[name] = item
 
 	return name
@@ -143,89 +209,147 @@ func (e *expression) FilterExpression() *string {
 	return aws.String(e.Filters.String())
 }
 
-func (e *expression) append(buf *strings.Builder, keyword, separator, expr string, values ...interface{}) error {
+func (e *expression) append(buf **strings.Builder, keyword, separator, expr string, values ...interface{}) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
 	expr, err := e.parse(expr, values...)
 	if err != nil {
 		return err
 	}
 
+	if *buf == nil {
+		*buf = &strings.Builder{}
+		(*buf).Grow(128)
+	}
+
 	// expr
 	//
-	if buf.Len() == 0 {
+	b := *buf
+	if b.Len() == 0 {
 		if len(keyword) > 0 {
-			buf.WriteString(keyword)
-			buf.WriteString(" ")
+			b.WriteString(keyword)
+			b.WriteString(" ")
 		}
 	} else {
-		buf.WriteString(separator)
+		b.WriteString(separator)
 	}
-	buf.WriteString(strings.TrimSpace(expr))
+	b.WriteString(strings.TrimSpace(expr))
 
 	return nil
 }
 
 const comma = ", "
 
-func (e *expression) Add(expr string, values ...interface{}) error {
-	if e.Adds == nil {
-		e.Adds = &strings.Builder{}
-		e.Adds.Grow(128)
+// Projection parses a projection expression e.g. #A, #B.  As projections may only
+// reference names, values are not permitted.
+func (e *expression) Projection(expr string, values ...interface{}) (string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	n := len(e.Values)
+	s, err := e.parse(expr, values...)
+	if err != nil {
+		return "", err
 	}
-	return e.append(e.Adds, "Add", comma, expr, values...)
+	if len(e.Values) != n {
+		return "", fmt.Errorf("projection expressions may not contain values: %v", expr)
+	}
+
+	return strings.TrimSpace(s), nil
+}
+
+func (e *expression) Add(expr string, values ...interface{}) error {
+	return e.append(&e.Adds, "Add", comma, expr, values...)
 }
 
 func (e *expression) Condition(expr string, values ...interface{}) error {
-	if e.Conditions == nil {
-		e.Conditions = &strings.Builder{}
-		e.Conditions.Grow(128)
-	}
-	return e.append(e.Conditions, "", " and ", expr, values...)
+	return e.append(&e.Conditions, "", " and ", expr, values...)
 }
 
 func (e *expression) Delete(expr string, values ...interface{}) error {
-	if e.Deletes == nil {
-		e.Deletes = &strings.Builder{}
-		e.Deletes.Grow(128)
-	}
-	return e.append(e.Deletes, "Delete", comma, expr, values...)
+	return e.append(&e.Deletes, "Delete", comma, expr, values...)
 }
 
 func (e *expression) Filter(expr string, values ...interface{}) error {
-	if e.Filters == nil {
-		e.Filters = &strings.Builder{}
-		e.Filters.Grow(128)
-	}
-	return e.append(e.Filters, "", " and ", expr, values...)
+	return e.append(&e.Filters, "", " and ", expr, values...)
 }
 
 func (e *expression) Remove(expr string, values ...interface{}) error {
-	if e.Removes == nil {
-		e.Removes = &strings.Builder{}
-		e.Removes.Grow(128)
-	}
-	return e.append(e.Removes, "Remove", comma, expr, values...)
+	return e.append(&e.Removes, "Remove", comma, expr, values...)
 }
 
 func (e *expression) Set(expr string, values ...interface{}) error {
-	if e.Sets == nil {
-		e.Sets = &strings.Builder{}
-		e.Sets.Grow(128)
+	return e.append(&e.Sets, "Set", comma, expr, values...)
+}
+
+// namePool holds buffers used to accumulate #names while parsing
+var namePool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// reserve records the number of placeholders in expr so Names and Values, when
+// first allocated, are sized appropriately
+func (e *expression) reserve(expr string) {
+	if e.Names == nil {
+		e.namesHint = strings.Count(expr, "#")
+	}
+	if e.Values == nil {
+		e.valuesHint = strings.Count(expr, "?")
 	}
-	return e.append(e.Sets, "Set", comma, expr, values...)
 }
 
+// parse binds the names and values of expr.  #Name and #? refer to attribute names,
+// ? to values, and quoted strings e.g. 'what?' are bound as string values.  Within
+// quotes, and elsewhere, a backslash escapes the rune that follows e.g. \? is a
+// literal question mark.
 func (e *expression) parse(expr string, values ...interface{}) (string, error) {
 	var (
 		inName  bool
+		nested  bool // nested is true when the current name follows a . within a path
+		escaped bool // escaped is true when the previous rune was a backslash
+		quote   rune // quote holds the rune that opened the current string literal, if any
+		literal strings.Builder
 		index   int
 		buf     = &strings.Builder{}
-		bufName = &strings.Builder{}
+		bufName = namePool.Get().(*bytes.Buffer)
 	)
+	bufName.Reset()
+	defer namePool.Put(bufName)
+
+	e.reserve(expr)
+	buf.Grow(len(expr) + len(expr)/2)
+	for i, v := range expr {
+		if quote != 0 {
+			switch {
+			case escaped:
+				literal.WriteRune(v)
+				escaped = false
+			case v == '\\':
+				escaped = true
+			case v == quote:
+				item, err := e.encoder.marshal(literal.String())
+				if err != nil {
+					return "", fmt.Errorf("unable to marshal value: %v", err)
+				}
+				buf.WriteString(e.addExpressionAttributeValue(item))
+				quote = 0
+			default:
+				literal.WriteRune(v)
+			}
+			continue
+		}
+
+		if escaped {
+			buf.WriteRune(v)
+			escaped = false
+			continue
+		}
 
-	buf.Grow(len(expr) * 2)
-	for _, v := range expr {
 		if inName {
-			if isAlphaNumeric(v) {
+			if isNameRune(v) {
 				bufName.WriteRune(v)
 				continue
 
@@ -248,6 +372,11 @@ func (e *expression) parse(expr string, values ...interface{}) (string, error) {
 				continue
 
 			} else {
+				if !nested {
+					if err := e.checkAttributeName(bufName.String()); err != nil {
+						return "", err
+					}
+				}
 				key := e.addExpressionAttributeName(bufName.String())
 				buf.WriteString(key)
 				inName = false
@@ -261,7 +390,13 @@ func (e *expression) parse(expr string, values ...interface{}) (string, error) {
 				return "", errorf(ErrMismatchedValueCount, "not enough values")
 			}
 
-			item, err := marshal(values[index])
+			if e.validate != nil && isStructValue(values[index]) {
+				if err := e.validate(values[index]); err != nil {
+					return "", err
+				}
+			}
+
+			item, err := e.encoder.marshal(values[index])
 			if err != nil {
 				return "", fmt.Errorf("unable to marshal value: %v", err)
 			}
@@ -272,14 +407,34 @@ func (e *expression) parse(expr string, values ...interface{}) (string, error) {
 
 		case '#':
 			inName = true
+			nested = i > 0 && expr[i-1] == '.'
 			bufName.Reset()
 
+		case '\\':
+			escaped = true
+
+		case '\'', '"':
+			quote = v
+			literal.Reset()
+
 		default:
 			buf.WriteRune(v)
 		}
 	}
 
+	if quote != 0 {
+		return "", fmt.Errorf("unterminated string literal in expression: %v", expr)
+	}
+	if escaped {
+		buf.WriteRune('\\')
+	}
+
 	if bufName.Len() > 0 {
+		if !nested {
+			if err := e.checkAttributeName(bufName.String()); err != nil {
+				return "", err
+			}
+		}
 		key := e.addExpressionAttributeName(bufName.String())
 		buf.WriteString(key)
 	}
@@ -288,9 +443,182 @@ func (e *expression) parse(expr string, values ...interface{}) (string, error) {
 		return "", fmt.Errorf("mismatched number of values; got %v, want %v", got, want)
 	}
 
-	return buf.String(), nil
+	return e.escapeReservedWords(buf.String()), nil
+}
+
+// attributeFunc returns an expression applying the DynamoDB function, fn, to the
+// attribute, name.  name may either be an expression name e.g. #Field or a raw
+// attribute name.
+func attributeFunc(fn, name string) (string, []interface{}) {
+	expr, values := expressionName(name)
+	return fn + "(" + expr + ")", values
+}
+
+// expressionName returns name as an expression name along with the values it binds.
+// name may either be an expression name e.g. #Field or a raw attribute name.
+func expressionName(name string) (string, []interface{}) {
+	if strings.HasPrefix(name, "#") {
+		return name, nil
+	}
+	return "#?", []interface{}{name}
 }
 
 func isAlphaNumeric(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
 }
+
+// referencedNames returns the subset of names whose placeholders appear in exprs or nil
+// if none do
+func referencedNames(names map[string]*string, exprs ...*string) map[string]*string {
+	var referenced map[string]*string
+	for _, expr := range exprs {
+		runes := []rune(aws.StringValue(expr))
+		for i := 0; i < len(runes); i++ {
+			if runes[i] != '#' {
+				continue
+			}
+			j := i + 1
+			for j < len(runes) && isNameRune(runes[j]) {
+				j++
+			}
+			placeholder := string(runes[i:j])
+			if name, ok := names[placeholder]; ok {
+				if referenced == nil {
+					referenced = map[string]*string{}
+				}
+				referenced[placeholder] = name
+			}
+			i = j - 1
+		}
+	}
+	return referenced
+}
+
+// isNameRune returns true if r may appear in a #name e.g. #first_name or #café
+func isNameRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isAlphaNumeric(r) || r == '_'
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitTopLevel splits s by sep, ignoring separators nested within parentheses
+func splitTopLevel(s string, sep rune) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// updatePaths returns the document paths targeted by the actions of an update clause
+// e.g. the clause, Set #n1 = :v1, #n2 = :v2, targets #n1 and #n2
+func updatePaths(clause *strings.Builder, keyword string) []string {
+	if clause == nil {
+		return nil
+	}
+
+	var paths []string
+	for _, action := range splitTopLevel(strings.TrimPrefix(clause.String(), keyword), ',') {
+		action = strings.TrimSpace(action)
+		switch keyword {
+		case "Set":
+			action = splitTopLevel(action, '=')[0]
+		case "Add", "Delete":
+			if fields := strings.Fields(action); len(fields) > 0 {
+				action = fields[0]
+			}
+		}
+		if path := strings.TrimSpace(action); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// resolvePath replaces the name placeholders of path with the names they reference
+func (e *expression) resolvePath(path string) string {
+	for placeholder, name := range e.Names {
+		path = replaceName(path, placeholder, aws.StringValue(name))
+	}
+	return path
+}
+
+// replaceName replaces each complete occurrence of placeholder within path
+func replaceName(path, placeholder, name string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(path, placeholder)
+		if i < 0 {
+			sb.WriteString(path)
+			return sb.String()
+		}
+		end := i + len(placeholder)
+		if r, _ := utf8.DecodeRuneInString(path[end:]); end < len(path) && isNameRune(r) {
+			sb.WriteString(path[:end])
+			path = path[end:]
+			continue
+		}
+		sb.WriteString(path[:i])
+		sb.WriteString(name)
+		path = path[end:]
+	}
+}
+
+// overlaps returns true if a and b refer to the same document or one contains the other
+func overlaps(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+".") || strings.HasPrefix(b, a+"[")
+}
+
+// checkOverlappingPaths ensures no document path is targeted by more than one of the
+// Set, Remove, Add, and Delete clauses.  DynamoDB rejects such updates with a
+// ValidationException that does not name the attribute.
+func (e *expression) checkOverlappingPaths() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	type target struct {
+		keyword string
+		path    string
+	}
+
+	var targets []target
+	for _, clause := range []struct {
+		keyword string
+		buf     *strings.Builder
+	}{
+		{keyword: "Set", buf: e.Sets},
+		{keyword: "Remove", buf: e.Removes},
+		{keyword: "Add", buf: e.Adds},
+		{keyword: "Delete", buf: e.Deletes},
+	} {
+		for _, path := range updatePaths(clause.buf, clause.keyword) {
+			resolved := e.resolvePath(path)
+			for _, t := range targets {
+				if t.keyword != clause.keyword && overlaps(t.path, resolved) {
+					return errorf(ErrOverlappingPaths, "attribute, %v, appears in both %v and %v", t.path, t.keyword, clause.keyword)
+				}
+			}
+			targets = append(targets, target{keyword: clause.keyword, path: resolved})
+		}
+	}
+
+	return nil
+}
@@ -15,6 +15,9 @@
 package ddb
 
 import (
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -60,6 +63,35 @@ func TestParse(t *testing.T) {
 			Want:     "#n1 = 1",
 			Filename: "testdata/parse/custom_name.json",
 		},
+		"underscore name": {
+			Expr:     "#first_name = 1",
+			Want:     "#n1 = 1",
+			Filename: "testdata/parse/underscore_name.json",
+		},
+		"size": {
+			Expr:     "size(#Attr) > ?",
+			Values:   []interface{}{3},
+			Want:     "size(#n1) > :v1",
+			Filename: "testdata/parse/size.json",
+		},
+		"size dynamic name": {
+			Expr:     "size(#?)>?",
+			Values:   []interface{}{"Attr", 3},
+			Want:     "size(#n1)>:v1",
+			Filename: "testdata/parse/size.json",
+		},
+		"attribute_type": {
+			Expr:     "attribute_type(#Attr, ?)",
+			Values:   []interface{}{"S"},
+			Want:     "attribute_type(#n1, :v1)",
+			Filename: "testdata/parse/attribute_type.json",
+		},
+		"attribute_type dynamic name": {
+			Expr:     "attribute_type(#?,?)",
+			Values:   []interface{}{"Attr", "S"},
+			Want:     "attribute_type(#n1,:v1)",
+			Filename: "testdata/parse/attribute_type.json",
+		},
 	}
 
 	for label, tc := range testCases {
@@ -109,6 +141,167 @@ func TestParse(t *testing.T) {
 	})
 }
 
+func TestParse_NamingStyles(t *testing.T) {
+	testCases := map[string]string{
+		"camel case":  "firstName",
+		"pascal case": "FirstName",
+		"snake case":  "first_name",
+		"upper snake": "FIRST_NAME",
+		"leading _":   "_version",
+		"digits":      "address2",
+		"accented":    "café",
+		"cjk":         "名前",
+		"cyrillic":    "имя",
+	}
+
+	for label, name := range testCases {
+		t.Run(label, func(t *testing.T) {
+			expr := newExpression()
+			got, err := expr.parse("#"+name+" = ? and attribute_exists(#"+name+".#"+name+")", "abc")
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if want := "#n1 = :v1 and attribute_exists(#n1.#n1)"; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := *expr.Names["#n1"], name; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParse_Literals(t *testing.T) {
+	testCases := map[string]struct {
+		Expr       string
+		Values     []interface{}
+		Want       string
+		WantValues map[string]string
+	}{
+		"single quotes": {
+			Expr:       "#Question = 'why?' and #Answer = ?",
+			Values:     []interface{}{"because"},
+			Want:       "#n1 = :v1 and #n2 = :v2",
+			WantValues: map[string]string{":v1": "why?", ":v2": "because"},
+		},
+		"double quotes": {
+			Expr:       `#Question = "it's #1?"`,
+			Want:       "#n1 = :v1",
+			WantValues: map[string]string{":v1": "it's #1?"},
+		},
+		"escaped quote": {
+			Expr:       `#Question = 'it\'s'`,
+			Want:       "#n1 = :v1",
+			WantValues: map[string]string{":v1": "it's"},
+		},
+		"function argument": {
+			Expr:       "begins_with(#Question, 'who?')",
+			Want:       "begins_with(#n1, :v1)",
+			WantValues: map[string]string{":v1": "who?"},
+		},
+		"escaped placeholder": {
+			Expr: `#A = \?`,
+			Want: "#n1 = ?",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			expr := newExpression()
+			got, err := expr.parse(tc.Expr, tc.Values...)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+			if got, want := len(expr.Values), len(tc.WantValues); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			for k, want := range tc.WantValues {
+				if got := expr.Values[k]; got == nil || got.S == nil || *got.S != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("unterminated", func(t *testing.T) {
+		expr := newExpression()
+		if _, err := expr.parse("#A = 'abc"); err == nil {
+			t.Fatal("got nil; want not nil")
+		}
+	})
+}
+
+func TestParse_ReservedWords(t *testing.T) {
+	testCases := map[string]struct {
+		Expr   string
+		Values []interface{}
+		Want   string
+		Names  []string
+	}{
+		"bare": {
+			Expr:   "Status = ?",
+			Values: []interface{}{"active"},
+			Want:   "#n1 = :v1",
+			Names:  []string{"Status"},
+		},
+		"lower case": {
+			Expr:   "name = ? and #Date > ?",
+			Values: []interface{}{"abc", "2020"},
+			Want:   "#n2 = :v1 and #n1 > :v2",
+			Names:  []string{"Date", "name"},
+		},
+		"reused": {
+			Expr:   "#Status = ? or Status = ?",
+			Values: []interface{}{"a", "b"},
+			Want:   "#n1 = :v1 or #n1 = :v2",
+			Names:  []string{"Status"},
+		},
+		"nested": {
+			Expr: "#Item.size > 3",
+			Want: "#n1.#n2 > 3",
+			Names: []string{
+				"Item",
+				"size",
+			},
+		},
+		"functions and keywords": {
+			Expr:   "size(#Attr) between ? and ? and not attribute_exists(#Other)",
+			Values: []interface{}{1, 3},
+			Want:   "size(#n1) between :v1 and :v2 and not attribute_exists(#n2)",
+			Names:  []string{"Attr", "Other"},
+		},
+		"unreserved": {
+			Expr: "hello = 1",
+			Want: "hello = 1",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			expr := newExpression()
+			got, err := expr.parse(tc.Expr, tc.Values...)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+			if got, want := len(expr.Names), len(tc.Names); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			for i, name := range tc.Names {
+				key := "#n" + strconv.Itoa(i+1)
+				if got := expr.Names[key]; got == nil || *got != name {
+					t.Fatalf("got %v; want %v", got, name)
+				}
+			}
+		})
+	}
+}
+
 func Test_expression_FilterExpression(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -143,3 +336,159 @@ func Test_expression_FilterExpression(t *testing.T) {
 		})
 	}
 }
+
+func Test_attributeFunc(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		names map[string]string
+	}{
+		{
+			name:  "#Field",
+			want:  "attribute_exists(#n1)",
+			names: map[string]string{"#n1": "Field"},
+		},
+		{
+			name:  "Field",
+			want:  "attribute_exists(#n1)",
+			names: map[string]string{"#n1": "Field"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := newExpression()
+			s, values := attributeFunc("attribute_exists", tt.name)
+			err := expr.Filter(s, values...)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+
+			if got := *expr.FilterExpression(); got != tt.want {
+				t.Fatalf("got %v; want %v", got, tt.want)
+			}
+			for k, v := range tt.names {
+				if got := *expr.Names[k]; got != v {
+					t.Fatalf("got %v; want %v", got, v)
+				}
+			}
+		})
+	}
+}
+
+func Test_expression_concurrent(t *testing.T) {
+	const n = 50
+
+	var (
+		expr = newExpression()
+		wg   sync.WaitGroup
+	)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			if err := expr.Filter("#? = ?", "a", i); err != nil {
+				t.Errorf("got %v; want nil", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got, want := len(expr.Names), 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(expr.Values), n; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := strings.Count(*expr.FilterExpression(), " and "), n-1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func BenchmarkExpression_parse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		expr := newExpression()
+		if _, err := expr.parse("#a = ? and #b = ? and size(#? ) > ?", "x", "y", "c", 3); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+	}
+}
+
+func BenchmarkExpression_Set(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		expr := newExpression()
+		if err := expr.Set("#a = ?", "x"); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if err := expr.Set("#b = ?", "y"); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if err := expr.Condition("attribute_exists(#a)"); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+	}
+}
+
+func TestParse_NameResolution(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		expr := table.newExpression()
+		expr.names.caseInsensitive = true
+		if _, err := expr.parse("#count = ? and #ID = ?", 1, "abc"); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := *expr.Names["#n1"], "Count"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("exact by default", func(t *testing.T) {
+		expr := table.newExpression()
+		if _, err := expr.parse("#count = ?", 1); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := *expr.Names["#n1"], "count"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		expr := table.newExpression()
+		expr.names.strict = true
+		_, err := expr.parse("#Unknown = ?", 1)
+		if !IsUnknownAttributeError(err) {
+			t.Fatalf("got %v; want UnknownAttribute", err)
+		}
+		if got, want := err.Error(), "UnknownAttribute: attribute, Unknown, is not defined by the model; known attributes are Count, Date, ID, a, b"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		if _, err := expr.parse("#a.#nested = ? and #? = ?", 1, "dynamic", 2); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("strict via options", func(t *testing.T) {
+		table := New(nil).WithStrictNames().WithCaseInsensitiveNames().MustTable("example", UpdateTable{})
+		if _, err := table.Update("abc").Range("def").Set("#COUNT = ?", 1).UpdateItemInput(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, err := table.Update("abc").Range("def").Set("#Total = ?", 1).UpdateItemInput(); !IsUnknownAttributeError(err) {
+			t.Fatalf("got %v; want UnknownAttribute", err)
+		}
+	})
+}
+
+func TestExpression_parseAllocs(t *testing.T) {
+	// budget tracks BenchmarkExpression_parse; see testdata/benchmarks.txt
+	const budget = 17
+	allocs := testing.AllocsPerRun(100, func() {
+		expr := newExpression()
+		_, _ = expr.parse("#a = ? and #b = ? and size(#? ) > ?", "x", "y", "c", 3)
+	})
+	if allocs > budget {
+		t.Fatalf("got %v allocs; want <= %v", allocs, budget)
+	}
+}
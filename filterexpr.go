@@ -0,0 +1,186 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"strings"
+)
+
+const (
+	filterAnd = "and"
+	filterOr  = "or"
+)
+
+// FilterExpr holds a reusable filter expression along with its values.  FilterExprs
+// may be combined with And and Or and applied to any Query or Scan via FilterExprs, or
+// to any Put, Update, or Delete via ConditionExprs, allowing common filters to be
+// defined once e.g.
+//
+//	active := ddb.NewFilterExpr("attribute_not_exists(#ArchivedAt)")
+//	visible := active.And(ddb.NewFilterExpr("#Owner = ?", owner))
+//	table.Query("#ID = ?", id).FilterExprs(visible)
+//
+// ddb.Filter is unrelated; it matches stream records.
+type FilterExpr struct {
+	expr   string
+	values []interface{}
+	op     string // op holds the operator joining the clauses of expr; blank for leaves
+}
+
+// NewFilterExpr returns a filter using the same syntax as Query.Filter
+func NewFilterExpr(expr string, values ...interface{}) FilterExpr {
+	return FilterExpr{
+		expr:   strings.TrimSpace(expr),
+		values: values,
+	}
+}
+
+// And returns a filter matching items that match f and all of filters
+func (f FilterExpr) And(filters ...FilterExpr) FilterExpr {
+	return combineFilterExprs(filterAnd, append([]FilterExpr{f}, filters...))
+}
+
+// Or returns a filter matching items that match f or any of filters
+func (f FilterExpr) Or(filters ...FilterExpr) FilterExpr {
+	return combineFilterExprs(filterOr, append([]FilterExpr{f}, filters...))
+}
+
+// Not returns a filter matching items that do not match f
+func (f FilterExpr) Not() FilterExpr {
+	if f.expr == "" {
+		return f
+	}
+	return FilterExpr{
+		expr:   "not (" + f.expr + ")",
+		values: f.values,
+	}
+}
+
+// String returns the unbound expression
+func (f FilterExpr) String() string {
+	return f.expr
+}
+
+// operand returns the expression of f as an operand of op; clauses are parenthesized
+// unless joined by op
+func (f FilterExpr) operand(op string) string {
+	if f.op == op {
+		return f.expr
+	}
+	return "(" + f.expr + ")"
+}
+
+func combineFilterExprs(op string, filters []FilterExpr) FilterExpr {
+	var nonEmpty []FilterExpr
+	for _, f := range filters {
+		if f.expr != "" {
+			nonEmpty = append(nonEmpty, f)
+		}
+	}
+
+	switch len(nonEmpty) {
+	case 0:
+		return FilterExpr{}
+	case 1:
+		return nonEmpty[0]
+	}
+
+	var (
+		clauses []string
+		values  []interface{}
+	)
+	for _, f := range nonEmpty {
+		clauses = append(clauses, f.operand(op))
+		values = append(values, f.values...)
+	}
+
+	return FilterExpr{
+		expr:   strings.Join(clauses, " "+op+" "),
+		values: values,
+		op:     op,
+	}
+}
+
+// Between returns a filter matching items where the attribute, name, is between lo and
+// hi inclusive.  name may either be an expression name e.g. #Age or a raw attribute
+// name.
+func Between(name string, lo, hi interface{}) FilterExpr {
+	expr, values := expressionName(name)
+	return NewFilterExpr(expr+" between ? and ?", append(values, lo, hi)...)
+}
+
+// BeginsWith returns a filter matching items where the attribute, name, begins with
+// prefix e.g. BeginsWith("#SK", "ORDER#")
+func BeginsWith(name string, prefix interface{}) FilterExpr {
+	expr, values := expressionName(name)
+	return NewFilterExpr("begins_with("+expr+", ?)", append(values, prefix)...)
+}
+
+// Contains returns a filter matching items where the string attribute, name, contains
+// the substring, v, or the set or list attribute, name, contains the element, v
+func Contains(name string, v interface{}) FilterExpr {
+	expr, values := expressionName(name)
+	return NewFilterExpr("contains("+expr+", ?)", append(values, v)...)
+}
+
+// FilterExprs filters the query by each of filters
+func (q *Query) FilterExprs(filters ...FilterExpr) *Query {
+	for _, f := range filters {
+		if f.expr != "" {
+			q.Filter(f.operand(filterAnd), f.values...)
+		}
+	}
+	return q
+}
+
+// FilterExprs filters the scan by each of filters
+func (s *Scan) FilterExprs(filters ...FilterExpr) *Scan {
+	for _, f := range filters {
+		if f.expr != "" {
+			s.Filter(f.operand(filterAnd), f.values...)
+		}
+	}
+	return s
+}
+
+// ConditionExprs adds each of conditions to the put
+func (p *Put) ConditionExprs(conditions ...FilterExpr) *Put {
+	for _, c := range conditions {
+		if c.expr != "" {
+			p.Condition(c.operand(filterAnd), c.values...)
+		}
+	}
+	return p
+}
+
+// ConditionExprs adds each of conditions to the update
+func (u *Update) ConditionExprs(conditions ...FilterExpr) *Update {
+	for _, c := range conditions {
+		if c.expr != "" {
+			u.Condition(c.operand(filterAnd), c.values...)
+		}
+	}
+	return u
+}
+
+// ConditionExprs adds each of conditions to the delete
+func (d *Delete) ConditionExprs(conditions ...FilterExpr) *Delete {
+	for _, c := range conditions {
+		if c.expr != "" {
+			d.Condition(c.operand(filterAnd), c.values...)
+		}
+	}
+	return d
+}
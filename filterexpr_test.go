@@ -0,0 +1,143 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestFilterExpr(t *testing.T) {
+	var (
+		active = NewFilterExpr("attribute_not_exists(#Field)")
+		small  = NewFilterExpr("#Field < ?", 10)
+		large  = NewFilterExpr("#Field > ?", 100)
+	)
+
+	testCases := map[string]struct {
+		Filter FilterExpr
+		Want   string
+	}{
+		"leaf": {
+			Filter: small,
+			Want:   "(#Field < ?)",
+		},
+		"and": {
+			Filter: active.And(small),
+			Want:   "(attribute_not_exists(#Field)) and (#Field < ?)",
+		},
+		"or within and": {
+			Filter: active.And(small.Or(large)),
+			Want:   "(attribute_not_exists(#Field)) and ((#Field < ?) or (#Field > ?))",
+		},
+		"and flattened": {
+			Filter: active.And(small).And(large),
+			Want:   "(attribute_not_exists(#Field)) and (#Field < ?) and (#Field > ?)",
+		},
+		"not": {
+			Filter: small.Or(large).Not(),
+			Want:   "(not ((#Field < ?) or (#Field > ?)))",
+		},
+		"empty ignored": {
+			Filter: FilterExpr{}.And(small, FilterExpr{}),
+			Want:   "(#Field < ?)",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got := tc.Filter.operand(filterAnd); got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestQuery_FilterExprs(t *testing.T) {
+	var (
+		table  = New(&Mock{}).MustTable("example", QueryExample{})
+		recent = NewFilterExpr("#Date > ?", "2020-01-01")
+		either = NewFilterExpr("#Date = ?", "a").Or(NewFilterExpr("#Date = ?", "b"))
+	)
+
+	input, err := table.Query("#ID = ?", "abc").
+		FilterExprs(recent, either).
+		QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.FilterExpression), "(#n2 > :v2) and ((#n2 = :v3) or (#n2 = :v4))"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeValues[":v4"].S), "b"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	scan, err := table.Scan().FilterExprs(either).ScanInput(0, 1)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(scan.FilterExpression), "((#n1 = :v1) or (#n1 = :v2))"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestFilterExpr_Helpers(t *testing.T) {
+	table := New(&Mock{}).MustTable("example", QueryExample{})
+
+	t.Run("query", func(t *testing.T) {
+		input, err := table.Query("#ID = ?", "abc").
+			FilterExprs(Between("Date", "2020-01-01", "2020-12-31").Or(BeginsWith("#Date", "2021-"))).
+			QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.FilterExpression), "((#n2 between :v2 and :v3) or (begins_with(#n2, :v4)))"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		input, err := table.Update("abc").Range("2020").
+			Set("#Tags = ?", []string{"vip"}).
+			ConditionExprs(Contains("Tags", "new")).
+			UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ConditionExpression), "(contains(#n1, :v2))"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("put and delete", func(t *testing.T) {
+		put, err := table.Put(QueryExample{ID: "abc", Date: "2020"}).ConditionExprs(BeginsWith("#ID", "a")).PutItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(put.ConditionExpression), "(begins_with(#n1, :v1))"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		del, err := table.Delete("abc").Range("2020").ConditionExprs(Between("#Date", "2019", "2021")).DeleteItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(del.ConditionExpression), "(#n1 between :v1 and :v2)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
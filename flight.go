@@ -0,0 +1,69 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// flightCall holds an in flight or completed GetItem call
+type flightCall struct {
+	wg     sync.WaitGroup
+	dups   int // dups counts the callers waiting on this call
+	output *dynamodb.GetItemOutput
+	err    error
+}
+
+// flightGroup coalesces concurrent GetItem calls with the same key into a single call
+type flightGroup struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		calls: map[string]*flightCall{},
+	}
+}
+
+// do invokes fn unless a call with the same key is already in flight, in which case
+// do waits for and returns that call's result.  executed is true if fn was invoked
+// by this caller.
+func (f *flightGroup) do(key string, fn func() (*dynamodb.GetItemOutput, error)) (output *dynamodb.GetItemOutput, executed bool, err error) {
+	f.mutex.Lock()
+	if call, ok := f.calls[key]; ok {
+		call.dups++
+		f.mutex.Unlock()
+		call.wg.Wait()
+		return call.output, false, call.err
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	f.calls[key] = call
+	f.mutex.Unlock()
+
+	defer func() {
+		f.mutex.Lock()
+		delete(f.calls, key)
+		f.mutex.Unlock()
+		call.wg.Done()
+	}()
+
+	call.output, call.err = fn()
+	return call.output, true, call.err
+}
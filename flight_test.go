@@ -0,0 +1,107 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// blockingGetMock counts GetItem calls and blocks each until release is closed
+type blockingGetMock struct {
+	*Mock
+	calls   int64
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingGetMock) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if atomic.AddInt64(&b.calls, 1) == 1 {
+		close(b.started)
+	}
+	<-b.release
+	return b.Mock.GetItemWithContext(ctx, input, opts...)
+}
+
+// waiting returns the number of callers waiting on in flight calls
+func (f *flightGroup) waiting() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var n int
+	for _, call := range f.calls {
+		n += call.dups
+	}
+	return n
+}
+
+func TestTable_WithSingleflight(t *testing.T) {
+	const n = 10
+
+	var (
+		mock = &blockingGetMock{
+			Mock:    &Mock{getItem: Example{ID: "abc", Name: "name"}, readUnits: 1},
+			started: make(chan struct{}),
+			release: make(chan struct{}),
+		}
+		table = New(mock).MustTable("example", Example{}).WithSingleflight()
+		wg    sync.WaitGroup
+		errs  = make(chan error, n)
+	)
+
+	get := func() {
+		defer wg.Done()
+		var got Example
+		if err := table.Get("abc").Scan(&got); err != nil {
+			errs <- err
+			return
+		}
+		if got.Name != "name" {
+			errs <- errorf("unexpected", "got %v; want name", got.Name)
+		}
+	}
+
+	// the first Get blocks within GetItem so the remaining Gets join it
+	wg.Add(1)
+	go get()
+	<-mock.started
+
+	wg.Add(n - 1)
+	for i := 1; i < n; i++ {
+		go get()
+	}
+	for table.flight.waiting() < n-1 {
+		runtime.Gosched()
+	}
+	close(mock.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := atomic.LoadInt64(&mock.calls), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := table.ConsumedCapacity().ReadUnits, int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
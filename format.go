@@ -0,0 +1,103 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// FormatItem renders item compactly for logs and error messages with attributes sorted
+// by name and types collapsed e.g.
+//
+//	{Count: 3, Data: 0x0102, Deleted: null, ID: "abc", Tags: set["a", "b"]}
+//
+// Strings are quoted, numbers and booleans are written as is, binary values are hex
+// encoded, and lists and maps are written as [...] and {...} respectively.
+func FormatItem(item map[string]*dynamodb.AttributeValue) string {
+	var sb strings.Builder
+	formatMap(&sb, item)
+	return sb.String()
+}
+
+func formatMap(sb *strings.Builder, m map[string]*dynamodb.AttributeValue) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sb.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteString(": ")
+		formatValue(sb, m[k])
+	}
+	sb.WriteString("}")
+}
+
+func formatValue(sb *strings.Builder, av *dynamodb.AttributeValue) {
+	switch {
+	case av == nil:
+		sb.WriteString("null")
+	case av.S != nil:
+		sb.WriteString(strconv.Quote(*av.S))
+	case av.N != nil:
+		sb.WriteString(*av.N)
+	case av.BOOL != nil:
+		sb.WriteString(strconv.FormatBool(*av.BOOL))
+	case av.B != nil:
+		sb.WriteString("0x")
+		sb.WriteString(hex.EncodeToString(av.B))
+	case av.M != nil:
+		formatMap(sb, av.M)
+	case av.L != nil:
+		sb.WriteString("[")
+		for i, item := range av.L {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			formatValue(sb, item)
+		}
+		sb.WriteString("]")
+	case av.SS != nil:
+		formatSet(sb, len(av.SS), func(i int) string { return strconv.Quote(aws.StringValue(av.SS[i])) })
+	case av.NS != nil:
+		formatSet(sb, len(av.NS), func(i int) string { return aws.StringValue(av.NS[i]) })
+	case av.BS != nil:
+		formatSet(sb, len(av.BS), func(i int) string { return "0x" + hex.EncodeToString(av.BS[i]) })
+	default:
+		sb.WriteString("null")
+	}
+}
+
+func formatSet(sb *strings.Builder, n int, fn func(i int) string) {
+	sb.WriteString("set[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fn(i))
+	}
+	sb.WriteString("]")
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestFormatItem(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{
+		"ID":      {S: aws.String(`a"b`)},
+		"Count":   {N: aws.String("3")},
+		"Active":  {BOOL: aws.Bool(true)},
+		"Data":    {B: []byte{1, 2}},
+		"Deleted": {NULL: aws.Bool(true)},
+		"Items":   {L: []*dynamodb.AttributeValue{{S: aws.String("x")}, {N: aws.String("1")}}},
+		"Attrs":   {M: map[string]*dynamodb.AttributeValue{"b": {N: aws.String("2")}, "a": {S: aws.String("1")}}},
+		"Tags":    {SS: aws.StringSlice([]string{"a", "b"})},
+		"Nums":    {NS: aws.StringSlice([]string{"1", "2"})},
+		"Blobs":   {BS: [][]byte{{0xff}}},
+	}
+
+	want := `{Active: true, Attrs: {a: "1", b: 2}, Blobs: set[0xff], Count: 3, Data: 0x0102, Deleted: null, ID: "a\"b", Items: ["x", 1], Nums: set[1, 2], Tags: set["a", "b"]}`
+	if got := FormatItem(item); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if got, want := FormatItem(nil), "{}"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
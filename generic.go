@@ -0,0 +1,129 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package ddb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+)
+
+// EachT unmarshals each item matched by the query into a T and passes it to fn.  As
+// with Query.Each, iteration stops when fn returns false or an error.
+func EachT[T any](ctx context.Context, q *Query, fn func(v T) (bool, error)) error {
+	return q.EachWithContext(ctx, unmarshalT(fn))
+}
+
+// ScanEachT unmarshals each item returned by the scan into a T and passes it to fn; see
+// EachT.  For parallel scans, fn must be safe for concurrent use.
+func ScanEachT[T any](ctx context.Context, s *Scan, fn func(v T) (bool, error)) error {
+	return s.EachWithContext(ctx, unmarshalT(fn))
+}
+
+// unmarshalT adapts fn to accept an Item
+func unmarshalT[T any](fn func(v T) (bool, error)) func(item Item) (bool, error) {
+	return func(item Item) (bool, error) {
+		var v T
+		if err := item.Unmarshal(&v); err != nil {
+			return false, err
+		}
+		return fn(v)
+	}
+}
+
+// FindAll returns all items matched by the query as a []T e.g.
+//
+//	orders, err := ddb.FindAll[Order](ctx, table.Query("#ID = ?", id))
+//
+// Unlike Query.FindAll, items that fail to unmarshal return an error.  The results are
+// preallocated using the SizeHint or Limit of the query.
+func FindAll[T any](ctx context.Context, q *Query) ([]T, error) {
+	var items []T
+	if n := q.expectedItems(); n > 0 {
+		items = make([]T, 0, n)
+	}
+	return AppendAll(ctx, q, items)
+}
+
+// AppendAll appends the items matched by the query to dst and returns the extended
+// slice.  Items are unmarshalled directly into dst so a dst with sufficient capacity
+// is filled without further allocation e.g.
+//
+//	orders, err = ddb.AppendAll(ctx, table.Query("#ID = ?", id), orders[:0])
+func AppendAll[T any](ctx context.Context, q *Query, dst []T) ([]T, error) {
+	var zero T
+	callback := func(item Item) (bool, error) {
+		dst = append(dst, zero)
+		if err := item.Unmarshal(&dst[len(dst)-1]); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := q.EachWithContext(ctx, callback); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func First[T any](ctx context.Context, q *Query) (T, error) {
+	var v T
+	if err := q.FirstWithContext(ctx, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// DecodeRecord returns the event name of the stream record, one of INSERT, MODIFY, or
+// REMOVE, along with its old and new images unmarshalled into a T e.g.
+//
+//	op, oldUser, newUser, err := ddb.DecodeRecord[User](record)
+//
+// An INSERT returns a nil oldVal and a REMOVE a nil newVal.  Images not included by the
+// StreamViewType of the stream, e.g. KEYS_ONLY, are likewise returned as nil.
+func DecodeRecord[T any](record Record) (op string, oldVal, newVal *T, err error) {
+	decode := func(image map[string]*dynamodb.AttributeValue) (*T, error) {
+		if len(image) == 0 {
+			return nil, nil
+		}
+		var v T
+		if err := unmarshalMap(image, &v); err != nil {
+			return nil, fmt.Errorf("unable to decode %v record, %v: %w", record.EventName, record.EventID, err)
+		}
+		return &v, nil
+	}
+
+	switch op = record.EventName; op {
+	case dynamodbstreams.OperationTypeInsert:
+		newVal, err = decode(record.Change.NewImage)
+	case dynamodbstreams.OperationTypeModify:
+		if oldVal, err = decode(record.Change.OldImage); err == nil {
+			newVal, err = decode(record.Change.NewImage)
+		}
+	case dynamodbstreams.OperationTypeRemove:
+		oldVal, err = decode(record.Change.OldImage)
+	default:
+		err = fmt.Errorf("unable to decode record, %v: unsupported event name, %v", record.EventID, op)
+	}
+	if err != nil {
+		return op, nil, nil, err
+	}
+	return op, oldVal, newVal, nil
+}
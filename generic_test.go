@@ -0,0 +1,220 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package ddb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestFindAll(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		want  = []QueryExample{{ID: "abc", Date: "1"}, {ID: "abc", Date: "2"}}
+		mock  = &Mock{queryItems: []interface{}{want[0], want[1]}}
+		table = New(mock).MustTable("example", QueryExample{})
+	)
+
+	got, err := FindAll[QueryExample](ctx, table.Query("#ID = ?", "abc"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("pointers", func(t *testing.T) {
+		got, err := FindAll[*QueryExample](ctx, table.Query("#ID = ?", "abc"))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if len(got) != 2 || *got[1] != want[1] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestFirst(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		want = QueryExample{ID: "abc", Date: "1"}
+	)
+
+	table := New(&Mock{queryItems: []interface{}{want}}).MustTable("example", QueryExample{})
+	got, err := First[QueryExample](ctx, table.Query("#ID = ?", "abc"))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		table := New(&Mock{}).MustTable("example", QueryExample{})
+		if _, err := First[QueryExample](ctx, table.Query("#ID = ?", "abc")); !IsItemNotFoundError(err) {
+			t.Fatalf("got %v; want ErrItemNotFound", err)
+		}
+	})
+}
+
+func TestEachT(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		items = []QueryExample{{ID: "abc", Date: "1"}, {ID: "abc", Date: "2"}}
+		mock  = &Mock{queryItems: []interface{}{items[0], items[1]}}
+		table = New(mock).MustTable("example", QueryExample{})
+	)
+
+	var got []string
+	err := EachT(ctx, table.Query("#ID = ?", "abc"), func(v QueryExample) (bool, error) {
+		got = append(got, v.Date)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if want := []string{"1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestScanEachT(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		want  = ScanTable{ID: "abc", Name: "name"}
+		mock  = &Mock{scanItems: []interface{}{want}}
+		table = New(mock).MustTable("example", ScanTable{})
+	)
+
+	var got []ScanTable
+	err := ScanEachT(ctx, table.Scan(), func(v ScanTable) (bool, error) {
+		got = append(got, v)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, []ScanTable{want}) {
+		t.Fatalf("got %v; want %v", got, []ScanTable{want})
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	image := func(v Example) map[string]*dynamodb.AttributeValue {
+		item, err := marshalMap(v)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		return item
+	}
+
+	var (
+		before = Example{ID: "abc", Name: "before"}
+		after  = Example{ID: "abc", Name: "after"}
+	)
+
+	testCases := map[string]struct {
+		Record  Record
+		WantOld *Example
+		WantNew *Example
+	}{
+		"insert": {
+			Record:  Record{EventName: "INSERT", Change: Change{NewImage: image(after)}},
+			WantNew: &after,
+		},
+		"modify": {
+			Record:  Record{EventName: "MODIFY", Change: Change{OldImage: image(before), NewImage: image(after)}},
+			WantOld: &before,
+			WantNew: &after,
+		},
+		"remove": {
+			Record:  Record{EventName: "REMOVE", Change: Change{OldImage: image(before)}},
+			WantOld: &before,
+		},
+		"keys only": {
+			Record: Record{EventName: "MODIFY", Change: Change{Keys: image(Example{ID: "abc"})}},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			op, oldVal, newVal, err := DecodeRecord[Example](tc.Record)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := op, tc.Record.EventName; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if !reflect.DeepEqual(oldVal, tc.WantOld) {
+				t.Fatalf("got %v; want %v", oldVal, tc.WantOld)
+			}
+			if !reflect.DeepEqual(newVal, tc.WantNew) {
+				t.Fatalf("got %v; want %v", newVal, tc.WantNew)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, _, _, err := DecodeRecord[Example](Record{EventName: "BOGUS"}); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func TestAppendAll(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(2, 2)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	dst := make([]Example, 1, 8)
+	got, err := AppendAll(ctx, table.Query("#ID = ?", "abc"), dst)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := []Example{{}, {ID: "0", Name: "name"}, {ID: "1", Name: "name"}, {ID: "2", Name: "name"}, {ID: "3", Name: "name"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if &got[0] != &dst[0] {
+		t.Fatalf("got new backing array; want dst reused")
+	}
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		records, err := FindAll[Example](ctx, table.Query("#ID = ?", "abc").SizeHint(1000))
+		if err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if len(records) != 1000 {
+			b.Fatalf("got %v; want 1000", len(records))
+		}
+	}
+}
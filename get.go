@@ -16,10 +16,11 @@ package ddb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
@@ -30,7 +31,18 @@ type Get struct {
 	rangeKey       interface{}
 	consistentRead bool
 	table          *ConsumedCapacity
+	capacity       string
+	flight         *flightGroup
 	request        *ConsumedCapacity
+	expr           *expression
+	projection     string
+	err            error
+	modify         []func(*dynamodb.GetItemInput)
+	noContext      contextFactory
+	tenant         func(ctx context.Context) string
+	stats          *Stats
+	clock          Clock // clock times the request for Stats
+	network        retryPolicy
 }
 
 type getTx struct {
@@ -39,6 +51,11 @@ type getTx struct {
 }
 
 func (g getTx) Decode(v *dynamodb.ItemResponse) error {
+	return g.decodeWithContext(g.get.noContext.background(), v)
+}
+
+// decodeWithContext implements contextDecoder
+func (g getTx) decodeWithContext(ctx context.Context, v *dynamodb.ItemResponse) error {
 	if len(v.Item) == 0 {
 		if tx, err := g.Tx(); err == nil {
 			hashKey, rangeKey, tableName := getMetadata(tx.Get.Key, g.get.spec)
@@ -46,7 +63,20 @@ func (g getTx) Decode(v *dynamodb.ItemResponse) error {
 		}
 		return errorf(ErrItemNotFound, "item not found")
 	}
-	return dynamodbattribute.UnmarshalMap(v.Item, g.value)
+	if err := unmarshalMap(v.Item, g.value); err != nil {
+		return err
+	}
+	return afterGet(ctx, g.value)
+}
+
+// tableSpec implements specSource
+func (g getTx) tableSpec() *tableSpec {
+	return g.get.spec
+}
+
+// tableCapacity implements capacitySink
+func (g getTx) tableCapacity() (string, *ConsumedCapacity) {
+	return g.get.spec.TableName, g.get.table
 }
 
 func (g getTx) Tx() (*dynamodb.TransactGetItem, error) {
@@ -55,16 +85,27 @@ func (g getTx) Tx() (*dynamodb.TransactGetItem, error) {
 		return nil, err
 	}
 
+	if g.get.err != nil {
+		return nil, g.get.err
+	}
+
+	get := &dynamodb.Get{
+		Key:       key,
+		TableName: aws.String(g.get.spec.TableName),
+	}
+	if g.get.projection != "" {
+		get.ProjectionExpression = aws.String(g.get.projection)
+		get.ExpressionAttributeNames = g.get.expr.Names
+	}
+
 	return &dynamodb.TransactGetItem{
-		Get: &dynamodb.Get{
-			Key:       key,
-			TableName: aws.String(g.get.spec.TableName),
-		},
+		Get: get,
 	}, nil
 }
 
+// ConsistentRead enables or disables consistent reading
 func (g *Get) ConsistentRead(enabled bool) *Get {
-	g.consistentRead = true
+	g.consistentRead = enabled
 	return g
 }
 
@@ -74,18 +115,100 @@ func (g *Get) ConsumedCapacity(capture *ConsumedCapacity) *Get {
 	return g
 }
 
+// Stats captures the attempts, duration, and consumed capacity of the request to the
+// property provided.  Gets coalesced by Table.WithSingleflight record only the
+// duration of the Get that made the call.
+func (g *Get) Stats(capture *Stats) *Get {
+	g.stats = capture
+	return g
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (g *Get) ReturnConsumedCapacity(v string) *Get {
+	g.capacity = v
+	return g
+}
+
+// Exists returns true if the item exists.  Only the key attributes are requested
+// so the item is never fetched in full or unmarshalled.
+func (g *Get) Exists(ctx context.Context) (bool, error) {
+	input, err := g.GetItemInput()
+	if err != nil {
+		return false, err
+	}
+	input.ProjectionExpression, input.ExpressionAttributeNames = makeKeyProjection(g.spec)
+
+	defer g.stats.since(g.clock, g.clock.Now())
+	output, err := g.readItem(ctx, input)
+	if err != nil {
+		return false, err
+	}
+
+	g.table.add(output.ConsumedCapacity)
+	if g.request != nil {
+		g.request.add(output.ConsumedCapacity)
+	}
+	g.stats.consumed(output.ConsumedCapacity)
+	if len(output.Item) > 0 {
+		g.stats.found()
+	}
+
+	return len(output.Item) > 0, nil
+}
+
 func (g *Get) GetItemInput() (*dynamodb.GetItemInput, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+
 	key, err := makeKey(g.spec, g.hashKey, g.rangeKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dynamodb.GetItemInput{
+	input := dynamodb.GetItemInput{
 		ConsistentRead:         aws.Bool(g.consistentRead),
 		Key:                    key,
 		TableName:              aws.String(g.spec.TableName),
-		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
-	}, nil
+		ReturnConsumedCapacity: returnConsumedCapacity(g.capacity),
+	}
+	if g.projection != "" {
+		input.ProjectionExpression = aws.String(g.projection)
+		input.ExpressionAttributeNames = g.expr.Names
+	}
+	for _, fn := range g.modify {
+		fn(&input)
+	}
+
+	return &input, nil
+}
+
+// Modify registers a function to alter the GetItemInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  When called multiple times, the
+// functions are applied in order.
+func (g *Get) Modify(fn func(input *dynamodb.GetItemInput)) *Get {
+	g.modify = append(g.modify, fn)
+	return g
+}
+
+// Project limits the attributes returned to those in the projection expression e.g.
+// Project("#A, #B").  Names are resolved as with other expressions so either the field
+// or attribute name may be used.  When called multiple times, the projections are
+// combined.
+func (g *Get) Project(expr string, names ...interface{}) *Get {
+	projection, err := g.expr.Projection(expr, names...)
+	if err != nil {
+		g.err = err
+		return g
+	}
+
+	if g.projection != "" {
+		g.projection += comma
+	}
+	g.projection += projection
+
+	return g
 }
 
 func (g *Get) Range(value interface{}) *Get {
@@ -99,30 +222,82 @@ func (g *Get) ScanWithContext(ctx context.Context, v interface{}) error {
 		return err
 	}
 
-	output, err := g.api.GetItemWithContext(ctx, input)
+	defer g.stats.since(g.clock, g.clock.Now())
+	output, err := g.getItem(ctx, input)
 	if err != nil {
 		return err
 	}
 
-	g.table.add(output.ConsumedCapacity)
-	if g.request != nil {
-		g.request.add(output.ConsumedCapacity)
-	}
-
 	if len(output.Item) == 0 {
 		hashKey, rangeKey, tableName := getMetadata(input.Key, g.spec)
 		return notFoundError(hashKey, rangeKey, tableName)
 	}
 
-	if err := dynamodbattribute.UnmarshalMap(output.Item, v); err != nil {
+	if err := unmarshalMap(output.Item, v); err != nil {
 		return err
 	}
 
-	return nil
+	return afterGet(ctx, v)
+}
+
+// getItem calls GetItem, coalescing concurrent calls for the same input when the table
+// was created with Table.WithSingleflight.  Consumed capacity is only recorded by the
+// caller that made the call.
+func (g *Get) getItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	call := func() (*dynamodb.GetItemOutput, error) {
+		output, err := g.readItem(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		g.table.add(output.ConsumedCapacity)
+		g.stats.consumed(output.ConsumedCapacity)
+		if len(output.Item) > 0 {
+			g.stats.found()
+		}
+		return output, nil
+	}
+
+	if g.flight == nil {
+		output, err := call()
+		if err != nil {
+			return nil, err
+		}
+		if g.request != nil {
+			g.request.add(output.ConsumedCapacity)
+		}
+		return output, nil
+	}
+
+	key, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode get input: %w", err)
+	}
+
+	output, executed, err := g.flight.do(tenantKey(ctx, g.tenant, string(key)), call)
+	if err != nil {
+		return nil, err
+	}
+	if executed && g.request != nil {
+		g.request.add(output.ConsumedCapacity)
+	}
+	return output, nil
+}
+
+// readItem calls GetItem, retrying network errors when the DDB was created with
+// WithNetworkRetry
+func (g *Get) readItem(ctx context.Context, input *dynamodb.GetItemInput) (output *dynamodb.GetItemOutput, err error) {
+	var retry bool
+	err = g.network.do(ctx, func() (err error) {
+		g.stats.attempt(retry)
+		retry = true
+		output, err = g.api.GetItemWithContext(ctx, input, g.stats.options()...)
+		return err
+	})
+	return output, wrapAWSError(err, "GetItem", g.spec, "", input.Key)
 }
 
 func (g *Get) Scan(v interface{}) error {
-	return g.ScanWithContext(defaultContext, v)
+	return g.ScanWithContext(g.noContext.context("Get.Scan"), v)
 }
 
 func (g *Get) ScanTx(v interface{}) GetTx {
@@ -132,11 +307,26 @@ func (g *Get) ScanTx(v interface{}) GetTx {
 	}
 }
 
+// Get retrieves the item with the given hash key.  hashKey may also be a key struct,
+// a struct declaring only the hash and range key of the table, or a key map, a
+// map[string]*dynamodb.AttributeValue holding the key attributes, in place of Range.
 func (t *Table) Get(hashKey interface{}) *Get {
-	return &Get{
-		api:     t.ddb.api,
-		spec:    t.spec,
-		hashKey: hashKey,
-		table:   t.consumed,
+	get := &Get{
+		api:       t.ddb.api,
+		spec:      t.spec,
+		hashKey:   hashKey,
+		table:     t.consumed,
+		capacity:  t.ddb.capacity,
+		noContext: t.ddb.noContext,
+		flight:    t.flight,
+		tenant:    t.tenant,
+		network:   t.ddb.netRetry.withClock(t.ddb.clock),
+		clock:     t.ddb.clock,
+		expr:      t.newExpression(),
+	}
+	if len(t.view) > 0 {
+		expr, names := projectNames(t.view)
+		get.Project(expr, names...)
 	}
+	return get
 }
@@ -103,6 +103,57 @@ func TestGet_One(t *testing.T) {
 	})
 }
 
+func TestGet_Exists(t *testing.T) {
+	t.Run("exists", func(t *testing.T) {
+		var (
+			mock  = &Mock{getItem: GetExample{ID: "abc"}}
+			table = New(mock).MustTable("example", GetExample{})
+		)
+
+		ok, err := table.Get("abc").Exists(context.Background())
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !ok {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := aws.StringValue(mock.getInput.ProjectionExpression), "#n1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(mock.getInput.ExpressionAttributeNames["#n1"]), "ID"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", GetExample{})
+		)
+
+		ok, err := table.Get("abc").Exists(context.Background())
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if ok {
+			t.Fatalf("got true; want false")
+		}
+	})
+
+	t.Run("aws api failed", func(t *testing.T) {
+		var (
+			want  = io.EOF
+			mock  = &Mock{err: want}
+			table = New(mock).MustTable("example", GetExample{})
+		)
+
+		_, err := table.Get("abc").Exists(context.Background())
+		if err != want {
+			t.Fatalf("got %v; want %v", err, want)
+		}
+	})
+}
+
 func TestLive(t *testing.T) {
 	if !runIntegrationTests {
 		t.SkipNow()
@@ -182,4 +233,63 @@ func TestGet_ConsistentRead(t *testing.T) {
 	if !*input.ConsistentRead {
 		t.Fatalf("got false; expected true")
 	}
+
+	g.ConsistentRead(false)
+	input, err = g.GetItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if *input.ConsistentRead {
+		t.Fatalf("got true; expected false")
+	}
+}
+
+func TestGet_Project(t *testing.T) {
+	type Sample struct {
+		ID    string `ddb:"hash"`
+		Name  string `dynamodbav:"name"`
+		Email string
+	}
+
+	table := New(&Mock{}).MustTable("example", Sample{})
+
+	t.Run("ok", func(t *testing.T) {
+		input, err := table.Get("abc").
+			Project("#Name, #?", "Email").
+			GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/get_project.json")
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		input, err := table.Get("abc").
+			Project("#Name").
+			Project("#Email").
+			GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/get_project.json")
+	})
+
+	t.Run("tx", func(t *testing.T) {
+		tx, err := table.Get("abc").Project("#Name, #Email").ScanTx(&Sample{}).Tx()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(tx.Get.ProjectionExpression), "#n1, #n2"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("values not permitted", func(t *testing.T) {
+		_, err := table.Get("abc").Project("#Name, ?", "blah").GetItemInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
 }
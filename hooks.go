@@ -0,0 +1,118 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"reflect"
+)
+
+// BeforePutter may be implemented by models that wish to normalize or validate
+// themselves before being written by Put.  Use a pointer receiver to modify the
+// model prior to marshalling.
+type BeforePutter interface {
+	BeforePut(ctx context.Context) error
+}
+
+// AfterGetter may be implemented by models that wish to compute derived fields
+// after being read by Get, Query, or Scan
+type AfterGetter interface {
+	AfterGet(ctx context.Context) error
+}
+
+// AfterUpdater may be implemented by models passed to Update.NewValues or
+// Update.OldValues and is invoked once the returned values have been decoded
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeUpdater may be implemented by models that wish to amend each Update of their
+// table, e.g. to maintain a modified timestamp or add a condition.  The hook is invoked
+// on a zero value of the model by Update.RunWithContext or Update.Tx before the request
+// is built, at most once per Update.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, update *Update) error
+}
+
+// BeforeDeleter may be implemented by models that wish to amend or veto each Delete of
+// their table.  The hook is invoked on a zero value of the model by
+// Delete.RunWithContext or Delete.Tx before the request is built, at most once per
+// Delete.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context, del *Delete) error
+}
+
+// indirectHook unwraps *interface{} values, as passed by FindAll, so the
+// underlying model may be checked for hooks
+func indirectHook(v interface{}) interface{} {
+	if ptr, ok := v.(*interface{}); ok && ptr != nil {
+		return *ptr
+	}
+	return v
+}
+
+func beforePut(ctx context.Context, v interface{}) error {
+	if hook, ok := indirectHook(v).(BeforePutter); ok {
+		return hook.BeforePut(ctx)
+	}
+	return nil
+}
+
+func afterGet(ctx context.Context, v interface{}) error {
+	if hook, ok := indirectHook(v).(AfterGetter); ok {
+		return hook.AfterGet(ctx)
+	}
+	return nil
+}
+
+func afterUpdate(ctx context.Context, v interface{}) error {
+	if hook, ok := indirectHook(v).(AfterUpdater); ok {
+		return hook.AfterUpdate(ctx)
+	}
+	return nil
+}
+
+// modelHook returns a pointer to a zero value of the table model, so hooks declared on
+// either value or pointer receivers are found, or nil if the model is unknown
+func modelHook(spec *tableSpec) interface{} {
+	if spec == nil || spec.Model == nil {
+		return nil
+	}
+	return reflect.New(spec.Model).Interface()
+}
+
+func beforeUpdate(ctx context.Context, u *Update) error {
+	if u.hooked {
+		return nil
+	}
+	u.hooked = true
+
+	if hook, ok := modelHook(u.spec).(BeforeUpdater); ok {
+		return hook.BeforeUpdate(ctx, u)
+	}
+	return nil
+}
+
+func beforeDelete(ctx context.Context, d *Delete) error {
+	if d.hooked {
+		return nil
+	}
+	d.hooked = true
+
+	if hook, ok := modelHook(d.spec).(BeforeDeleter); ok {
+		return hook.BeforeDelete(ctx, d)
+	}
+	return nil
+}
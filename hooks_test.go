@@ -0,0 +1,230 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type HookExample struct {
+	ID    string `ddb:"hash"`
+	Name  string
+	Upper string `dynamodbav:"-"`
+}
+
+func (h *HookExample) BeforePut(ctx context.Context) error {
+	if h.Name == "" {
+		return fmt.Errorf("name required")
+	}
+	h.Name = strings.TrimSpace(h.Name)
+	return nil
+}
+
+// hookSuffixKey holds a suffix AfterGet appends to Upper, verifying the context reaches the hook
+type hookSuffixKey struct{}
+
+func (h *HookExample) AfterGet(ctx context.Context) error {
+	h.Upper = strings.ToUpper(h.Name)
+	if suffix, ok := ctx.Value(hookSuffixKey{}).(string); ok {
+		h.Upper += suffix
+	}
+	return nil
+}
+
+func (h *HookExample) AfterUpdate(ctx context.Context) error {
+	h.Upper = "updated"
+	return nil
+}
+
+func (h *HookExample) BeforeUpdate(ctx context.Context, update *Update) error {
+	update.Condition("attribute_exists(#ID)")
+	return nil
+}
+
+func (h *HookExample) BeforeDelete(ctx context.Context, del *Delete) error {
+	del.Condition("attribute_exists(#ID)")
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	t.Run("before put", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		err := table.Put(&HookExample{ID: "abc", Name: " name "}).Run()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["Name"].S), "name"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		err = table.Put(&HookExample{ID: "abc"}).Run()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+
+	t.Run("after get", func(t *testing.T) {
+		var (
+			mock  = &Mock{getItem: HookExample{ID: "abc", Name: "name"}}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		var got HookExample
+		err := table.Get("abc").Scan(&got)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Upper, "NAME"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("after get via FindAll", func(t *testing.T) {
+		var (
+			mock  = &Mock{queryItems: []interface{}{HookExample{ID: "abc", Name: "name"}}}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		var got []HookExample
+		err := table.Query("#ID = ?", "abc").FindAll(&got)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := got[0].Upper, "NAME"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("after update", func(t *testing.T) {
+		var (
+			mock  = &Mock{updateItem: HookExample{ID: "abc", Name: "name"}}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		var got HookExample
+		err := table.Update("abc").Set("#Name = ?", "name").NewValues(&got).Run()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Upper, "updated"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("after get via transaction", func(t *testing.T) {
+		item, err := marshalMap(HookExample{ID: "abc", Name: "name"})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		var (
+			mock  = &transactGetMock{Mock: &Mock{}, responses: []*dynamodb.ItemResponse{{Item: item}}}
+			db    = New(mock)
+			table = db.MustTable("example", HookExample{})
+			ctx   = context.WithValue(context.Background(), hookSuffixKey{}, "!")
+		)
+
+		var got HookExample
+		if err := db.TransactGetItemsWithContext(ctx, table.Get("abc").ScanTx(&got)); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := got.Upper, "NAME!"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("before update", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		if err := table.Update("abc").Set("#Name = ?", "name").Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.updateInput.ConditionExpression), "attribute_exists(#n2)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("before delete", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		if err := table.Delete("abc").Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.deleteInput.ConditionExpression), "attribute_exists(#n1)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("before update and delete via transaction", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			db    = New(mock)
+			table = db.MustTable("example", HookExample{})
+		)
+
+		_, err := db.TransactWriteItemsWithContext(context.Background(),
+			table.Update("abc").Set("#Name = ?", "name"),
+			table.Delete("def"),
+		)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		items := mock.writeInput.TransactItems
+		if got, want := aws.StringValue(items[0].Update.ConditionExpression), "attribute_exists(#n2)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(items[1].Delete.ConditionExpression), "attribute_exists(#n1)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("before update applied once", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", HookExample{})
+		)
+
+		update := table.Update("abc").Set("#Name = ?", "name")
+		if _, err := update.Tx(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if err := update.Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.updateInput.ConditionExpression), "attribute_exists(#n2)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
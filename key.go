@@ -0,0 +1,182 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+var (
+	marshalerType = reflect.TypeOf((*dynamodbattribute.Marshaler)(nil)).Elem()
+	timeType      = reflect.TypeOf(time.Time{})
+)
+
+// isKeyStruct returns true if v is a struct that may hold a primary key rather than
+// itself being a key value
+func isKeyStruct(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		return false
+	}
+	if _, ok := lookupMarshaler(reflect.TypeOf(v)); ok {
+		return false
+	}
+	return true
+}
+
+// splitKeyStruct returns the hash and range key held by the key struct, v, a struct
+// declaring only the hash and, if defined, range key of the table e.g.
+//
+//	type OrderKey struct {
+//		ID  string `ddb:"hash"`
+//		Seq int64  `ddb:"range"`
+//	}
+func splitKeyStruct(spec *tableSpec, v interface{}) (hashKey, rangeKey interface{}, err error) {
+	keys, err := inspect(spec.TableName, v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key struct, %T: %w", v, err)
+	}
+
+	matches := func(got, want *keySpec) bool {
+		if got == nil || want == nil {
+			return got == want
+		}
+		return got.AttributeName == want.AttributeName && got.AttributeType == want.AttributeType
+	}
+	if !matches(keys.HashKey, spec.HashKey) || !matches(keys.RangeKey, spec.RangeKey) {
+		return nil, nil, fmt.Errorf("invalid key struct, %T: keys do not match those of table, %v", v, spec.TableName)
+	}
+	fields := 1
+	if keys.RangeKey != nil {
+		fields = 2
+	}
+	if len(keys.Attributes) != fields {
+		return nil, nil, fmt.Errorf("invalid key struct, %T: may only contain key fields", v)
+	}
+
+	return keys.keyValues(v)
+}
+
+// splitKeyMap returns the hash and range key attributes of item, a raw key or item such
+// as the Keys or OldImage of a stream record.  Attributes not present are returned as
+// nil.
+func splitKeyMap(spec *tableSpec, item map[string]*dynamodb.AttributeValue) (hashKey, rangeKey interface{}) {
+	if key := spec.HashKey; key != nil {
+		if av, ok := item[key.AttributeName]; ok {
+			hashKey = av
+		}
+	}
+	if key := spec.RangeKey; key != nil {
+		if av, ok := item[key.AttributeName]; ok {
+			rangeKey = av
+		}
+	}
+	return hashKey, rangeKey
+}
+
+// keyValues returns the hash and range key values held by the struct, v.  rangeKey is
+// nil if the table has no range key.
+func (spec *tableSpec) keyValues(v interface{}) (hashKey, rangeKey interface{}, err error) {
+	value, _, ok := addressableStruct(v)
+	if !ok {
+		return nil, nil, fmt.Errorf("unable to extract key: want struct or pointer to struct, got %T", v)
+	}
+
+	field := func(key *keySpec) (interface{}, error) {
+		for _, attr := range spec.Attributes {
+			if attr.AttributeName != key.AttributeName {
+				continue
+			}
+			fv := value.FieldByName(attr.FieldName)
+			if !fv.IsValid() {
+				break
+			}
+			if fv.Kind() == reflect.String && fv.String() == "" {
+				return nil, fmt.Errorf("unable to extract key: %v of %T is blank", attr.FieldName, v)
+			}
+			return fv.Interface(), nil
+		}
+		return nil, fmt.Errorf("unable to extract key: %T has no field for key attribute, %v", v, key.AttributeName)
+	}
+
+	if spec.HashKey == nil {
+		return nil, nil, fmt.Errorf("unable to extract key: no hash key defined for %v", spec.TableName)
+	}
+	if hashKey, err = field(spec.HashKey); err != nil {
+		return nil, nil, err
+	}
+	if spec.RangeKey != nil {
+		if rangeKey, err = field(spec.RangeKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return hashKey, rangeKey, nil
+}
+
+// KeyOf returns the primary key of the item, v, a struct or pointer to struct of the
+// table model
+func (t *Table) KeyOf(v interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	hashKey, rangeKey, err := t.spec.keyValues(v)
+	if err != nil {
+		return nil, err
+	}
+	return makeKey(t.spec, hashKey, rangeKey)
+}
+
+// DeleteItem deletes the item with the primary key of v; see Delete
+func (t *Table) DeleteItem(v interface{}) *Delete {
+	hashKey, rangeKey, err := t.spec.keyValues(v)
+	d := t.Delete(hashKey).Range(rangeKey)
+	if err != nil {
+		d.err = err
+	}
+	return d
+}
+
+// GetItem retrieves the item with the primary key of v; see Get
+func (t *Table) GetItem(v interface{}) *Get {
+	hashKey, rangeKey, err := t.spec.keyValues(v)
+	g := t.Get(hashKey).Range(rangeKey)
+	if err != nil {
+		g.err = err
+	}
+	return g
+}
+
+// UpdateItem updates the item with the primary key of v; see Update.  Only the key is
+// taken from v; use SetAll to update the remaining fields from v.
+func (t *Table) UpdateItem(v interface{}) *Update {
+	hashKey, rangeKey, err := t.spec.keyValues(v)
+	u := t.Update(hashKey).Range(rangeKey)
+	if err != nil {
+		u.err = err
+	}
+	return u
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type KeyExample struct {
+	ID    string `ddb:"hash"`
+	Seq   int64  `ddb:"range" dynamodbav:"seq"`
+	Label string
+}
+
+func TestTable_KeyOf(t *testing.T) {
+	table := New(nil).MustTable("example", KeyExample{})
+
+	got, err := table.KeyOf(&KeyExample{ID: "abc", Seq: 0, Label: "ignored"})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := map[string]*dynamodb.AttributeValue{
+		"ID":  {S: aws.String("abc")},
+		"seq": {N: aws.String("0")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, v := range []interface{}{KeyExample{Seq: 1}, "abc", Example{ID: "abc"}} {
+			if _, err := table.KeyOf(v); err == nil {
+				t.Fatalf("got nil; want err for %#v", v)
+			}
+		}
+	})
+}
+
+func TestTable_GetItem(t *testing.T) {
+	var (
+		table = New(nil).MustTable("example", KeyExample{})
+		item  = KeyExample{ID: "abc", Seq: 3}
+	)
+
+	want, err := table.KeyOf(item)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	get, err := table.GetItem(item).GetItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(get.Key, want) {
+		t.Fatalf("got %v; want %v", get.Key, want)
+	}
+
+	del, err := table.DeleteItem(item).DeleteItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(del.Key, want) {
+		t.Fatalf("got %v; want %v", del.Key, want)
+	}
+
+	update, err := table.UpdateItem(item).Set("#Label = ?", "x").UpdateItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(update.Key, want) {
+		t.Fatalf("got %v; want %v", update.Key, want)
+	}
+
+	if _, err := table.GetItem(KeyExample{}).GetItemInput(); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
+
+func TestTable_KeyStruct(t *testing.T) {
+	type OrderKey struct {
+		ID  string `ddb:"hash"`
+		Seq int64  `ddb:"range" dynamodbav:"seq"`
+	}
+
+	table := New(nil).MustTable("example", KeyExample{})
+	want, err := table.KeyOf(KeyExample{ID: "abc", Seq: 3})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	get, err := table.Get(OrderKey{ID: "abc", Seq: 3}).GetItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(get.Key, want) {
+		t.Fatalf("got %v; want %v", get.Key, want)
+	}
+
+	del, err := table.Delete(&OrderKey{ID: "abc", Seq: 3}).DeleteItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(del.Key, want) {
+		t.Fatalf("got %v; want %v", del.Key, want)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		type HashOnly struct {
+			ID string `ddb:"hash"`
+		}
+		type SwappedKey struct {
+			ID  int64  `ddb:"hash"`
+			Seq string `ddb:"range" dynamodbav:"seq"`
+		}
+
+		testCases := map[string]*Update{
+			"missing range": table.Update(HashOnly{ID: "abc"}),
+			"swapped types": table.Update(SwappedKey{ID: 1, Seq: "abc"}),
+			"not key only":  table.Update(KeyExample{ID: "abc", Seq: 3}),
+			"with range":    table.Update(OrderKey{ID: "abc", Seq: 3}).Range(3),
+		}
+		for label, update := range testCases {
+			if _, err := update.UpdateItemInput(); err == nil {
+				t.Fatalf("got nil; want err for %v", label)
+			}
+		}
+	})
+}
+
+func TestTable_KeyMap(t *testing.T) {
+	table := New(nil).MustTable("example", KeyExample{})
+	image := map[string]*dynamodb.AttributeValue{
+		"ID":    {S: aws.String("abc")},
+		"seq":   {N: aws.String("1")},
+		"Label": {S: aws.String("ignored")},
+	}
+
+	input, err := table.Delete(image).DeleteItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	want := map[string]*dynamodb.AttributeValue{
+		"ID":  {S: aws.String("abc")},
+		"seq": {N: aws.String("1")},
+	}
+	if got := input.Key; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("missing range", func(t *testing.T) {
+		_, err := table.Get(map[string]*dynamodb.AttributeValue{"ID": {S: aws.String("abc")}}).GetItemInput()
+		if !IsMissingKeyError(err) {
+			t.Fatalf("got %v; want ErrMissingKey", err)
+		}
+	})
+}
@@ -0,0 +1,427 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// EmptyValues determines how empty strings, empty binary values, and empty
+// collections are encoded
+type EmptyValues int
+
+const (
+	// EmptyAsNull encodes empty values as NULL.  This is the default and matches
+	// the behavior of dynamodbattribute
+	EmptyAsNull EmptyValues = iota
+	// EmptyAsEmpty stores empty values as is e.g. "" is stored as an empty string
+	EmptyAsEmpty
+	// EmptyOmitted removes empty and NULL values from items entirely.  Expression
+	// values are encoded as NULL as they cannot be omitted.
+	EmptyOmitted
+)
+
+// encoder holds the options used to encode items and expression values
+type encoder struct {
+	emptyValues EmptyValues
+}
+
+// encoders are stateless once configured so a single instance per mode is shared
+var (
+	nullEncoder  = dynamodbattribute.NewEncoder()
+	emptyEncoder = dynamodbattribute.NewEncoder(func(enc *dynamodbattribute.Encoder) {
+		enc.NullEmptyString = false
+		enc.NullEmptyByteSlice = false
+		enc.EnableEmptyCollections = true
+	})
+)
+
+func (e encoder) newEncoder() *dynamodbattribute.Encoder {
+	if e.emptyValues == EmptyAsEmpty {
+		return emptyEncoder
+	}
+	return nullEncoder
+}
+
+func (e encoder) marshal(item interface{}) (*dynamodb.AttributeValue, error) {
+	switch v := item.(type) {
+	case *dynamodb.AttributeValue:
+		return v, nil
+	case map[string]*dynamodb.AttributeValue:
+		return &dynamodb.AttributeValue{M: v}, nil
+	case []*dynamodb.AttributeValue:
+		return &dynamodb.AttributeValue{L: v}, nil
+	default:
+		if item != nil {
+			if m, ok := lookupMarshaler(reflect.TypeOf(item)); ok {
+				return m.marshal(item)
+			}
+		}
+		return e.newEncoder().Encode(item)
+	}
+}
+
+// marshalKey encodes a key value.  Common scalar key types are encoded directly
+// rather than through dynamodbattribute as keys are marshalled on every request;
+// all other values, including types with a registered marshaler, fall back to
+// marshal.
+func marshalKey(item interface{}) (*dynamodb.AttributeValue, error) {
+	if av, ok := marshalScalar(item); ok {
+		if _, registered := lookupMarshaler(reflect.TypeOf(item)); !registered {
+			return av, nil
+		}
+	}
+	return marshal(item)
+}
+
+// marshalScalar returns the attribute value of item if item is a non-empty string or
+// []byte or a built in numeric type, encoded as dynamodbattribute would encode it
+func marshalScalar(item interface{}) (*dynamodb.AttributeValue, bool) {
+	var n string
+	switch v := item.(type) {
+	case string:
+		if v == "" {
+			return nil, false
+		}
+		return newScalar(v, false), true
+	case []byte:
+		if len(v) == 0 {
+			return nil, false
+		}
+		return &dynamodb.AttributeValue{B: v}, true
+	case int:
+		n = strconv.FormatInt(int64(v), 10)
+	case int8:
+		n = strconv.FormatInt(int64(v), 10)
+	case int16:
+		n = strconv.FormatInt(int64(v), 10)
+	case int32:
+		n = strconv.FormatInt(int64(v), 10)
+	case int64:
+		n = strconv.FormatInt(v, 10)
+	case uint:
+		n = strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		n = strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		n = strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		n = strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		n = strconv.FormatUint(v, 10)
+	case float32:
+		n = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		n = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, false
+	}
+	return newScalar(n, true), true
+}
+
+// scalar holds an attribute value along with the string it points to so both are
+// allocated together
+type scalar struct {
+	av    dynamodb.AttributeValue
+	value string
+}
+
+// newScalar returns an N attribute value when number is true and an S attribute
+// value otherwise, using a single allocation
+func newScalar(value string, number bool) *dynamodb.AttributeValue {
+	s := &scalar{value: value}
+	if number {
+		s.av.N = &s.value
+	} else {
+		s.av.S = &s.value
+	}
+	return &s.av
+}
+
+func (e encoder) marshalMap(item interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	switch v := item.(type) {
+	case map[string]*dynamodb.AttributeValue:
+		return v, nil
+	default:
+		av, err := e.newEncoder().Encode(item)
+		if err != nil {
+			return nil, err
+		}
+
+		m := map[string]*dynamodb.AttributeValue{}
+		if av != nil && av.M != nil {
+			m = av.M
+		}
+		if err := applyMarshalers(item, m); err != nil {
+			return nil, err
+		}
+		if e.emptyValues == EmptyOmitted {
+			for k, v := range m {
+				if isEmptyAttributeValue(v) {
+					delete(m, k)
+				}
+			}
+		}
+		return m, nil
+	}
+}
+
+// isEmptyAttributeValue returns true if the value is NULL or holds an empty string,
+// binary, or collection
+func isEmptyAttributeValue(item *dynamodb.AttributeValue) bool {
+	switch {
+	case item == nil:
+		return true
+	case item.NULL != nil && *item.NULL:
+		return true
+	case item.S != nil:
+		return *item.S == ""
+	case item.B != nil:
+		return len(item.B) == 0
+	case item.L != nil:
+		return len(item.L) == 0
+	case item.M != nil:
+		return len(item.M) == 0
+	case item.SS != nil:
+		return len(item.SS) == 0
+	case item.NS != nil:
+		return len(item.NS) == 0
+	case item.BS != nil:
+		return len(item.BS) == 0
+	default:
+		return false
+	}
+}
+
+// MarshalFunc encodes a value of a registered type into a dynamodb attribute value
+type MarshalFunc func(v interface{}) (*dynamodb.AttributeValue, error)
+
+// UnmarshalFunc decodes the attribute value into v, a pointer to the registered type
+type UnmarshalFunc func(item *dynamodb.AttributeValue, v interface{}) error
+
+type marshaler struct {
+	marshal   MarshalFunc
+	unmarshal UnmarshalFunc
+}
+
+var marshalers = struct {
+	mux   sync.RWMutex
+	types map[reflect.Type]marshaler
+}{
+	types: map[reflect.Type]marshaler{},
+}
+
+// RegisterMarshaler allows types that cannot implement dynamodbattribute.Marshaler,
+// e.g. types from third party packages, to be encoded consistently.  Registered types
+// are honored for key values, expression values, and top level fields of models.
+// Types should be registered before tables that use them are created.
+func RegisterMarshaler(t reflect.Type, marshal MarshalFunc, unmarshal UnmarshalFunc) {
+	if t == nil || marshal == nil || unmarshal == nil {
+		panic(fmt.Errorf("RegisterMarshaler requires a type, marshal func, and unmarshal func"))
+	}
+
+	marshalers.mux.Lock()
+	defer marshalers.mux.Unlock()
+
+	marshalers.types[t] = marshaler{
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+}
+
+// lookupMarshaler returns the marshaler registered for the given type, if any
+func lookupMarshaler(t reflect.Type) (marshaler, bool) {
+	marshalers.mux.RLock()
+	defer marshalers.mux.RUnlock()
+
+	m, ok := marshalers.types[t]
+	return m, ok
+}
+
+// hasMarshalers returns true if at least one marshaler has been registered
+func hasMarshalers() bool {
+	marshalers.mux.RLock()
+	defer marshalers.mux.RUnlock()
+
+	return len(marshalers.types) > 0
+}
+
+// indirectStruct follows pointers and interfaces until a struct is found
+func indirectStruct(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		case reflect.Struct:
+			return v, true
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// timeFormatTypes caches whether each struct type holds a time.Time field tagged with
+// the timefmt option
+var timeFormatTypes sync.Map // map[reflect.Type]bool
+
+// hasTimeFormats returns true if the struct type, t, holds a top level time.Time field
+// tagged with timefmt
+func hasTimeFormats(t reflect.Type) bool {
+	if v, ok := timeFormatTypes.Load(t); ok {
+		return v.(bool)
+	}
+
+	var found bool
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := timeFormatMarshaler(t.Field(i)); ok {
+			found = true
+			break
+		}
+	}
+	timeFormatTypes.Store(t, found)
+	return found
+}
+
+// timeFormatMarshaler returns a marshaler that encodes the time.Time field using the
+// layout of its timefmt option, matching the keys built by makeKey and RangeTimeBetween
+func timeFormatMarshaler(field reflect.StructField) (marshaler, bool) {
+	if field.PkgPath != "" || field.Type != timeType {
+		return marshaler{}, false
+	}
+	layout := tagOptionValue(field.Tag.Get(tagKey), optionTimeFormat)
+	if layout == "" {
+		return marshaler{}, false
+	}
+
+	return marshaler{
+		marshal: func(v interface{}) (*dynamodb.AttributeValue, error) {
+			return &dynamodb.AttributeValue{S: aws.String(v.(time.Time).UTC().Format(layout))}, nil
+		},
+		unmarshal: func(item *dynamodb.AttributeValue, v interface{}) error {
+			tm, err := time.Parse(layout, aws.StringValue(item.S))
+			if err != nil {
+				return err
+			}
+			*v.(*time.Time) = tm
+			return nil
+		},
+	}, true
+}
+
+// registeredFields invokes fn for each top level field of the struct whose type has
+// a registered marshaler or that is a time.Time tagged with timefmt
+func registeredFields(v reflect.Value, fn func(name string, field reflect.Value, m marshaler) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		m, ok := timeFormatMarshaler(field)
+		if !ok {
+			m, ok = lookupMarshaler(field.Type)
+		}
+		if !ok {
+			continue
+		}
+
+		name, ok := getAttrName(field)
+		if !ok {
+			continue
+		}
+
+		if err := fn(name, v.Field(i), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMarshalers overwrites the encoded values of registered fields within item
+func applyMarshalers(v interface{}, item map[string]*dynamodb.AttributeValue) error {
+	value, ok := indirectStruct(reflect.ValueOf(v))
+	if !ok || (!hasMarshalers() && !hasTimeFormats(value.Type())) {
+		return nil
+	}
+
+	return registeredFields(value, func(name string, field reflect.Value, m marshaler) error {
+		av, err := m.marshal(field.Interface())
+		if err != nil {
+			return wrapf(err, ErrUnableToMarshalItem, "unable to encode field, %v", name)
+		}
+		if av == nil {
+			delete(item, name)
+			return nil
+		}
+		item[name] = av
+		return nil
+	})
+}
+
+// unmarshalMap decodes item into v honoring registered marshalers
+func unmarshalMap(item map[string]*dynamodb.AttributeValue, v interface{}) error {
+	value, ok := indirectStruct(reflect.ValueOf(v))
+	if !ok || !value.CanAddr() || (!hasMarshalers() && !hasTimeFormats(value.Type())) {
+		return dynamodbattribute.UnmarshalMap(item, v)
+	}
+
+	var (
+		remain = make(map[string]*dynamodb.AttributeValue, len(item))
+		fields = map[string]func() error{}
+	)
+	for k, av := range item {
+		remain[k] = av
+	}
+
+	err := registeredFields(value, func(name string, field reflect.Value, m marshaler) error {
+		av, ok := remain[name]
+		if !ok {
+			return nil
+		}
+		delete(remain, name)
+		fields[name] = func() error {
+			return m.unmarshal(av, field.Addr().Interface())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := dynamodbattribute.UnmarshalMap(remain, v); err != nil {
+		return err
+	}
+
+	for name, fn := range fields {
+		if err := fn(); err != nil {
+			return fmt.Errorf("unable to decode field, %v: %w", name, err)
+		}
+	}
+
+	return nil
+}
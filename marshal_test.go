@@ -0,0 +1,248 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// thirdParty simulates a type from a package we don't own
+type thirdParty struct {
+	a, b string
+}
+
+func withThirdPartyMarshaler(t *testing.T) {
+	typ := reflect.TypeOf(thirdParty{})
+	RegisterMarshaler(typ,
+		func(v interface{}) (*dynamodb.AttributeValue, error) {
+			tp := v.(thirdParty)
+			return &dynamodb.AttributeValue{S: aws.String(tp.a + ":" + tp.b)}, nil
+		},
+		func(item *dynamodb.AttributeValue, v interface{}) error {
+			segments := strings.Split(aws.StringValue(item.S), ":")
+			if len(segments) != 2 {
+				return fmt.Errorf("invalid value, %v", aws.StringValue(item.S))
+			}
+			*v.(*thirdParty) = thirdParty{a: segments[0], b: segments[1]}
+			return nil
+		},
+	)
+	t.Cleanup(func() {
+		marshalers.mux.Lock()
+		delete(marshalers.types, typ)
+		marshalers.mux.Unlock()
+	})
+}
+
+func TestRegisterMarshaler(t *testing.T) {
+	withThirdPartyMarshaler(t)
+
+	type Sample struct {
+		ID    thirdParty `ddb:"hash" dynamodbav:"id"`
+		Value thirdParty
+		Name  string
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		item, err := marshal(thirdParty{a: "a", b: "b"})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(item.S), "a:b"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		want := Sample{
+			ID:    thirdParty{a: "a", b: "b"},
+			Value: thirdParty{a: "c", b: "d"},
+			Name:  "name",
+		}
+		item, err := marshalMap(want)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(item["id"].S), "a:b"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		var got Sample
+		if err := (baseItem{raw: item}).Unmarshal(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	})
+
+	t.Run("key type", func(t *testing.T) {
+		spec, err := inspect("example", Sample{})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := spec.HashKey.AttributeType, dynamodb.ScalarAttributeTypeS; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("unmarshal fails", func(t *testing.T) {
+		item := map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String("invalid")},
+		}
+		var got Sample
+		if err := unmarshalMap(item, &got); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
+func TestEncoder_EmptyValues(t *testing.T) {
+	type Sample struct {
+		ID    string
+		Name  string
+		Tags  []string
+		Count int
+	}
+
+	sample := Sample{ID: "abc"}
+
+	t.Run("null", func(t *testing.T) {
+		item, err := encoder{emptyValues: EmptyAsNull}.marshalMap(sample)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := item["Name"]; got == nil || !aws.BoolValue(got.NULL) {
+			t.Fatalf("got %v; want NULL", got)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		item, err := encoder{emptyValues: EmptyAsEmpty}.marshalMap(sample)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := item["Name"]; got == nil || got.S == nil || *got.S != "" {
+			t.Fatalf("got %v; want empty string", got)
+		}
+	})
+
+	t.Run("omitted", func(t *testing.T) {
+		item, err := encoder{emptyValues: EmptyOmitted}.marshalMap(sample)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		for _, key := range []string{"Name", "Tags"} {
+			if got, ok := item[key]; ok {
+				t.Fatalf("got %v; want %v omitted", got, key)
+			}
+		}
+		if _, ok := item["Count"]; !ok {
+			t.Fatalf("got omitted; want Count")
+		}
+	})
+
+	t.Run("expression value", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).WithEmptyValues(EmptyAsEmpty).MustTable("example", Sample{})
+		)
+
+		input, err := table.Update("abc").Set("#Name = ?", "").UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := input.ExpressionAttributeValues[":v1"]; got == nil || got.S == nil || *got.S != "" {
+			t.Fatalf("got %v; want empty string", got)
+		}
+	})
+}
+
+func BenchmarkMarshalMap(b *testing.B) {
+	item := UpdateTable{ID: "abc", Date: "2020-01-01", A: "a", B: "b", Count: 3}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := marshalMap(item); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalMap(b *testing.B) {
+	item, err := marshalMap(UpdateTable{ID: "abc", Date: "2020-01-01", A: "a", B: "b", Count: 3})
+	if err != nil {
+		b.Fatalf("got %v; want nil", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var got UpdateTable
+		if err := unmarshalMap(item, &got); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+	}
+}
+
+func TestMarshalMap_Allocs(t *testing.T) {
+	// budget tracks BenchmarkMarshalMap; see testdata/benchmarks.txt
+	const budget = 14
+	var item interface{} = UpdateTable{ID: "abc", Date: "2020-01-01", A: "a", B: "b", Count: 3}
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = marshalMap(item)
+	})
+	if allocs > budget {
+		t.Fatalf("got %v allocs; want <= %v", allocs, budget)
+	}
+}
+
+func TestMarshalScalar(t *testing.T) {
+	type ID string
+
+	testCases := []interface{}{
+		"abc",
+		[]byte("abc"),
+		int(-1), int8(-8), int16(-16), int32(-32), int64(-64),
+		uint(1), uint8(8), uint16(16), uint32(32), uint64(64),
+		float32(1.25), float64(1e21), float64(-0.001),
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%T", tc), func(t *testing.T) {
+			want, err := marshal(tc)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			got, ok := marshalScalar(tc)
+			if !ok {
+				t.Fatalf("got false; want true")
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	for _, tc := range []interface{}{"", []byte{}, ID("abc"), nil, true} {
+		if _, ok := marshalScalar(tc); ok {
+			t.Fatalf("got true; want false for %#v", tc)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package ddb
 
 import (
 	"flag"
+	"strconv"
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -118,6 +119,7 @@ func (m *Mock) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opt
 
 		output.Items = append(output.Items, v)
 	}
+	output.Count = aws.Int64(int64(len(output.Items)))
 
 	return &output, m.err
 }
@@ -176,3 +178,62 @@ func (m *Mock) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItem
 
 	return &output, m.err
 }
+
+// pageMock serves a fixed set of pre-marshalled pages to Query and Scan.  Pages are
+// never modified so pageMock is safe for concurrent use by parallel scans.
+type pageMock struct {
+	dynamodbiface.DynamoDBAPI
+	pages [][]map[string]*dynamodb.AttributeValue
+}
+
+// newPageMock returns a pageMock holding n pages of size items each
+func newPageMock(n, size int) *pageMock {
+	pages := make([][]map[string]*dynamodb.AttributeValue, n)
+	for i := range pages {
+		for j := 0; j < size; j++ {
+			item, err := marshalMap(Example{ID: strconv.Itoa(i*size + j), Name: "name"})
+			if err != nil {
+				panic(err)
+			}
+			pages[i] = append(pages[i], item)
+		}
+	}
+	return &pageMock{pages: pages}
+}
+
+// page returns the items following startKey along with the key of the next page
+func (m *pageMock) page(startKey map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, map[string]*dynamodb.AttributeValue) {
+	var i int
+	if av, ok := startKey["page"]; ok {
+		i, _ = strconv.Atoi(aws.StringValue(av.N))
+	}
+	if i >= len(m.pages) {
+		return nil, nil
+	}
+
+	var lastKey map[string]*dynamodb.AttributeValue
+	if i+1 < len(m.pages) {
+		lastKey = map[string]*dynamodb.AttributeValue{
+			"page": {N: aws.String(strconv.Itoa(i + 1))},
+		}
+	}
+	return m.pages[i], lastKey
+}
+
+func (m *pageMock) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	items, lastKey := m.page(input.ExclusiveStartKey)
+	return &dynamodb.QueryOutput{
+		Count:            aws.Int64(int64(len(items))),
+		Items:            items,
+		LastEvaluatedKey: lastKey,
+	}, nil
+}
+
+func (m *pageMock) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	items, lastKey := m.page(input.ExclusiveStartKey)
+	return &dynamodb.ScanOutput{
+		Count:            aws.Int64(int64(len(items))),
+		Items:            items,
+		LastEvaluatedKey: lastKey,
+	}, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// PatchOp identifies how a Patch updates an attribute
+type PatchOp int
+
+const (
+	// PatchSet assigns the value to the attribute
+	PatchSet PatchOp = iota
+	// PatchRemove removes the attribute from the item
+	PatchRemove
+	// PatchAdd adds the value to a number or set attribute
+	PatchAdd
+)
+
+// PatchOperation describes the update to a single attribute
+type PatchOperation struct {
+	Op    PatchOp
+	Value interface{} // Value holds the value to set or add; unused by PatchRemove
+}
+
+// Patch describes a partial update to an item, keyed by attribute name, that may be
+// applied generically via Update.ApplyPatch
+type Patch map[string]PatchOperation
+
+// Set assigns the value to the attribute, name
+func (p Patch) Set(name string, value interface{}) Patch {
+	p[name] = PatchOperation{Op: PatchSet, Value: value}
+	return p
+}
+
+// Remove removes the attribute, name
+func (p Patch) Remove(name string) Patch {
+	p[name] = PatchOperation{Op: PatchRemove}
+	return p
+}
+
+// Add adds the value to the number or set attribute, name
+func (p Patch) Add(name string, value interface{}) Patch {
+	p[name] = PatchOperation{Op: PatchAdd, Value: value}
+	return p
+}
+
+// MergePatch translates a JSON Merge Patch (RFC 7396) document into a Patch.  Members
+// with null values are removed and all others are set.  Numbers retain their precision.
+// RFC 7396 merges nested objects into the existing value, which a single attribute
+// update cannot express, so documents containing nested objects are rejected with
+// ErrValidation; arrays replace the existing attribute as the RFC specifies.
+func MergePatch(data []byte) (Patch, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to decode merge patch: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("unable to decode merge patch: document must be an object")
+	}
+
+	patch := Patch{}
+	for name, value := range doc {
+		if value == nil {
+			patch.Remove(name)
+			continue
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return nil, errorf(ErrValidation, "unable to apply merge patch to attribute, %v: nested objects are not supported", name)
+		}
+		patch.Set(name, jsonNumbers(value))
+	}
+
+	return patch, nil
+}
+
+// jsonNumbers replaces each json.Number within v with a dynamodbattribute.Number so
+// numbers are encoded as N rather than S
+func jsonNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		return dynamodbattribute.Number(value)
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = jsonNumbers(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonNumbers(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// ApplyPatch adds the operations of the patch to the update in attribute name order.
+// Patches may not modify the key attributes of the table.
+func (u *Update) ApplyPatch(p Patch) *Update {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, key := range []*keySpec{u.spec.HashKey, u.spec.RangeKey} {
+			if key != nil && key.AttributeName == name {
+				u.err = fmt.Errorf("patch may not modify key attribute, %v", name)
+				return u
+			}
+		}
+
+		switch op := p[name]; op.Op {
+		case PatchSet:
+			u.Set("#? = ?", name, op.Value)
+		case PatchRemove:
+			u.Remove("#?", name)
+		case PatchAdd:
+			u.Add("#? ?", name, op.Value)
+		default:
+			u.err = fmt.Errorf("unsupported patch operation, %v, for attribute, %v", op.Op, name)
+			return u
+		}
+	}
+
+	return u
+}
+
+// MergePatchUpdate returns an Update of the item identified by key that applies the JSON
+// Merge Patch (RFC 7396) document, patchJSON; see MergePatch.  key may be the hash key,
+// a key struct, or a key map; see Table.Get.  Unlike MergePatch, members are validated
+// against the model of the table: each must name an attribute, by attribute or field
+// name, that is not a key, and string and number attributes must be given string and
+// number values respectively.
+func MergePatchUpdate(table *Table, key interface{}, patchJSON []byte) *Update {
+	update := table.Update(key)
+
+	patch, err := MergePatch(patchJSON)
+	if err != nil {
+		update.err = err
+		return update
+	}
+
+	validated, err := validatePatch(table.spec, patch)
+	if err != nil {
+		update.err = err
+		return update
+	}
+
+	return update.ApplyPatch(validated)
+}
+
+// validatePatch returns the patch keyed by attribute name, ensuring each operation
+// refers to a model attribute and assigns a value of the appropriate type
+func validatePatch(spec *tableSpec, patch Patch) (Patch, error) {
+	validated := Patch{}
+	for name, op := range patch {
+		attr := spec.attribute(name)
+		if attr == nil {
+			return nil, unknownAttribute(name, spec.Attributes)
+		}
+
+		if op.Op == PatchSet {
+			var ok bool
+			switch attr.AttributeType {
+			case dynamodb.ScalarAttributeTypeS:
+				_, ok = op.Value.(string)
+			case dynamodb.ScalarAttributeTypeN:
+				_, ok = op.Value.(dynamodbattribute.Number)
+			default:
+				ok = true
+			}
+			if !ok {
+				return nil, errorf(ErrValidation, "invalid value for attribute, %v: got %T", attr.AttributeName, op.Value)
+			}
+		}
+
+		validated[attr.AttributeName] = op
+	}
+	return validated, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+)
+
+func TestUpdate_ApplyPatch(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+
+	t.Run("ok", func(t *testing.T) {
+		patch := Patch{}.
+			Set("a", "blah").
+			Remove("b").
+			Add("Count", 2)
+
+		input, err := table.Update("hello").Range("world").ApplyPatch(patch).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/update_apply_patch.json")
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		patch, err := MergePatch([]byte(`{"a":"blah","b":null,"Count":12345678901234567890}`))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		input, err := table.Update("hello").Range("world").ApplyPatch(patch).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/update_merge_patch.json")
+	})
+
+	t.Run("key attribute", func(t *testing.T) {
+		_, err := table.Update("hello").Range("world").ApplyPatch(Patch{}.Set("ID", "abc")).UpdateItemInput()
+		if err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+
+	t.Run("nested merge patch", func(t *testing.T) {
+		_, err := MergePatch([]byte(`{"a":{"b":"blah","c":null}}`))
+		if !IsValidationError(err) {
+			t.Fatalf("got %v; want ErrValidation", err)
+		}
+	})
+
+	t.Run("invalid merge patch", func(t *testing.T) {
+		for _, data := range []string{`[]`, `null`, `{`} {
+			if _, err := MergePatch([]byte(data)); err == nil {
+				t.Fatalf("got nil; want err for %v", data)
+			}
+		}
+	})
+}
+
+func TestMergePatchUpdate(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+	key, err := table.KeyOf(UpdateTable{ID: "hello", Date: "world"})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		input, err := MergePatchUpdate(table, key, []byte(`{"A":"blah","b":null,"Count":12345678901234567890}`)).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		assertEqual(t, input, "testdata/update_merge_patch.json")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string]struct {
+			Patch string
+			Is    func(error) bool
+		}{
+			"unknown attribute": {
+				Patch: `{"c":"blah"}`,
+				Is:    IsUnknownAttributeError,
+			},
+			"string type": {
+				Patch: `{"a":123}`,
+				Is:    IsValidationError,
+			},
+			"number type": {
+				Patch: `{"Count":"abc"}`,
+				Is:    IsValidationError,
+			},
+			"key attribute": {
+				Patch: `{"ID":"abc"}`,
+				Is:    func(err error) bool { return err != nil },
+			},
+			"malformed": {
+				Patch: `[]`,
+				Is:    func(err error) bool { return err != nil },
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				_, err := MergePatchUpdate(table, key, []byte(tc.Patch)).UpdateItemInput()
+				if !tc.Is(err) {
+					t.Fatalf("got %v; want error", err)
+				}
+			})
+		}
+	})
+}
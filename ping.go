@@ -0,0 +1,84 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// classifyPing wraps err with a code identifying the class of failure: ErrUnauthorized,
+// ErrUnreachable, or ErrThrottled.  Errors of other classes are returned as is.
+func classifyPing(err error, tableName string) error {
+	if err == nil {
+		return nil
+	}
+
+	code := ""
+	var ae awserr.Error
+	var ne net.Error
+	switch {
+	case isThrottleError(err):
+		code = ErrThrottled
+	case isErrorClass(err, classUnauthorized):
+		code = ErrUnauthorized
+	case errors.As(err, &ae) && (ae.Code() == request.ErrCodeRequestError || ae.Code() == request.CanceledErrorCode),
+		errors.As(err, &ne),
+		errors.Is(err, context.DeadlineExceeded):
+		code = ErrUnreachable
+	default:
+		return err
+	}
+
+	return &baseError{
+		code:      code,
+		message:   "ping failed",
+		cause:     err,
+		tableName: tableName,
+	}
+}
+
+// Ping verifies that DynamoDB is reachable with the configured credentials using
+// DescribeEndpoints, which reads no table and consumes no capacity.  Failures are
+// classified by code; use IsUnauthorizedError, IsUnreachableError, and
+// IsThrottledError to distinguish them.  Intended for readiness probes.
+func (d *DDB) Ping(ctx context.Context) error {
+	_, err := d.api.DescribeEndpointsWithContext(ctx, &dynamodb.DescribeEndpointsInput{})
+	return classifyPing(err, "")
+}
+
+// Ping verifies that the table exists and is available using DescribeTable.  Failures
+// are classified as with DDB.Ping.  Use Ping in preference to DDB.Ping when the
+// credentials are scoped to specific tables or when using DynamoDB Local.
+func (t *Table) Ping(ctx context.Context) error {
+	output, err := t.ddb.api.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return classifyPing(err, t.tableName)
+	}
+
+	if status := aws.StringValue(output.Table.TableStatus); status != dynamodb.TableStatusActive && status != dynamodb.TableStatusUpdating {
+		return fmt.Errorf("ping failed: table, %v, is %v", t.tableName, status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// pingMock fails DescribeEndpoints and DescribeTable with err
+type pingMock struct {
+	dynamodbiface.DynamoDBAPI
+	err    error
+	status string
+}
+
+func (p *pingMock) DescribeEndpointsWithContext(aws.Context, *dynamodb.DescribeEndpointsInput, ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
+	return &dynamodb.DescribeEndpointsOutput{}, p.err
+}
+
+func (p *pingMock) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableStatus: aws.String(p.status)}}, nil
+}
+
+func TestDDB_Ping(t *testing.T) {
+	testCases := map[string]struct {
+		Err   error
+		Check func(err error) bool
+	}{
+		"ok": {
+			Check: func(err error) bool { return err == nil },
+		},
+		"unauthorized": {
+			Err:   awserr.New("UnrecognizedClientException", "invalid token", nil),
+			Check: IsUnauthorizedError,
+		},
+		"unreachable": {
+			Err:   awserr.New(request.ErrCodeRequestError, "send request failed", io.EOF),
+			Check: IsUnreachableError,
+		},
+		"deadline": {
+			Err:   context.DeadlineExceeded,
+			Check: IsUnreachableError,
+		},
+		"throttled": {
+			Err:   awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "slow down", nil),
+			Check: IsThrottledError,
+		},
+		"other": {
+			Err:   io.EOF,
+			Check: func(err error) bool { return err == io.EOF },
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			err := New(&pingMock{err: tc.Err}).Ping(context.Background())
+			if !tc.Check(err) {
+				t.Fatalf("got %v; want %v classified", err, label)
+			}
+		})
+	}
+}
+
+func TestTable_Ping(t *testing.T) {
+	ctx := context.Background()
+
+	table := New(&pingMock{status: dynamodb.TableStatusActive}).MustTable("example", Example{})
+	if err := table.Ping(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	table = New(&pingMock{status: dynamodb.TableStatusCreating}).MustTable("example", Example{})
+	if err := table.Ping(ctx); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+
+	table = New(&pingMock{err: awserr.New("AccessDeniedException", "denied", nil)}).MustTable("example", Example{})
+	if err := table.Ping(ctx); !IsUnauthorizedError(err) {
+		t.Fatalf("got %v; want ErrUnauthorized", err)
+	}
+}
@@ -16,6 +16,7 @@ package ddb
 
 import (
 	"context"
+	"io"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -28,9 +29,18 @@ type Put struct {
 	value                               interface{}
 	request                             *ConsumedCapacity
 	table                               *ConsumedCapacity
+	capacity                            string
 	err                                 error
 	expr                                *expression
+	validator                           Validator
 	returnValuesOnConditionCheckFailure string
+	modify                              []func(*dynamodb.PutItemInput)
+	noContext                           contextFactory
+	stats                               *Stats
+	conflicts                           *contention
+	conditions                          *conditions
+	clock                               Clock     // clock supplies the timestamp of generated keys
+	random                              io.Reader // random supplies the randomness of generated keys
 }
 
 func (p *Put) Condition(expr string, values ...interface{}) *Put {
@@ -41,21 +51,67 @@ func (p *Put) Condition(expr string, values ...interface{}) *Put {
 	return p
 }
 
+// ConditionNamed adds the condition defined on the table as name; see Table.DefineCondition
+func (p *Put) ConditionNamed(name string) *Put {
+	condition, err := p.conditions.lookup(name)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	return p.Condition(condition.expr, condition.values...)
+}
+
+// AttributeExists adds a condition that the attribute, name, exists e.g. #Field
+func (p *Put) AttributeExists(name string) *Put {
+	expr, values := attributeFunc("attribute_exists", name)
+	return p.Condition(expr, values...)
+}
+
+// AttributeNotExists adds a condition that the attribute, name, does not exist e.g. #Field
+func (p *Put) AttributeNotExists(name string) *Put {
+	expr, values := attributeFunc("attribute_not_exists", name)
+	return p.Condition(expr, values...)
+}
+
 // ConsumedCapacity captures consumed capacity to the property provided
 func (p *Put) ConsumedCapacity(capture *ConsumedCapacity) *Put {
 	p.request = capture
 	return p
 }
 
+// Stats captures the attempts, duration, and consumed capacity of the request to the
+// property provided
+func (p *Put) Stats(capture *Stats) *Put {
+	p.stats = capture
+	return p
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (p *Put) ReturnConsumedCapacity(v string) *Put {
+	p.capacity = v
+	return p
+}
+
 func (p *Put) PutItemInput() (*dynamodb.PutItemInput, error) {
 	if p.err != nil {
 		return nil, p.err
 	}
 
-	item, err := marshalMap(p.value)
+	item, err := p.expr.encoder.marshalMap(p.value)
 	if err != nil {
 		return nil, err
 	}
+	for _, c := range p.spec.Composites {
+		if av, ok := item[c.AttributeName]; ok && aws.StringValue(av.S) == "" {
+			delete(item, c.AttributeName) // omit blank composite keys from sparse indexes
+		}
+	}
+	for _, name := range p.spec.Flags {
+		if av, ok := item[name]; ok && !aws.BoolValue(av.BOOL) {
+			delete(item, name) // flags are only stored when true
+		}
+	}
 
 	input := dynamodb.PutItemInput{
 		ConditionExpression:       p.expr.ConditionExpression(),
@@ -64,42 +120,98 @@ func (p *Put) PutItemInput() (*dynamodb.PutItemInput, error) {
 		ExpressionAttributeValues: p.expr.Values,
 		TableName:                 aws.String(p.spec.TableName),
 	}
-	if p.request != nil {
-		input.ReturnConsumedCapacity = aws.String(dynamodb.ReturnConsumedCapacityTotal)
+	if p.request != nil || p.stats != nil || p.capacity != "" {
+		input.ReturnConsumedCapacity = returnConsumedCapacity(p.capacity)
+	}
+	for _, fn := range p.modify {
+		fn(&input)
 	}
 
 	return &input, nil
 }
 
+// Modify registers a function to alter the PutItemInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  When called multiple times, the
+// functions are applied in order.
+func (p *Put) Modify(fn func(input *dynamodb.PutItemInput)) *Put {
+	p.modify = append(p.modify, fn)
+	return p
+}
+
 func (p *Put) ReturnValuesOnConditionCheckFailure(value string) *Put {
 	p.returnValuesOnConditionCheckFailure = value
 	return p
 }
 
+// prepare readies the value for writing by applying defaults, invoking hooks,
+// generating auto keys, and validating the result
+func (p *Put) prepare(ctx context.Context) error {
+	p.value = applyDefaults(p.spec, p.value)
+
+	if err := beforePut(ctx, p.value); err != nil {
+		return err
+	}
+
+	v, err := applyAutoKeys(p.spec, p.value, p.clock, p.random)
+	if err != nil {
+		return err
+	}
+	p.value = applyComposites(p.spec, v)
+
+	return validate(p.validator, p.spec.TableName, p.value)
+}
+
 func (p *Put) RunWithContext(ctx context.Context) error {
+	if err := p.prepare(ctx); err != nil {
+		return err
+	}
+
 	input, err := p.PutItemInput()
 	if err != nil {
 		return err
 	}
 
-	output, err := p.api.PutItemWithContext(ctx, input)
+	defer p.stats.since(p.clock, p.clock.Now())
+	p.stats.attempt(false)
+	output, err := p.api.PutItemWithContext(ctx, input, p.stats.options()...)
 	if err != nil {
-		return err
+		p.conflicts.record(err)
+		return wrapAWSError(err, "PutItem", p.spec, "", input.Item)
 	}
 
 	p.table.add(output.ConsumedCapacity)
 	if p.request != nil {
 		p.request.add(output.ConsumedCapacity)
 	}
+	p.stats.consumed(output.ConsumedCapacity)
 
 	return nil
 }
 
 func (p *Put) Run() error {
-	return p.RunWithContext(defaultContext)
+	return p.RunWithContext(p.noContext.context("Put.Run"))
+}
+
+// tableSpec implements specSource
+func (p *Put) tableSpec() *tableSpec {
+	return p.spec
+}
+
+// tableCapacity implements capacitySink
+func (p *Put) tableCapacity() (string, *ConsumedCapacity) {
+	return p.spec.TableName, p.table
+}
+
+// tableContention implements contentionSink
+func (p *Put) tableContention() *contention {
+	return p.conflicts
 }
 
 func (p *Put) Tx() (*dynamodb.TransactWriteItem, error) {
+	if err := p.prepare(p.noContext.background()); err != nil {
+		return nil, err
+	}
+
 	input, err := p.PutItemInput()
 	if err != nil {
 		return nil, err
@@ -123,10 +235,17 @@ func (p *Put) Tx() (*dynamodb.TransactWriteItem, error) {
 
 func (t *Table) Put(v interface{}) *Put {
 	return &Put{
-		api:   t.ddb.api,
-		spec:  t.spec,
-		value: v,
-		table: t.consumed,
-		expr:  newExpression(t.spec.Attributes...),
+		api:        t.ddb.api,
+		spec:       t.spec,
+		value:      v,
+		table:      t.consumed,
+		conflicts:  t.conflicts,
+		conditions: t.conditions,
+		capacity:   t.ddb.capacity,
+		noContext:  t.ddb.noContext,
+		expr:       t.newExpression(),
+		validator:  t.ddb.validator,
+		clock:      t.ddb.clock,
+		random:     t.ddb.random,
 	}
 }
@@ -15,6 +15,7 @@
 package ddb
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -36,9 +37,26 @@ func TestPut_Run(t *testing.T) {
 		)
 
 		err := table.Put(PutTable{ID: "abc"}).Run()
-		if err != want {
+		if !errors.Is(err, want) {
 			t.Fatalf("got %v; want %v", err, want)
 		}
+
+		var oe *OperationError
+		if !errors.As(err, &oe) {
+			t.Fatalf("got %T; want *OperationError", err)
+		}
+		if got, want := oe.Operation, "PutItem"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := oe.TableName(), "example"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if hashKey, _ := oe.Keys(); hashKey == nil || *hashKey.S != "abc" {
+			t.Fatalf("got %v; want abc", hashKey)
+		}
+		if ae, ok := err.(awserr.Error); !ok || ae.Code() != dynamodb.ErrCodeConditionalCheckFailedException {
+			t.Fatalf("got %v; want awserr.Error", err)
+		}
 	})
 }
 
@@ -57,6 +75,24 @@ func TestPut_PutItemInput(t *testing.T) {
 	assertEqual(t, input, "testdata/put_item_input.json")
 }
 
+func TestPut_Flags(t *testing.T) {
+	type Flagged struct {
+		ID      string `ddb:"hash"`
+		Pending bool   `ddb:"flag"`
+	}
+
+	table := New(&Mock{}).MustTable("example", Flagged{})
+	for _, pending := range []bool{true, false} {
+		input, err := table.Put(Flagged{ID: "abc", Pending: pending}).PutItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if _, got := input.Item["Pending"]; got != pending {
+			t.Fatalf("got %v; want %v", got, pending)
+		}
+	}
+}
+
 func TestPut_Condition(t *testing.T) {
 	t.Run("single", func(t *testing.T) {
 		var (
@@ -98,4 +134,37 @@ func TestPut_Condition(t *testing.T) {
 		}
 		assertEqual(t, mock.putInput, "testdata/put_condition_multiple.json")
 	})
+
+	t.Run("attribute not exists", func(t *testing.T) {
+		for _, name := range []string{"#Field", "Field"} {
+			var (
+				item  = PutTable{ID: "abc"}
+				mock  = &Mock{}
+				db    = New(mock)
+				table = db.MustTable("example", PutTable{})
+			)
+
+			err := table.Put(item).AttributeNotExists(name).Run()
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			assertEqual(t, mock.putInput, "testdata/put_condition_single.json")
+		}
+	})
+}
+
+func TestPut_EmptyValues(t *testing.T) {
+	var (
+		mock  = &Mock{}
+		db    = New(mock).WithEmptyValues(EmptyOmitted)
+		table = db.MustTable("example", PutTable{})
+	)
+
+	input, err := table.Put(PutTable{ID: "abc"}).PutItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if _, ok := input.Item["Field"]; ok {
+		t.Fatalf("got Field; want omitted")
+	}
 }
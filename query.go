@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -29,27 +31,53 @@ import (
 type Query struct {
 	api                dynamodbiface.DynamoDBAPI
 	spec               *tableSpec
-	consistentRead     bool
+	consistentRead     *bool // consistentRead, if set, overrides the DynamoDB default of eventually consistent reads
+	dedupeLimit        int
 	lastEvaluatedKey   *map[string]*dynamodb.AttributeValue
 	lastEvaluatedToken *string
 	limit              int64
 	selectAttributes   string
-	scanIndexForward   bool
+	scanIndexForward   *bool // scanIndexForward, if set, overrides the DynamoDB default of ascending order
 	startKey           map[string]*dynamodb.AttributeValue
 	request            *ConsumedCapacity
 	table              *ConsumedCapacity
+	capacity           string
 	err                error
 	expr               *expression
 	indexName          string
 	attributes         []string
+	cache              *queryCache
+	retry              retryPolicy
+	network            retryPolicy
+	modify             []func(*dynamodb.QueryInput)
+	noContext          contextFactory
+	projection         string
+	tenant             func(ctx context.Context) string
+	stats              *Stats
+	clock              Clock    // clock times the request for Stats
+	keyNames           []string // keyNames, if set, holds the attributes referenced by a QueryExpr key condition
+	logger             Logger   // logger, if set, is warned of projections fetched from the base table
+	reuseItems         bool
+	sizeHint           int
 }
 
 func (t *Table) Query(expr string, values ...interface{}) *Query {
 	query := &Query{
-		api:   t.ddb.api,
-		spec:  t.spec,
-		table: t.consumed,
-		expr:  newExpression(t.spec.Attributes...),
+		api:       t.ddb.api,
+		spec:      t.spec,
+		table:     t.consumed,
+		capacity:  t.ddb.capacity,
+		noContext: t.ddb.noContext,
+		expr:      t.newExpression(),
+		cache:     t.queryCache,
+		retry:     t.ddb.pageRetry.withClock(t.ddb.clock),
+		network:   t.ddb.netRetry.withClock(t.ddb.clock),
+		tenant:    t.tenant,
+		logger:    t.ddb.logger,
+		clock:     t.ddb.clock,
+	}
+	if len(t.view) > 0 {
+		query.project(t.view)
 	}
 	return query.KeyCondition(expr, values...)
 }
@@ -60,13 +88,38 @@ func (q *Query) ConsumedCapacity(capture *ConsumedCapacity) *Query {
 	return q
 }
 
+// Stats captures the attempts, pages, items scanned and returned, duration, and
+// consumed capacity of the query to the property provided.  Pages served from the
+// query cache are not counted.
+func (q *Query) Stats(capture *Stats) *Query {
+	q.stats = capture
+	return q
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (q *Query) ReturnConsumedCapacity(v string) *Query {
+	q.capacity = v
+	return q
+}
+
+// ConsistentRead enables or disables consistent reading
 func (q *Query) ConsistentRead(enabled bool) *Query {
-	q.consistentRead = true
+	q.consistentRead = aws.Bool(enabled)
+	return q
+}
+
+// ReuseItems enables or disables passing the same Item to every invocation of the
+// callback.  This avoids an allocation per item for high throughput jobs such as
+// exports.  The Item, and the map returned by its Raw method, are only valid until the
+// callback returns and must not be retained.
+func (q *Query) ReuseItems(enabled bool) *Query {
+	q.reuseItems = enabled
 	return q
 }
 
 func (q *Query) Each(fn func(item Item) (bool, error)) error {
-	return q.EachWithContext(defaultContext, fn)
+	return q.EachWithContext(q.noContext.context("Query.Each"), fn)
 }
 
 func (q *Query) EachWithContext(ctx context.Context, fn func(item Item) (bool, error)) (err error) {
@@ -74,23 +127,24 @@ func (q *Query) EachWithContext(ctx context.Context, fn func(item Item) (bool, e
 		return q.err
 	}
 
-	startKey := q.startKey
+	var (
+		startKey = q.startKey
+		resume   = &resumePoint{start: q.startKey} // resume follows the last item successfully passed to fn
+	)
 	defer func() {
+		key := startKey
+		if err != nil {
+			key = resume.key()
+		}
 		if q.lastEvaluatedKey != nil {
-			*q.lastEvaluatedKey = startKey
+			*q.lastEvaluatedKey = key
 		}
 		if q.lastEvaluatedToken != nil {
-			switch {
-			case len(startKey) == 0:
-				*q.lastEvaluatedToken = ""
-
-			default:
-				data, e := json.Marshal(startKey)
-				if e != nil {
-					err = fmt.Errorf("failed to marshal startKey: %w", err)
-				}
-				*q.lastEvaluatedToken = base64.StdEncoding.EncodeToString(data)
+			token, e := encodeStartToken(key)
+			if e != nil {
+				err = e
 			}
+			*q.lastEvaluatedToken = token
 		}
 	}()
 
@@ -99,32 +153,57 @@ func (q *Query) EachWithContext(ctx context.Context, fn func(item Item) (bool, e
 		return err
 	}
 
+	defer q.stats.since(q.clock, q.clock.Now())
+
+	if q.dedupeLimit != 0 {
+		var (
+			callback = fn
+			dedupe   = newDeduper(q.spec, q.dedupeLimit)
+		)
+		fn = func(item Item) (bool, error) {
+			if ok, err := dedupe.isDuplicate(item.Raw()); err != nil || ok {
+				return err == nil, err
+			}
+			return callback(item)
+		}
+	}
+
+	resume.names, _ = q.spec.keyAttributes(q.indexName)
+	view := itemView(ctx, q.reuseItems)
+
 	for {
 		input.ExclusiveStartKey = startKey
+		resume.reset(startKey)
 
-		output, err := q.api.QueryWithContext(ctx, input)
+		output, cached, err := q.queryPage(ctx, input)
 		if err != nil {
+			if isThrottleError(err) {
+				return newResumeError(err, ErrThrottled, q.spec.TableName, 0, resume.key())
+			}
 			return err
 		}
 		startKey = output.LastEvaluatedKey
 
-		item := baseItem{}
+		if !cached {
+			q.table.add(output.ConsumedCapacity)
+			if q.request != nil {
+				q.request.add(output.ConsumedCapacity)
+			}
+			q.stats.add(output.Count, output.ScannedCount)
+			q.stats.consumed(output.ConsumedCapacity)
+		}
+
 		for _, rawItem := range output.Items {
-			item.raw = rawItem
-			ok, err := fn(item)
+			ok, err := fn(view(rawItem))
 			if err != nil {
 				return err
 			}
+			resume.advance(rawItem)
 			if !ok {
 				return nil
 			}
 		}
 
-		q.table.add(output.ConsumedCapacity)
-		if q.request != nil {
-			q.request.add(output.ConsumedCapacity)
-		}
-
 		if startKey == nil {
 			break
 		}
@@ -136,6 +215,130 @@ func (q *Query) EachWithContext(ctx context.Context, fn func(item Item) (bool, e
 	return nil
 }
 
+// queryPage reads a single page of results, using the query cache when the table was
+// created with Table.WithQueryCache.  cached is true if the page was served from cache.
+func (q *Query) queryPage(ctx context.Context, input *dynamodb.QueryInput) (output *dynamodb.QueryOutput, cached bool, err error) {
+	if q.cache == nil {
+		output, err = q.readPage(ctx, input)
+		return output, false, err
+	}
+
+	query, page, err := q.cache.keys(input)
+	if err != nil {
+		return nil, false, err
+	}
+	page = tenantKey(ctx, q.tenant, page)
+	if output, ok := q.cache.get(query, page); ok {
+		return output, true, nil
+	}
+
+	output, err = q.readPage(ctx, input)
+	if err != nil {
+		return nil, false, err
+	}
+	q.cache.put(query, page, output)
+
+	return output, false, nil
+}
+
+// readPage reads a single page of results, retrying throttled requests
+func (q *Query) readPage(ctx context.Context, input *dynamodb.QueryInput) (output *dynamodb.QueryOutput, err error) {
+	var retry bool
+	err = q.retry.do(ctx, func() error {
+		return q.network.do(ctx, func() (err error) {
+			q.stats.attempt(retry)
+			retry = true
+			output, err = q.api.QueryWithContext(ctx, input, q.stats.options()...)
+			return err
+		})
+	})
+	return output, wrapAWSError(err, "Query", q.spec, q.indexName, nil)
+}
+
+// InvalidateCache removes all cached pages of this query from the query cache.  Has no
+// effect unless the table was created with Table.WithQueryCache.
+func (q *Query) InvalidateCache() error {
+	if q.cache == nil {
+		return nil
+	}
+
+	input, err := q.QueryInput()
+	if err != nil {
+		return err
+	}
+
+	query, _, err := q.cache.keys(input)
+	if err != nil {
+		return err
+	}
+	q.cache.invalidate(query)
+
+	return nil
+}
+
+// Dedupe suppresses items whose primary key has already been passed to the
+// callback, which may occur when paging through a global secondary index that is
+// being written to.  Up to DefaultDedupeLimit keys are remembered; use DedupeLimit
+// to change the bound.  The primary key attributes must be present in each item.
+func (q *Query) Dedupe() *Query {
+	return q.DedupeLimit(DefaultDedupeLimit)
+}
+
+// DedupeLimit enables Dedupe, remembering at most n primary keys.  Once the limit is
+// reached, the oldest keys are forgotten.  A negative n places no bound on the keys
+// remembered.
+func (q *Query) DedupeLimit(n int) *Query {
+	q.dedupeLimit = n
+	return q
+}
+
+// Exists returns true if at least one item matches the query.  Items are counted
+// rather than returned so nothing is unmarshalled.  Without a filter, a single
+// item is evaluated; with a filter, pages are evaluated until a match is found.
+func (q *Query) Exists(ctx context.Context) (bool, error) {
+	input, err := q.queryInput(dynamodb.SelectCount)
+	if err != nil {
+		return false, err
+	}
+	if input.FilterExpression == nil {
+		input.Limit = aws.Int64(1)
+	}
+
+	defer q.stats.since(q.clock, q.clock.Now())
+
+	for {
+		output, err := q.readPage(ctx, input)
+		if err != nil {
+			return false, err
+		}
+
+		q.table.add(output.ConsumedCapacity)
+		if q.request != nil {
+			q.request.add(output.ConsumedCapacity)
+		}
+		q.stats.add(output.Count, output.ScannedCount)
+		q.stats.consumed(output.ConsumedCapacity)
+
+		if aws.Int64Value(output.Count) > 0 {
+			return true, nil
+		}
+		if len(output.LastEvaluatedKey) == 0 {
+			return false, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// Earliest binds the item with the lowest sort key
+func (q *Query) Earliest(v interface{}) error {
+	return q.EarliestWithContext(q.noContext.context("Query.Earliest"), v)
+}
+
+// EarliestWithContext binds the item with the lowest sort key using the context provided
+func (q *Query) EarliestWithContext(ctx context.Context, v interface{}) error {
+	return q.ScanIndexForward(true).firstOnly().FirstWithContext(ctx, v)
+}
+
 // Filter allows for the query to be conditionally filtered
 func (q *Query) Filter(expr string, values ...interface{}) *Query {
 	if err := q.expr.Filter(expr, values...); err != nil {
@@ -145,9 +348,30 @@ func (q *Query) Filter(expr string, values ...interface{}) *Query {
 	return q
 }
 
+// AttributeExists filters out items where the attribute, name, does not exist e.g. #Field
+func (q *Query) AttributeExists(name string) *Query {
+	expr, values := attributeFunc("attribute_exists", name)
+	return q.Filter(expr, values...)
+}
+
+// AttributeNotExists filters out items where the attribute, name, exists e.g. #Field
+func (q *Query) AttributeNotExists(name string) *Query {
+	expr, values := attributeFunc("attribute_not_exists", name)
+	return q.Filter(expr, values...)
+}
+
+// firstOnly limits the query to a single item.  When a filter is present, the
+// limit is left unset as a single evaluated item may not satisfy the filter.
+func (q *Query) firstOnly() *Query {
+	if q.expr.Filters == nil {
+		q.Limit(1)
+	}
+	return q
+}
+
 // First binds the first value and returns
 func (q *Query) First(v interface{}) error {
-	return q.FirstWithContext(defaultContext, v)
+	return q.FirstWithContext(q.noContext.context("Query.First"), v)
 }
 
 // FirstWithContext binds the first value and returns
@@ -171,7 +395,7 @@ func (q *Query) FirstWithContext(ctx context.Context, v interface{}) error {
 
 // FindAll returns all record
 func (q *Query) FindAll(v interface{}) error {
-	return q.FindAllWithContext(defaultContext, v)
+	return q.FindAllWithContext(q.noContext.context("Query.FindAll"), v)
 }
 
 // FindAllWithContext returns all record using context provided
@@ -189,24 +413,30 @@ func (q *Query) FindAllWithContext(ctx context.Context, v interface{}) error {
 	if slice.Kind() != reflect.Slice {
 		return fmt.Errorf("want ptr to slice as input, got %T", v)
 	}
-	records := reflect.New(slice).Elem()
+	records := reflect.MakeSlice(slice, 0, q.expectedItems())
 
-	element := slice.Elem()
-	isPtr := element.Kind() == reflect.Ptr
+	var (
+		element = slice.Elem()
+		zero    = reflect.Zero(element)
+		isPtr   = element.Kind() == reflect.Ptr
+	)
 	if isPtr {
 		element = element.Elem()
 	}
 
 	callback := func(item Item) (bool, error) {
-		v := reflect.New(element).Interface()
-		if err := item.Unmarshal(&v); err != nil {
-			return false, nil
+		n := records.Len()
+		records = reflect.Append(records, zero)
+
+		target := records.Index(n)
+		if isPtr {
+			target.Set(reflect.New(element))
+		} else {
+			target = target.Addr()
 		}
-		record := reflect.ValueOf(v)
-		if !isPtr {
-			record = record.Elem()
+		if err := item.Unmarshal(target.Interface()); err != nil {
+			return false, err
 		}
-		records.Set(reflect.Append(records, record))
 		return true, nil
 	}
 
@@ -232,96 +462,321 @@ func (q *Query) KeyCondition(expr string, values ...interface{}) *Query {
 	return q
 }
 
+// Latest binds the item with the highest sort key
+func (q *Query) Latest(v interface{}) error {
+	return q.LatestWithContext(q.noContext.context("Query.Latest"), v)
+}
+
+// LatestWithContext binds the item with the highest sort key using the context provided
+func (q *Query) LatestWithContext(ctx context.Context, v interface{}) error {
+	return q.ScanIndexForward(false).firstOnly().FirstWithContext(ctx, v)
+}
+
 // Limit returns at most N elements; 0 indicates return all elements
 func (q *Query) Limit(limit int64) *Query {
 	q.limit = limit
 	return q
 }
 
+// SizeHint sets the number of items FindAll expects so the results may be allocated
+// once e.g. from a prior count of the items.  Defaults to Limit, if set.
+func (q *Query) SizeHint(n int) *Query {
+	q.sizeHint = n
+	return q
+}
+
+// expectedItems returns the initial capacity of the slice returned by FindAll
+func (q *Query) expectedItems() int {
+	if q.sizeHint > 0 {
+		return q.sizeHint
+	}
+	if q.limit > 0 {
+		return int(q.limit)
+	}
+	return 0
+}
+
 // QueryInput returns the raw dynamodb QueryInput that will be submitted
 func (q *Query) QueryInput() (*dynamodb.QueryInput, error) {
+	return q.queryInput(q.selectAttributes)
+}
+
+// queryInput returns the QueryInput for the query using selectAttributes in place of
+// the Select of the query, allowing Exists to count without modifying the query
+func (q *Query) queryInput(selectAttributes string) (*dynamodb.QueryInput, error) {
 	if q.err != nil {
 		return nil, q.err
 	}
+	if q.keyNames != nil {
+		if err := q.spec.checkKeyCondition(q.indexName, q.keyNames); err != nil {
+			return nil, err
+		}
+	}
 
 	var indexName *string
 	if q.indexName != "" {
 		indexName = aws.String(q.indexName)
 	}
 
-	if q.selectAttributes == "" {
-		q.selectAttributes = dynamodb.SelectAllAttributes
+	if err := q.checkProjection(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case q.projection != "" && selectAttributes != dynamodb.SelectCount:
+		selectAttributes = dynamodb.SelectSpecificAttributes
+	case selectAttributes == "":
+		selectAttributes = dynamodb.SelectAllAttributes
 	}
 
 	conditionExpression := q.expr.ConditionExpression()
 	filterExpression := q.expr.FilterExpression()
 	input := dynamodb.QueryInput{
-		ConsistentRead:            aws.Bool(q.consistentRead),
+		ConsistentRead:            q.consistentRead,
 		ExclusiveStartKey:         q.startKey,
 		ExpressionAttributeNames:  q.expr.Names,
 		ExpressionAttributeValues: q.expr.Values,
 		FilterExpression:          filterExpression,
 		IndexName:                 indexName,
 		KeyConditionExpression:    conditionExpression,
-		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
-		ScanIndexForward:          aws.Bool(q.scanIndexForward),
-		Select:                    aws.String(q.selectAttributes),
+		ReturnConsumedCapacity:    returnConsumedCapacity(q.capacity),
+		ScanIndexForward:          q.scanIndexForward,
+		Select:                    aws.String(selectAttributes),
 		TableName:                 aws.String(q.spec.TableName),
 	}
 	if q.limit > 0 {
 		input.Limit = aws.Int64(q.limit)
 	}
+	switch {
+	case q.projection == "":
+	case selectAttributes == dynamodb.SelectCount:
+		// DynamoDB rejects a projection with Select COUNT so the projection and the names
+		// only it references are omitted
+		input.ExpressionAttributeNames = referencedNames(q.expr.Names, conditionExpression, filterExpression)
+	default:
+		input.ProjectionExpression = aws.String(q.projection)
+	}
+	for _, fn := range q.modify {
+		fn(&input)
+	}
 	return &input, nil
 }
 
-// Select attributes to return; defaults to dynamodb.SelectAllAttributes
+// Modify registers a function to alter the QueryInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  ExclusiveStartKey is
+// managed by ddb and is replaced as pages are read.  When called multiple times, the
+// functions are applied in order.
+func (q *Query) Modify(fn func(input *dynamodb.QueryInput)) *Query {
+	q.modify = append(q.modify, fn)
+	return q
+}
+
+// ProjectInto limits the attributes returned to those of the struct, v, using the same
+// attribute names, e.g. from dynamodbav tags, as used to unmarshal into v.  This allows
+// narrow read models to fetch only the attributes they use e.g.
+//
+//	var summaries []OrderSummary
+//	err := table.Query("#ID = ?", id).ProjectInto(OrderSummary{}).FindAll(&summaries)
+func (q *Query) ProjectInto(v interface{}) *Query {
+	spec, err := inspect(q.spec.TableName, v)
+	if err != nil {
+		q.err = fmt.Errorf("unable to project into %T: %w", v, err)
+		return q
+	}
+	if len(spec.Attributes) == 0 {
+		q.err = fmt.Errorf("unable to project into %T: no attributes", v)
+		return q
+	}
+
+	var names []string
+	for _, attr := range spec.Attributes {
+		names = append(names, attr.AttributeName)
+	}
+
+	return q.project(names)
+}
+
+// project limits the attributes returned to the attribute names provided
+func (q *Query) project(names []string) *Query {
+	expr, values := projectNames(names)
+	projection, err := q.expr.Projection(expr, values...)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	q.projection = projection
+
+	return q
+}
+
+// RangeTimeBetween restricts the range key to values between from and to inclusive.  Times
+// are formatted using the timefmt option of the range key e.g. ddb:"range,timefmt=2006-01-02".
+// When querying an index, IndexName must be called before RangeTimeBetween.
+func (q *Query) RangeTimeBetween(from, to time.Time) *Query {
+	key := q.spec.rangeKey(q.indexName)
+	if key == nil {
+		q.err = fmt.Errorf("unable to query range by time: no range key defined for %v", q.spec.TableName)
+		return q
+	}
+
+	return q.KeyCondition("#? between ? and ?", key.AttributeName, formatKeyTime(key, from), formatKeyTime(key, to))
+}
+
+// checkProjection verifies the projection of an index query only references attributes
+// projected into the index.  Unprojected attributes fail queries of global secondary
+// indexes.  Local secondary indexes fetch them from the base table at additional cost,
+// which is logged as a warning when the DDB was created with WithLogger.
+func (q *Query) checkProjection() error {
+	if q.indexName == "" || q.projection == "" {
+		return nil
+	}
+
+	missing := q.spec.unprojected(q.indexName, projectionAttributes(q.projection, q.expr.Names))
+	if len(missing) == 0 {
+		return nil
+	}
+	if !q.spec.isLocal(q.indexName) {
+		return fmt.Errorf("unable to query index, %v: attributes, %v, are not projected into the index", q.indexName, strings.Join(missing, ", "))
+	}
+	if q.logger != nil {
+		q.logger.Printf("ddb: query on index, %v, of table, %v, fetches unprojected attributes, %v, from the base table", q.indexName, q.spec.TableName, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// projectionAttributes returns the top level attribute names referenced by the
+// projection expression, resolving #names using names
+func projectionAttributes(projection string, names map[string]*string) []string {
+	var attributes []string
+	for _, path := range strings.Split(projection, ",") {
+		path = strings.TrimSpace(path)
+		if i := strings.IndexAny(path, ".["); i >= 0 {
+			path = path[:i]
+		}
+		if name, ok := names[path]; ok {
+			path = aws.StringValue(name)
+		}
+		if path != "" {
+			attributes = append(attributes, path)
+		}
+	}
+	return attributes
+}
+
+// Select attributes to return; defaults to dynamodb.SelectAllAttributes, or
+// dynamodb.SelectSpecificAttributes when a projection is set
 func (q *Query) Select(s string) *Query {
 	q.selectAttributes = s
 	return q
 }
 
-// LastEvaluatedKey stores the last evaluated key into the provided value
+// LastEvaluatedKey stores the last evaluated key into the provided value.  If Each
+// returns an error, the key of the last item successfully passed to the callback is
+// stored instead so the query may be resumed via StartKey.  Should the item lack its
+// key attributes e.g. due to Select, the start of the failed page is stored and the
+// items of that page already processed will be seen again.
 func (q *Query) LastEvaluatedKey(lastEvaluatedKey *map[string]*dynamodb.AttributeValue) *Query {
 	q.lastEvaluatedKey = lastEvaluatedKey
 	return q
 }
 
-// LastEvaluatedToken stores the last evaluated key as a base64 encoded string
+// LastEvaluatedToken stores the last evaluated key as a base64 encoded string suitable
+// for StartToken.  As with LastEvaluatedKey, when Each returns an error, the token
+// resumes after the last item successfully passed to the callback.
 func (q *Query) LastEvaluatedToken(lastEvaluatedToken *string) *Query {
 	q.lastEvaluatedToken = lastEvaluatedToken
 	return q
 }
 
-// ScanIndexForward when true returns the values in reverse sort key order
+// ScanIndexForward when true returns the values in ascending sort key order and
+// when false, in descending sort key order.  When not called, ScanIndexForward is
+// omitted from the request and DynamoDB returns values in ascending order; earlier
+// releases always sent false, so call Descending to keep their order.
 // https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_Query.html
 func (q *Query) ScanIndexForward(enabled bool) *Query {
-	q.scanIndexForward = enabled
+	q.scanIndexForward = aws.Bool(enabled)
 	return q
 }
 
+// Descending returns the values in descending sort key order; shorthand for ScanIndexForward(false)
+func (q *Query) Descending() *Query {
+	return q.ScanIndexForward(false)
+}
+
 // StartKey assigns the continuation key used for query pagination
 func (q *Query) StartKey(startKey map[string]*dynamodb.AttributeValue) *Query {
 	q.startKey = startKey
 	return q
 }
 
+// StartAfter assigns the continuation key from the key attributes of the model or key
+// struct, v, so the query resumes after that item.  When querying an index, IndexName
+// must be called before StartAfter.
+func (q *Query) StartAfter(v interface{}) *Query {
+	names, err := q.spec.keyAttributes(q.indexName)
+	if err != nil {
+		q.err = err
+		return q
+	}
+
+	item, err := q.expr.encoder.marshalMap(v)
+	if err != nil {
+		q.err = wrapf(err, ErrUnableToMarshalItem, "unable to encode start key from %T", v)
+		return q
+	}
+
+	startKey := map[string]*dynamodb.AttributeValue{}
+	for _, name := range names {
+		av, ok := item[name]
+		if !ok || isEmptyAttributeValue(av) {
+			q.err = fmt.Errorf("unable to build start key: %T has no value for key attribute, %v", v, name)
+			return q
+		}
+		startKey[name] = av
+	}
+
+	return q.StartKey(startKey)
+}
+
 // StartToken encodes start key as a base64 encoded string
 func (q *Query) StartToken(token string) *Query {
+	startKey, err := decodeStartToken(token)
+	if err != nil {
+		q.err = err
+		return q
+	}
+
+	return q.StartKey(startKey)
+}
+
+// encodeStartToken encodes the start key as a base64 encoded string
+func encodeStartToken(startKey map[string]*dynamodb.AttributeValue) (string, error) {
+	if len(startKey) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(startKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal start key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decodeStartToken decodes a start key previously encoded by encodeStartToken
+func decodeStartToken(token string) (map[string]*dynamodb.AttributeValue, error) {
 	if token == "" {
-		return q.StartKey(nil)
+		return nil, nil
 	}
 
 	data, err := base64.StdEncoding.DecodeString(token)
 	if err != nil {
-		q.err = fmt.Errorf("failed to base64 decode start token: %w", err)
-		return q
+		return nil, fmt.Errorf("failed to base64 decode start token: %w", err)
 	}
 
 	var startKey map[string]*dynamodb.AttributeValue
 	if err := json.Unmarshal(data, &startKey); err != nil {
-		q.err = fmt.Errorf("failed to json decode start token:% w", err)
-		return q
+		return nil, fmt.Errorf("failed to json decode start token: %w", err)
 	}
 
-	return q.StartKey(startKey)
+	return startKey, nil
 }
@@ -16,6 +16,7 @@ package ddb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -57,6 +58,69 @@ type QueryExample struct {
 	Date string `ddb:"range"`
 }
 
+func TestQuery_QueryInput(t *testing.T) {
+	table := New(nil).MustTable("example", QueryExample{})
+
+	t.Run("unset", func(t *testing.T) {
+		input, err := table.Query("#ID = ?", "abc").QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/query_unset.json")
+	})
+
+	t.Run("default order", func(t *testing.T) {
+		var (
+			item  = QueryExample{ID: "abc", Date: "2019-03-10"}
+			mock  = &Mock{queryItems: []interface{}{item}}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		var got QueryExample
+		if err := table.Query("#ID = ?", "abc").First(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		// ScanIndexForward is omitted, so DynamoDB returns items in ascending order
+		assertEqual(t, mock.queryInput, "testdata/query_default_order.json")
+	})
+
+	t.Run("consistent read", func(t *testing.T) {
+		input, err := table.Query("#ID = ?", "abc").ConsistentRead(true).QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/query_consistent_read.json")
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		query := table.Query("#ID = ?", "abc").Descending()
+
+		input, err := query.QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/query_descending.json")
+	})
+
+	t.Run("consistent read disabled", func(t *testing.T) {
+		query := table.Query("#ID = ?", "abc").
+			ConsistentRead(true).
+			ConsistentRead(false).
+			ScanIndexForward(true)
+
+		input, err := query.QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/query_consistent_read_disabled.json")
+	})
+}
+
 func TestQuery(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		var (
@@ -180,6 +244,218 @@ func TestQuery_First(t *testing.T) {
 	})
 }
 
+func TestQuery_Exists(t *testing.T) {
+	t.Run("exists", func(t *testing.T) {
+		var (
+			item  = QueryExample{ID: "abc", Date: "2019-03-10"}
+			mock  = &Mock{queryItems: []interface{}{item}}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		ok, err := table.Query("#ID = ?", item.ID).Exists(context.Background())
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !ok {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := aws.StringValue(mock.queryInput.Select), dynamodb.SelectCount; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.Int64Value(mock.queryInput.Limit), int64(1); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		ok, err := table.Query("#ID = ?", "abc").
+			Filter("#Date > ?", "2019").
+			Exists(context.Background())
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if ok {
+			t.Fatalf("got true; want false")
+		}
+		if mock.queryInput.Limit != nil {
+			t.Fatalf("got %v; want nil", *mock.queryInput.Limit)
+		}
+	})
+}
+
+func TestQuery_ExistsReuse(t *testing.T) {
+	var (
+		item  = QueryExample{ID: "abc", Date: "2019-03-10"}
+		mock  = &Mock{queryItems: []interface{}{item}}
+		table = New(mock).MustTable("example", QueryExample{})
+		query = table.Query("#ID = ?", item.ID)
+	)
+
+	if ok, err := query.Exists(context.Background()); err != nil || !ok {
+		t.Fatalf("got %v, %v; want true, nil", ok, err)
+	}
+
+	input, err := query.QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.Select), dynamodb.SelectAllAttributes; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestQuery_Latest(t *testing.T) {
+	var (
+		want  = QueryExample{ID: "abc", Date: "2019-03-10"}
+		mock  = &Mock{queryItems: []interface{}{want}}
+		table = New(mock).MustTable("example", QueryExample{})
+	)
+
+	var got QueryExample
+	err := table.Query("#ID = ?", want.ID).Latest(&got)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.BoolValue(mock.queryInput.ScanIndexForward), false; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.Int64Value(mock.queryInput.Limit), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestQuery_Earliest(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		var (
+			want  = QueryExample{ID: "abc", Date: "2019-03-10"}
+			mock  = &Mock{queryItems: []interface{}{want}}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		var got QueryExample
+		err := table.Query("#ID = ?", want.ID).Earliest(&got)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.BoolValue(mock.queryInput.ScanIndexForward), true; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", QueryExample{})
+		)
+
+		var got QueryExample
+		err := table.Query("#ID = ?", "abc").Earliest(&got)
+		if !IsItemNotFoundError(err) {
+			t.Fatalf("got %v; want ErrItemNotFound", err)
+		}
+	})
+}
+
+func TestQuery_RangeTimeBetween(t *testing.T) {
+	type Sample struct {
+		ID   string `ddb:"hash"`
+		Date string `ddb:"range,timefmt=2006-01-02"`
+	}
+
+	var (
+		table = New(&Mock{}).MustTable("example", Sample{})
+		from  = time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+		to    = time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+	)
+
+	t.Run("ok", func(t *testing.T) {
+		input, err := table.Query("#ID = ?", "abc").
+			RangeTimeBetween(from, to).
+			QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.KeyConditionExpression), "#n1 = :v1 and #n2 between :v2 and :v3"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(input.ExpressionAttributeValues[":v2"].S), "2020-05-01"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(input.ExpressionAttributeValues[":v3"].S), "2020-05-31"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("time field round trip", func(t *testing.T) {
+		type Event struct {
+			ID string    `ddb:"hash"`
+			At time.Time `ddb:"range,timefmt=2006-01-02"`
+		}
+
+		var (
+			mock  = &Mock{}
+			table = New(mock).MustTable("example", Event{})
+			at    = time.Date(2020, time.March, 4, 5, 6, 7, 0, time.UTC)
+			want  = Event{ID: "abc", At: time.Date(2020, time.March, 4, 0, 0, 0, 0, time.UTC)}
+		)
+
+		if err := table.Put(Event{ID: "abc", At: at}).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		item := mock.putInput.Item
+		if got, want := aws.StringValue(item["At"].S), "2020-03-04"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		mock.getItem = item
+		var got Event
+		if err := table.Get("abc").Range(at).Scan(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.getInput.Key["At"].S), aws.StringValue(item["At"].S); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if !got.At.Equal(want.At) || got.ID != want.ID {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		mock.queryItems = []interface{}{item}
+		var events []Event
+		if err := table.Query("#ID = ?", "abc").RangeTimeBetween(at, at).FindAll(&events); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		for _, key := range []string{":v2", ":v3"} {
+			if got, want := aws.StringValue(mock.queryInput.ExpressionAttributeValues[key].S), aws.StringValue(item["At"].S); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		}
+		if len(events) != 1 || !events[0].At.Equal(want.At) {
+			t.Fatalf("got %v; want [%v]", events, want)
+		}
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		_, err := table.Query("#ID = ?", "abc").
+			IndexName("missing").
+			RangeTimeBetween(from, to).
+			QueryInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
 func TestQuery_Filter(t *testing.T) {
 	type Sample struct {
 		Hash  string `ddb:"hash"`
@@ -399,3 +675,396 @@ func TestQuery_FindAllWithContext(t *testing.T) {
 		})
 	})
 }
+
+func TestQuery_FindAllUnmarshalError(t *testing.T) {
+	var (
+		mock = &Mock{queryItems: []interface{}{
+			QueryExample{ID: "abc", Date: "2019-03-10"},
+			map[string]interface{}{"ID": "abc", "Date": true},
+		}}
+		table = New(mock).MustTable("example", QueryExample{})
+	)
+
+	var got []QueryExample
+	if err := table.Query("#ID = ?", "abc").FindAll(&got); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+}
+
+func TestQuery_StartAfter(t *testing.T) {
+	type Sample struct {
+		ID     string `ddb:"hash"`
+		Date   string `ddb:"range"`
+		Status string `ddb:"gsi_hash:status"`
+		Amount int    `ddb:"gsi_range:status"`
+		Note   string
+	}
+
+	var (
+		table  = New(&Mock{}).MustTable("example", Sample{})
+		sample = Sample{ID: "abc", Date: "2020-05-01", Status: "open", Amount: 5, Note: "ignored"}
+	)
+
+	t.Run("table", func(t *testing.T) {
+		input, err := table.Query("#ID = ?", "abc").
+			StartAfter(sample).
+			QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		want := map[string]*dynamodb.AttributeValue{
+			"ID":   {S: aws.String("abc")},
+			"Date": {S: aws.String("2020-05-01")},
+		}
+		if got := input.ExclusiveStartKey; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("index", func(t *testing.T) {
+		input, err := table.Query("#Status = ?", "open").
+			IndexName("status").
+			StartAfter(&sample).
+			QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		want := map[string]*dynamodb.AttributeValue{
+			"ID":     {S: aws.String("abc")},
+			"Date":   {S: aws.String("2020-05-01")},
+			"Status": {S: aws.String("open")},
+			"Amount": {N: aws.String("5")},
+		}
+		if got := input.ExclusiveStartKey; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("missing key value", func(t *testing.T) {
+		_, err := table.Query("#ID = ?", "abc").
+			StartAfter(Sample{ID: "abc"}).
+			QueryInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		_, err := table.Query("#ID = ?", "abc").
+			IndexName("missing").
+			StartAfter(sample).
+			QueryInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
+func TestQuery_ResumeAfterError(t *testing.T) {
+	var (
+		items = []interface{}{
+			QueryExample{ID: "abc", Date: "2020-01-01"},
+			QueryExample{ID: "abc", Date: "2020-01-02"},
+			QueryExample{ID: "abc", Date: "2020-01-03"},
+		}
+		mock  = &Mock{queryItems: items}
+		table = New(mock).MustTable("example", QueryExample{})
+		boom  = errors.New("boom")
+	)
+
+	var (
+		lastKey   map[string]*dynamodb.AttributeValue
+		lastToken string
+		count     int
+	)
+	err := table.Query("#ID = ?", "abc").
+		LastEvaluatedKey(&lastKey).
+		LastEvaluatedToken(&lastToken).
+		Each(func(item Item) (bool, error) {
+			if count++; count == 3 {
+				return false, boom
+			}
+			return true, nil
+		})
+	if err != boom {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+
+	want := map[string]*dynamodb.AttributeValue{
+		"ID":   {S: aws.String("abc")},
+		"Date": {S: aws.String("2020-01-02")},
+	}
+	if !reflect.DeepEqual(lastKey, want) {
+		t.Fatalf("got %v; want %v", lastKey, want)
+	}
+
+	input, err := table.Query("#ID = ?", "abc").StartToken(lastToken).QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got := input.ExclusiveStartKey; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestQuery_Modify(t *testing.T) {
+	table := New(nil).MustTable("example", Example{})
+	input, err := table.Query("#ID = ?", "abc").
+		Modify(func(input *dynamodb.QueryInput) { input.Limit = aws.Int64(5) }).
+		Modify(func(input *dynamodb.QueryInput) { input.Limit = aws.Int64(*input.Limit * 2) }).
+		QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.Int64Value(input.Limit), int64(10); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestQuery_ProjectInto(t *testing.T) {
+	type Summary struct {
+		ID     string
+		Label  string `dynamodbav:"label"`
+		Ignore string `dynamodbav:"-"`
+	}
+
+	table := New(nil).MustTable("example", Example{})
+	input, err := table.Query("#ID = ?", "abc").ProjectInto(Summary{}).QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.ProjectionExpression), "#n1, #n2"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeNames["#n2"]), "label"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.Select), dynamodb.SelectSpecificAttributes; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := table.Query("#ID = ?", "abc").ProjectInto("nope").QueryInput(); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func TestQuery_CheckProjection(t *testing.T) {
+	type Indexed struct {
+		ID       string `ddb:"hash"`
+		Date     string `ddb:"range"`
+		Status   string `ddb:"gsi_hash:status;lsi:lsi"`
+		Amount   int64  `ddb:"gsi:status"`
+		Received string `ddb:"lsi_range:lsi"`
+		Note     string
+	}
+
+	type Projected struct {
+		ID     string
+		Status string
+		Amount int64
+	}
+
+	type Unprojected struct {
+		ID   string
+		Note string
+	}
+
+	var (
+		logger = &bufferLogger{}
+		table  = New(nil).WithLogger(logger).MustTable("example", Indexed{})
+	)
+
+	t.Run("projected", func(t *testing.T) {
+		input, err := table.Query("#Status = ?", "open").IndexName("status").ProjectInto(Projected{}).QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.Select), dynamodb.SelectSpecificAttributes; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("select all", func(t *testing.T) {
+		input, err := table.Query("#Status = ?", "open").
+			IndexName("status").
+			ProjectInto(Projected{}).
+			Select(dynamodb.SelectAllProjectedAttributes).
+			QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.Select), dynamodb.SelectSpecificAttributes; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("global", func(t *testing.T) {
+		_, err := table.Query("#Status = ?", "open").IndexName("status").ProjectInto(Unprojected{}).QueryInput()
+		if err == nil {
+			t.Fatalf("got nil; want err")
+		}
+		if got, want := err.Error(), "unable to query index, status: attributes, Note, are not projected into the index"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("local", func(t *testing.T) {
+		_, err := table.Query("#ID = ?", "abc").IndexName("lsi").ProjectInto(Unprojected{}).QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		want := []string{"ddb: query on index, lsi, of table, example, fetches unprojected attributes, Note, from the base table"}
+		if got := logger.lines; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func BenchmarkQuery_Each(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var count int
+		err := table.Query("#ID = ?", "abc").EachWithContext(ctx, func(item Item) (bool, error) {
+			var v Example
+			if err := item.Unmarshal(&v); err != nil {
+				return false, err
+			}
+			count++
+			return true, nil
+		})
+		if err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if count != 1000 {
+			b.Fatalf("got %v; want 1000", count)
+		}
+	}
+}
+
+func TestQuery_ReuseItems(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(2, 2)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	var (
+		items []Item
+		ids   []string
+	)
+	err := table.Query("#ID = ?", "abc").ReuseItems(true).EachWithContext(ctx, func(item Item) (bool, error) {
+		var v Example
+		if err := item.Unmarshal(&v); err != nil {
+			return false, err
+		}
+		items = append(items, item)
+		ids = append(ids, v.ID)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := ids, []string{"0", "1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for _, item := range items {
+		if item != items[0] {
+			t.Fatalf("got distinct items; want the same item reused")
+		}
+	}
+}
+
+func BenchmarkQuery_EachReuse(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var count int
+		err := table.Query("#ID = ?", "abc").ReuseItems(true).EachWithContext(ctx, func(item Item) (bool, error) {
+			count++
+			return true, nil
+		})
+		if err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if count != 1000 {
+			b.Fatalf("got %v; want 1000", count)
+		}
+	}
+}
+
+func TestQuery_SizeHint(t *testing.T) {
+	var (
+		mock  = newPageMock(2, 3)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	t.Run("struct", func(t *testing.T) {
+		var records []Example
+		if err := table.Query("#ID = ?", "abc").SizeHint(10).FindAll(&records); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(records), 6; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := cap(records), 10; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := records[5].ID, "5"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		var records []*Example
+		if err := table.Query("#ID = ?", "abc").FindAll(&records); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(records), 6; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := records[5].ID, "5"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		if got, want := table.Query("#ID = ?", "abc").Limit(25).expectedItems(), 25; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func BenchmarkQuery_FindAll(b *testing.B) {
+	var (
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	for _, hint := range []int{0, 1000} {
+		b.Run(fmt.Sprintf("hint=%v", hint), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var records []Example
+				if err := table.Query("#ID = ?", "abc").SizeHint(hint).FindAll(&records); err != nil {
+					b.Fatalf("got %v; want nil", err)
+				}
+				if len(records) != 1000 {
+					b.Fatalf("got %v; want 1000", len(records))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type queryCacheEntry struct {
+	output  *dynamodb.QueryOutput
+	expires time.Time
+}
+
+// queryCache holds query pages for a fixed ttl.  Pages are grouped by query, that is
+// the query input less the start key, so all pages of a query may be invalidated
+// together.
+type queryCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mutex   sync.Mutex
+	queries map[string]map[string]queryCacheEntry
+	swept   time.Time // swept holds the last time expired entries were removed
+}
+
+func newQueryCache(ttl time.Duration, now func() time.Time) *queryCache {
+	return &queryCache{
+		ttl:     ttl,
+		now:     now,
+		queries: map[string]map[string]queryCacheEntry{},
+	}
+}
+
+// keys returns the query and page keys for the input
+func (c *queryCache) keys(input *dynamodb.QueryInput) (query, page string, err error) {
+	withoutStartKey := *input
+	withoutStartKey.ExclusiveStartKey = nil
+
+	data, err := json.Marshal(withoutStartKey)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to encode query cache key: %w", err)
+	}
+	query = string(data)
+
+	data, err = json.Marshal(input.ExclusiveStartKey)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to encode query cache key: %w", err)
+	}
+	page = string(data)
+
+	return query, page, nil
+}
+
+func (c *queryCache) get(query, page string) (*dynamodb.QueryOutput, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.queries[query][page]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.output, true
+}
+
+func (c *queryCache) put(query, page string, output *dynamodb.QueryOutput) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	if now.Sub(c.swept) > c.ttl {
+		c.sweep(now)
+	}
+
+	pages, ok := c.queries[query]
+	if !ok {
+		pages = map[string]queryCacheEntry{}
+		c.queries[query] = pages
+	}
+	pages[page] = queryCacheEntry{
+		output:  output,
+		expires: now.Add(c.ttl),
+	}
+}
+
+// sweep removes expired entries; callers must hold the mutex
+func (c *queryCache) sweep(now time.Time) {
+	for query, pages := range c.queries {
+		for page, entry := range pages {
+			if !now.Before(entry.expires) {
+				delete(pages, page)
+			}
+		}
+		if len(pages) == 0 {
+			delete(c.queries, query)
+		}
+	}
+	c.swept = now
+}
+
+func (c *queryCache) invalidate(query string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.queries, query)
+}
+
+func (c *queryCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.queries = map[string]map[string]queryCacheEntry{}
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type countingQueryMock struct {
+	*Mock
+	calls int
+}
+
+func (c *countingQueryMock) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	c.calls++
+	return c.Mock.QueryWithContext(ctx, input, opts...)
+}
+
+func TestTable_WithQueryCache(t *testing.T) {
+	var (
+		now   = time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+		mock  = &countingQueryMock{Mock: &Mock{queryItems: []interface{}{QueryExample{ID: "abc", Date: "1"}}, readUnits: 1}}
+		table = New(mock).MustTable("example", QueryExample{}).WithQueryCache(time.Second)
+	)
+	table.queryCache.now = func() time.Time { return now }
+
+	find := func(id string) {
+		var got []QueryExample
+		if err := table.Query("#ID = ?", id).FindAll(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(got), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	find("abc")
+	find("abc")
+	if got, want := mock.calls, 1; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := table.ConsumedCapacity().ReadUnits, int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	find("def")
+	if got, want := mock.calls, 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(time.Second)
+		find("abc")
+		if got, want := mock.calls, 3; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalidate query", func(t *testing.T) {
+		if err := table.Query("#ID = ?", "abc").InvalidateCache(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		find("abc")
+		if got, want := mock.calls, 4; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalidate all", func(t *testing.T) {
+		table.InvalidateQueryCache()
+		find("abc")
+		if got, want := mock.calls, 5; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DisableSDKRetries is a request option that disables the retries of the aws sdk for
+// use with WithRequestOptions when retries are left to ddb e.g.
+//
+//	db := ddb.New(api).WithRequestOptions(ddb.DisableSDKRetries).WithNetworkRetry(3, nil)
+//
+// Without a ddb retry policy in place, throttled and failed requests are not retried.
+func DisableSDKRetries(r *request.Request) {
+	r.Retryer = client.NoOpRetryer{}
+}
+
+// UseHTTPClient returns a request option that sends requests with httpClient for use
+// with WithRequestOptions e.g. to tune the connection pool used by ddb independently
+// of other clients sharing the aws session
+func UseHTTPClient(httpClient *http.Client) request.Option {
+	return func(r *request.Request) {
+		r.Config.HTTPClient = httpClient
+	}
+}
+
+// requestOptionsAPI decorates the dynamodb api, applying options to every call after
+// the options supplied by the caller
+type requestOptionsAPI struct {
+	dynamodbiface.DynamoDBAPI
+	options []request.Option
+}
+
+// newRequestOptionsAPI returns api decorated with options.  Options are appended to
+// those of api when it has already been decorated so they apply in the order given.
+func newRequestOptionsAPI(api dynamodbiface.DynamoDBAPI, options []request.Option) *requestOptionsAPI {
+	if existing, ok := api.(*requestOptionsAPI); ok {
+		merged := make([]request.Option, 0, len(existing.options)+len(options))
+		merged = append(merged, existing.options...)
+		merged = append(merged, options...)
+		return &requestOptionsAPI{DynamoDBAPI: existing.DynamoDBAPI, options: merged}
+	}
+	return &requestOptionsAPI{DynamoDBAPI: api, options: options}
+}
+
+func (r *requestOptionsAPI) with(opts []request.Option) []request.Option {
+	merged := make([]request.Option, 0, len(opts)+len(r.options))
+	merged = append(merged, opts...)
+	return append(merged, r.options...)
+}
+
+func (r *requestOptionsAPI) CreateTableWithContext(ctx aws.Context, input *dynamodb.CreateTableInput, opts ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	return r.DynamoDBAPI.CreateTableWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	return r.DynamoDBAPI.DeleteItemWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) DeleteTableWithContext(ctx aws.Context, input *dynamodb.DeleteTableInput, opts ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	return r.DynamoDBAPI.DeleteTableWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) DescribeEndpointsWithContext(ctx aws.Context, input *dynamodb.DescribeEndpointsInput, opts ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
+	return r.DynamoDBAPI.DescribeEndpointsWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return r.DynamoDBAPI.DescribeTableWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return r.DynamoDBAPI.GetItemWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	return r.DynamoDBAPI.PutItemWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	return r.DynamoDBAPI.QueryWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	return r.DynamoDBAPI.ScanWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	return r.DynamoDBAPI.TransactGetItemsWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	return r.DynamoDBAPI.TransactWriteItemsWithContext(ctx, input, r.with(opts)...)
+}
+
+func (r *requestOptionsAPI) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	return r.DynamoDBAPI.UpdateItemWithContext(ctx, input, r.with(opts)...)
+}
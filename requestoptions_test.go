@@ -0,0 +1,96 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// roundTripFunc implements http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestDDB_WithRequestOptions(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		calls int64
+		s, _  = session.NewSession(aws.NewConfig().
+			WithCredentials(credentials.NewStaticCredentials("blah", "blah", "")).
+			WithRegion("us-west-2").
+			WithEndpoint("http://localhost:8000"))
+		httpClient = &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt64(&calls, 1)
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+					Body:       ioutil.NopCloser(strings.NewReader(`{"__type":"InternalServerError","message":"boom"}`)),
+					Request:    req,
+				}, nil
+			}),
+		}
+		db = New(dynamodb.New(s)).
+			WithRequestOptions(UseHTTPClient(httpClient)).
+			WithRequestOptions(DisableSDKRetries)
+		table = db.MustTable("example", Example{})
+	)
+
+	var got Example
+	err := table.Get("abc").ScanWithContext(ctx, &got)
+	if err == nil {
+		t.Fatalf("got nil; want err")
+	}
+	if got, want := atomic.LoadInt64(&calls), int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRequestOptionsAPI(t *testing.T) {
+	var order []string
+	option := func(name string) request.Option {
+		return func(*request.Request) {
+			order = append(order, name)
+		}
+	}
+
+	var api *requestOptionsAPI
+	api = newRequestOptionsAPI(&Mock{}, []request.Option{option("a")})
+	api = newRequestOptionsAPI(api, []request.Option{option("b")})
+	if _, ok := api.DynamoDBAPI.(*Mock); !ok {
+		t.Fatalf("got %T; want *Mock", api.DynamoDBAPI)
+	}
+
+	var r request.Request
+	for _, opt := range api.with([]request.Option{option("caller")}) {
+		opt(&r)
+	}
+	if got, want := strings.Join(order, ","), "caller,a,b"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
@@ -17,12 +17,16 @@ package ddb
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
@@ -33,9 +37,14 @@ type Item interface {
 
 	// Unmarshal the record into the provided interface
 	Unmarshal(v interface{}) error
+
+	// UnmarshalInto unmarshals the record into each of the provided targets e.g. a
+	// keys struct and a payload struct backed by the same item
+	UnmarshalInto(targets ...interface{}) error
 }
 
 type baseItem struct {
+	ctx context.Context
 	raw map[string]*dynamodb.AttributeValue
 }
 
@@ -45,26 +54,80 @@ func (b baseItem) Raw() map[string]*dynamodb.AttributeValue {
 }
 
 func (b baseItem) Unmarshal(v interface{}) error {
-	return dynamodbattribute.UnmarshalMap(b.raw, v)
+	if err := unmarshalMap(b.raw, v); err != nil {
+		return err
+	}
+
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = defaultContext
+	}
+	return afterGet(ctx, v)
+}
+
+// UnmarshalInto implements Item
+func (b baseItem) UnmarshalInto(targets ...interface{}) error {
+	for _, v := range targets {
+		if err := b.Unmarshal(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemView returns a function that presents each raw item to a callback.  When reuse
+// is true, the same Item is returned for every raw item and is only valid until the
+// callback returns.
+func itemView(ctx context.Context, reuse bool) func(raw map[string]*dynamodb.AttributeValue) Item {
+	if !reuse {
+		return func(raw map[string]*dynamodb.AttributeValue) Item {
+			return baseItem{ctx: ctx, raw: raw}
+		}
+	}
+
+	view := &baseItem{ctx: ctx}
+	return func(raw map[string]*dynamodb.AttributeValue) Item {
+		view.raw = raw
+		return view
+	}
 }
 
-// Scan encapsulates a scan request
+// Scan encapsulates a scan request.  As with the other builders, a Scan must not be
+// modified once Each, First, or ScanInput has been called.
 type Scan struct {
-	api            dynamodbiface.DynamoDBAPI
-	spec           *tableSpec
-	consistentRead bool
-	request        *ConsumedCapacity
-	table          *ConsumedCapacity
-	debug          io.Writer
-	err            error
-	expr           *expression
-	indexName      string
-	totalSegments  int64
+	api                dynamodbiface.DynamoDBAPI
+	spec               *tableSpec
+	consistentRead     bool
+	request            *ConsumedCapacity
+	table              *ConsumedCapacity
+	capacity           string
+	debug              io.Writer
+	err                error
+	expr               *expression
+	indexName          string
+	lastEvaluatedKey   *map[string]*dynamodb.AttributeValue
+	lastEvaluatedToken *string
+	limit              int64
+	maxItems           int64
+	order              ScanOrder
+	queueSize          int
+	reuseItems         bool
+	selectAttributes   string
+	startKey           map[string]*dynamodb.AttributeValue
+	totalSegments      int64
+	workers            int
+	retry              retryPolicy
+	network            retryPolicy
+	modify             []func(*dynamodb.ScanInput)
+	noContext          contextFactory
+	stats              *Stats
+	clock              Clock  // clock times the request for Stats
+	counts             *Stats // counts, if set, accumulates the item counts of each page for Count
 }
 
 func (s *Scan) makeScanInput(segment, totalSegments int64, startKey map[string]*dynamodb.AttributeValue) *dynamodb.ScanInput {
 	var (
-		filterExpr = s.expr.ConditionExpression()
+		filterExpr = s.expr.FilterExpression()
 	)
 
 	input := dynamodb.ScanInput{
@@ -73,57 +136,119 @@ func (s *Scan) makeScanInput(segment, totalSegments int64, startKey map[string]*
 		ExpressionAttributeNames:  s.expr.Names,
 		ExpressionAttributeValues: s.expr.Values,
 		FilterExpression:          filterExpr,
-		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		ReturnConsumedCapacity:    returnConsumedCapacity(s.capacity),
 		Segment:                   aws.Int64(segment),
 		TableName:                 aws.String(s.spec.TableName),
-		TotalSegments:             aws.Int64(s.totalSegments),
+		TotalSegments:             aws.Int64(totalSegments),
 	}
 	if s.indexName != "" {
 		input.IndexName = aws.String(s.indexName)
 	}
+	if s.limit > 0 {
+		input.Limit = aws.Int64(s.limit)
+	}
+	if s.selectAttributes != "" {
+		input.Select = aws.String(s.selectAttributes)
+	}
+	for _, fn := range s.modify {
+		fn(&input)
+	}
 
 	return &input
 }
 
-func (s *Scan) scanSegment(ctx context.Context, segment, totalSegments int64, fn func(item Item) (bool, error)) (stop bool, err error) {
-	var startKey map[string]*dynamodb.AttributeValue
+// Modify registers a function to alter the ScanInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  The function is applied
+// to the input of every page of every segment.  When called multiple times, the
+// functions are applied in order.
+func (s *Scan) Modify(fn func(input *dynamodb.ScanInput)) *Scan {
+	s.modify = append(s.modify, fn)
+	return s
+}
+
+// ScanInput returns the input for the given segment of a scan split into totalSegments
+// segments; use 0 and 1 respectively for a sequential scan
+func (s *Scan) ScanInput(segment, totalSegments int64) (*dynamodb.ScanInput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if totalSegments <= 0 || segment < 0 || segment >= totalSegments {
+		return nil, fmt.Errorf("invalid scan segment, %v, of %v total segments", segment, totalSegments)
+	}
+
+	return s.makeScanInput(segment, totalSegments, s.startKey), nil
+}
+
+// scanSegment scans a single segment.  lastKey holds the key from which the segment may
+// be continued; following an error, the key of the last item successfully passed to fn.
+func (s *Scan) scanSegment(ctx context.Context, segment, totalSegments int64, fn func(item Item) (bool, error)) (stop bool, lastKey map[string]*dynamodb.AttributeValue, err error) {
+	var (
+		startKey = s.startKey
+		resume   = &resumePoint{}
+		view     = itemView(ctx, s.reuseItems)
+	)
+	resume.names, _ = s.spec.keyAttributes(s.indexName)
 
 	for {
+		resume.reset(startKey)
 		input := s.makeScanInput(segment, totalSegments, startKey)
-		output, err := s.api.ScanWithContext(ctx, input)
+
+		var (
+			output *dynamodb.ScanOutput
+			retry  bool
+		)
+		err := s.retry.do(ctx, func() error {
+			return s.network.do(ctx, func() (err error) {
+				s.stats.attempt(retry)
+				retry = true
+				output, err = s.api.ScanWithContext(ctx, input, s.stats.options()...)
+				return err
+			})
+		})
 		if err != nil {
-			return false, err
+			err = wrapAWSError(err, "Scan", s.spec, s.indexName, nil)
+			if isThrottleError(err) {
+				key := resume.key()
+				return false, key, newResumeError(err, ErrThrottled, s.spec.TableName, segment, key)
+			}
+			return false, resume.key(), err
 		}
 
 		s.table.add(output.ConsumedCapacity)
 		if s.request != nil {
 			s.request.add(output.ConsumedCapacity)
 		}
+		s.stats.add(output.Count, output.ScannedCount)
+		s.stats.consumed(output.ConsumedCapacity)
+		s.counts.add(output.Count, output.ScannedCount)
+
+		startKey = output.LastEvaluatedKey
 
-		var item baseItem
 		for _, rawItem := range output.Items {
-			item.raw = rawItem
-			ok, err := fn(item)
+			ok, err := fn(view(rawItem))
 			if err != nil {
-				return false, err
+				return false, resume.key(), err
 			}
+			resume.advance(rawItem)
 			if !ok {
-				return true, nil
+				return true, startKey, nil
 			}
 		}
 
-		startKey = output.LastEvaluatedKey
 		if startKey == nil {
 			break
 		}
+		if s.limit > 0 {
+			break
+		}
 	}
 
-	return false, nil
+	return false, startKey, nil
 }
 
 // ConsistentRead enables or disables consistent reading
 func (s *Scan) ConsistentRead(enabled bool) *Scan {
-	s.consistentRead = true
+	s.consistentRead = enabled
 	return s
 }
 
@@ -133,6 +258,20 @@ func (s *Scan) ConsumedCapacity(capture *ConsumedCapacity) *Scan {
 	return s
 }
 
+// Stats captures the attempts, pages, items scanned and returned, duration, and
+// consumed capacity of the scan to the property provided
+func (s *Scan) Stats(capture *Stats) *Scan {
+	s.stats = capture
+	return s
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (s *Scan) ReturnConsumedCapacity(v string) *Scan {
+	s.capacity = v
+	return s
+}
+
 // Debug dynamodb request
 func (s *Scan) Debug(w io.Writer) *Scan {
 	s.debug = w
@@ -142,13 +281,18 @@ func (s *Scan) Debug(w io.Writer) *Scan {
 // Each is identical to EachWithContext except that it does not allow for cancellation
 // via the context.
 func (s *Scan) Each(callback func(item Item) (bool, error)) error {
-	return s.EachWithContext(defaultContext, callback)
+	return s.EachWithContext(s.noContext.context("Scan.Each"), callback)
 }
 
 // EachWithContext iterates invokes the callback for each record that matches the scan.
 // So long as the callback returns `true, nil`, the scan will continue.  If the callback
 // either returns an error OR false, the scan will stop.  The scan will also stop if the
 // context has been canceled.
+//
+// When TotalSegments is greater than 1, the callback is invoked concurrently from one
+// goroutine per segment and must be safe for concurrent use unless Order is used to
+// serialize the callback.  Use Workers to process items from a bounded queue rather
+// than from the segment goroutines.
 func (s *Scan) EachWithContext(ctx context.Context, callback func(item Item) (bool, error)) error {
 	if s.err != nil {
 		return s.err
@@ -157,15 +301,53 @@ func (s *Scan) EachWithContext(ctx context.Context, callback func(item Item) (bo
 	if s.totalSegments == 0 {
 		s.totalSegments = 1
 	}
+	if s.startKey != nil && s.totalSegments > 1 {
+		return fmt.Errorf("StartKey may only be used with a sequential scan: got %v total segments", s.totalSegments)
+	}
+	if s.workers > 0 && s.order != ScanOrderAny {
+		return fmt.Errorf("Workers may not be combined with Order")
+	}
+	if s.workers > 0 && s.reuseItems {
+		return fmt.Errorf("Workers may not be combined with ReuseItems")
+	}
 
 	if s.debug != nil {
 		input := s.makeScanInput(0, s.totalSegments, nil)
 		_ = json.NewEncoder(s.debug).Encode(input)
 	}
 
+	defer s.stats.since(s.clock, s.clock.Now())
+
+	if s.maxItems > 0 {
+		callback = limitItems(s.maxItems, callback)
+	}
+
+	var (
+		buffer *keyBuffer
+		fn     = callback
+	)
+	switch s.order {
+	case ScanOrderSegment:
+		callback = serialize(callback)
+	case ScanOrderKey:
+		buffer = newKeyBuffer(s.spec, s.indexName)
+		callback = buffer.add
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	var queue *workQueue
+	if s.workers > 0 {
+		size := s.queueSize
+		if size <= 0 {
+			size = s.workers
+		}
+		queue = newWorkQueue(s.workers, size, callback)
+		callback = queue.push(ctx)
+	}
+
+	var stopped int32 // stopped is set once any segment stops the scan
 	errs := make(chan error, s.totalSegments)
 	wg := &sync.WaitGroup{}
 	wg.Add(int(s.totalSegments))
@@ -173,11 +355,17 @@ func (s *Scan) EachWithContext(ctx context.Context, callback func(item Item) (bo
 		go func(segment int64) {
 			defer wg.Done()
 
-			stop, err := s.scanSegment(ctx, segment, s.totalSegments, callback)
+			stop, lastKey, err := s.scanSegment(ctx, segment, s.totalSegments, callback)
 			if err != nil {
 				errs <- err
 			}
+			if s.totalSegments == 1 {
+				if e := s.setLastEvaluated(lastKey); e != nil && err == nil {
+					errs <- e
+				}
+			}
 			if stop {
+				atomic.StoreInt32(&stopped, 1)
 				cancel()
 			}
 		}(i)
@@ -185,25 +373,65 @@ func (s *Scan) EachWithContext(ctx context.Context, callback func(item Item) (bo
 	wg.Wait()
 	close(errs)
 
+	if queue != nil {
+		if err := queue.wait(); err != nil {
+			return err
+		}
+	}
+
 	for err := range errs {
+		if atomic.LoadInt32(&stopped) == 1 && isCanceled(err) {
+			continue // segments interrupted by the stop
+		}
 		return err
 	}
 
+	if buffer != nil {
+		return buffer.each(ctx, fn)
+	}
+
 	return nil
 }
 
+// Count returns the number of items matching the scan and the number of items scanned
+// to find them.  Pages are read with Select set to COUNT so no item data is returned.
+// Combine with TotalSegments to count large tables in parallel.
+func (s *Scan) Count(ctx context.Context) (count, scanned int64, err error) {
+	var counts Stats
+	selectAttributes, previous := s.selectAttributes, s.counts
+	s.selectAttributes, s.counts = dynamodb.SelectCount, &counts
+	defer func() { s.selectAttributes, s.counts = selectAttributes, previous }()
+
+	if err := s.EachWithContext(ctx, func(Item) (bool, error) { return true, nil }); err != nil {
+		return 0, 0, err
+	}
+	return counts.Count, counts.ScannedCount, nil
+}
+
 // Filter allows for the scan record to be conditionally filtered
 func (s *Scan) Filter(expr string, values ...interface{}) *Scan {
-	if err := s.expr.Condition(expr, values...); err != nil {
+	if err := s.expr.Filter(expr, values...); err != nil {
 		s.err = err
 	}
 
 	return s
 }
 
+// AttributeExists filters out items where the attribute, name, does not exist e.g. #Field
+func (s *Scan) AttributeExists(name string) *Scan {
+	expr, values := attributeFunc("attribute_exists", name)
+	return s.Filter(expr, values...)
+}
+
+// AttributeNotExists filters out items where the attribute, name, exists e.g. #Field
+func (s *Scan) AttributeNotExists(name string) *Scan {
+	expr, values := attributeFunc("attribute_not_exists", name)
+	return s.Filter(expr, values...)
+}
+
 // First returns the first scanned record
 func (s *Scan) First(v interface{}) error {
-	return s.FirstWithContext(defaultContext, v)
+	return s.FirstWithContext(s.noContext.context("Scan.First"), v)
 }
 
 // FirstWithContext returns the first scanned record and allows for cancellation
@@ -239,6 +467,134 @@ func (s *Scan) IndexName(indexName string) *Scan {
 	return s
 }
 
+// Limit the number of items evaluated per segment.  As with Query, only a single page
+// is read from each segment when a limit is set.
+func (s *Scan) Limit(limit int64) *Scan {
+	s.limit = limit
+	return s
+}
+
+// MaxItems stops the scan, across all segments, once n items have been passed to the
+// callback.  Unlike Limit, which bounds the items evaluated per request, MaxItems
+// bounds the items delivered.
+func (s *Scan) MaxItems(n int64) *Scan {
+	s.maxItems = n
+	return s
+}
+
+// limitItems returns a callback that passes at most n items to fn, reporting false once
+// the nth item has been passed.  Safe for concurrent use if fn is.
+func limitItems(n int64, fn func(item Item) (bool, error)) func(item Item) (bool, error) {
+	var delivered int64
+	return func(item Item) (bool, error) {
+		i := atomic.AddInt64(&delivered, 1)
+		if i > n {
+			return false, nil
+		}
+		ok, err := fn(item)
+		return ok && i < n, err
+	}
+}
+
+// isCanceled returns true if err was caused by a canceled context
+func isCanceled(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+	var ae awserr.Error
+	return errors.As(err, &ae) && ae.Code() == request.CanceledErrorCode
+}
+
+// Order determines the order in which items from a parallel scan are passed to the
+// callback; defaults to ScanOrderAny
+func (s *Scan) Order(order ScanOrder) *Scan {
+	s.order = order
+	return s
+}
+
+// Select attributes to return e.g. dynamodb.SelectCount; defaults to dynamodb.SelectAllAttributes
+func (s *Scan) Select(v string) *Scan {
+	s.selectAttributes = v
+	return s
+}
+
+// setLastEvaluated stores the last evaluated key into the captures, if any
+func (s *Scan) setLastEvaluated(lastKey map[string]*dynamodb.AttributeValue) error {
+	if s.lastEvaluatedKey != nil {
+		*s.lastEvaluatedKey = lastKey
+	}
+	if s.lastEvaluatedToken != nil {
+		token, err := encodeStartToken(lastKey)
+		if err != nil {
+			return err
+		}
+		*s.lastEvaluatedToken = token
+	}
+	return nil
+}
+
+// LastEvaluatedKey stores the last evaluated key of a sequential scan into the provided
+// value.  If Each returns an error, the key of the last item successfully passed to the
+// callback is stored instead so the scan may be resumed via StartKey.  Not set when
+// TotalSegments is greater than 1.
+func (s *Scan) LastEvaluatedKey(lastEvaluatedKey *map[string]*dynamodb.AttributeValue) *Scan {
+	s.lastEvaluatedKey = lastEvaluatedKey
+	return s
+}
+
+// LastEvaluatedToken stores the last evaluated key of a sequential scan as a base64
+// encoded string suitable for StartToken.  See LastEvaluatedKey.
+func (s *Scan) LastEvaluatedToken(lastEvaluatedToken *string) *Scan {
+	s.lastEvaluatedToken = lastEvaluatedToken
+	return s
+}
+
+// StartKey assigns the continuation key from which a sequential scan resumes e.g.
+// ResumeError.ResumeKey.  StartKey may not be combined with TotalSegments.
+func (s *Scan) StartKey(startKey map[string]*dynamodb.AttributeValue) *Scan {
+	s.startKey = startKey
+	return s
+}
+
+// StartToken assigns the continuation key from a base64 encoded string e.g.
+// ResumeError.ResumeToken
+func (s *Scan) StartToken(token string) *Scan {
+	startKey, err := decodeStartToken(token)
+	if err != nil {
+		s.err = err
+		return s
+	}
+
+	return s.StartKey(startKey)
+}
+
+// Workers processes items using n goroutines fed by a bounded queue rather than invoking
+// the callback from each segment goroutine.  Segments block while the queue is full so
+// read concurrency, TotalSegments, and processing concurrency may be tuned
+// independently.  The callback must be safe for concurrent use when n > 1.  May not be
+// combined with Order.
+func (s *Scan) Workers(n int) *Scan {
+	s.workers = n
+	return s
+}
+
+// ReuseItems enables or disables passing the same Item to every invocation of the
+// callback within a segment.  This avoids an allocation per item for high throughput
+// jobs such as exports.  The Item, and the map returned by its Raw method, are only
+// valid until the callback returns and must not be retained.  May not be combined with
+// Workers.
+func (s *Scan) ReuseItems(enabled bool) *Scan {
+	s.reuseItems = enabled
+	return s
+}
+
+// QueueSize bounds the number of items read but not yet processed when Workers is set;
+// defaults to the number of workers
+func (s *Scan) QueueSize(n int) *Scan {
+	s.queueSize = n
+	return s
+}
+
 // TotalSegments allows for the Scan operation to run in parallel.  If not set, defaults
 // to 1 segment
 func (s *Scan) TotalSegments(n int64) *Scan {
@@ -249,9 +605,14 @@ func (s *Scan) TotalSegments(n int64) *Scan {
 // Scan initiates the scan operation
 func (t *Table) Scan() *Scan {
 	return &Scan{
-		api:   t.ddb.api,
-		table: t.consumed,
-		expr:  newExpression(t.spec.Attributes...),
-		spec:  t.spec,
+		api:       t.ddb.api,
+		table:     t.consumed,
+		capacity:  t.ddb.capacity,
+		noContext: t.ddb.noContext,
+		expr:      t.newExpression(),
+		spec:      t.spec,
+		retry:     t.ddb.pageRetry.withClock(t.ddb.clock),
+		network:   t.ddb.netRetry.withClock(t.ddb.clock),
+		clock:     t.ddb.clock,
 	}
 }
@@ -16,14 +16,18 @@ package ddb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
@@ -148,14 +152,58 @@ func TestScan_Condition(t *testing.T) {
 			table = db.MustTable("example", ScanTable{})
 		)
 
-		input := table.Scan().
+		input, err := table.Scan().
 			Filter("#ID = ?", "abc").
-			makeScanInput(0, 1, nil)
+			ScanInput(0, 1)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
 
 		assertEqual(t, input, "testdata/scan_condition.json")
 	})
 }
 
+func TestScan_SelectLimit(t *testing.T) {
+	var (
+		mock  = &Mock{}
+		db    = New(mock)
+		table = db.MustTable("example", ScanTable{})
+	)
+
+	input, err := table.Scan().
+		Filter("#ID = ?", "abc").
+		Select(dynamodb.SelectCount).
+		Limit(10).
+		ScanInput(0, 1)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	assertEqual(t, input, "testdata/scan_select_limit.json")
+}
+
+func TestScan_ScanInput(t *testing.T) {
+	table := New(&Mock{}).MustTable("example", ScanTable{})
+
+	input, err := table.Scan().ScanInput(2, 4)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.Int64Value(input.Segment), int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.Int64Value(input.TotalSegments), int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if _, err := table.Scan().ScanInput(4, 4); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+	if _, err := table.Scan().Filter("#? = ?", "ID").ScanInput(0, 1); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+}
+
 func TestScan_IndexName(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		var (
@@ -164,9 +212,12 @@ func TestScan_IndexName(t *testing.T) {
 			table = db.MustTable("example", ScanTable{})
 		)
 
-		input := table.Scan().
+		input, err := table.Scan().
 			IndexName("gsi").
-			makeScanInput(0, 1, nil)
+			ScanInput(0, 1)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
 
 		assertEqual(t, input, "testdata/scan_index.json")
 	})
@@ -254,6 +305,12 @@ func TestScan_ConsistentRead(t *testing.T) {
 	if !*input.ConsistentRead {
 		t.Fatalf("got false; want true")
 	}
+
+	s.ConsistentRead(false)
+	input = s.makeScanInput(1, 2, nil)
+	if *input.ConsistentRead {
+		t.Fatalf("got true; want false")
+	}
 }
 
 func TestScan_ConsumedCapacity(t *testing.T) {
@@ -283,3 +340,311 @@ func TestScan_ConsumedCapacity(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, want)
 	}
 }
+
+func TestBaseItem_UnmarshalInto(t *testing.T) {
+	type Keys struct {
+		PK string
+		SK string
+	}
+	type Payload struct {
+		Name string
+	}
+
+	raw := map[string]*dynamodb.AttributeValue{
+		"PK":   {S: aws.String("user#abc")},
+		"SK":   {S: aws.String("profile")},
+		"Name": {S: aws.String("name")},
+	}
+
+	var (
+		keys    Keys
+		payload Payload
+	)
+	if err := (baseItem{raw: raw}).UnmarshalInto(&keys, &payload); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := keys, (Keys{PK: "user#abc", SK: "profile"}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := payload, (Payload{Name: "name"}); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := (baseItem{raw: raw}).UnmarshalInto(keys); err == nil {
+		t.Fatalf("got nil; want not nil")
+	}
+}
+
+func TestScan_ResumeAfterError(t *testing.T) {
+	var (
+		mock  = &Mock{scanItems: []interface{}{ScanTable{ID: "abc"}, ScanTable{ID: "def"}}}
+		table = New(mock).MustTable("example", ScanTable{})
+		boom  = errors.New("boom")
+	)
+
+	var lastKey map[string]*dynamodb.AttributeValue
+	err := table.Scan().
+		LastEvaluatedKey(&lastKey).
+		Each(func(item Item) (bool, error) {
+			var v ScanTable
+			if err := item.Unmarshal(&v); err != nil {
+				return false, err
+			}
+			if v.ID == "def" {
+				return false, boom
+			}
+			return true, nil
+		})
+	if err != boom {
+		t.Fatalf("got %v; want %v", err, boom)
+	}
+
+	// the mock returns one item per page so the scan resumes from the start of the
+	// failed page, the last evaluated key of the first page
+	want := map[string]*dynamodb.AttributeValue{
+		"blah": {S: aws.String("blah")},
+	}
+	if !reflect.DeepEqual(lastKey, want) {
+		t.Fatalf("got %v; want %v", lastKey, want)
+	}
+}
+
+// blockingSegmentMock blocks reads of the final segment until the context is canceled
+type blockingSegmentMock struct {
+	*segmentMock
+	blocked int64
+}
+
+func (m *blockingSegmentMock) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	if aws.Int64Value(input.Segment) == m.blocked {
+		<-ctx.Done()
+		return nil, awserr.New(request.CanceledErrorCode, "canceled", ctx.Err())
+	}
+	return m.segmentMock.ScanWithContext(ctx, input, opts...)
+}
+
+func TestScan_MaxItems(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		var (
+			table = New(newSegmentMock()).MustTable("example", OrderExample{})
+			count int32
+		)
+
+		err := table.Scan().
+			TotalSegments(3).
+			MaxItems(4).
+			Each(func(item Item) (bool, error) {
+				atomic.AddInt32(&count, 1)
+				return true, nil
+			})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := count, int32(4); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("interrupts other segments", func(t *testing.T) {
+		var (
+			mock  = &blockingSegmentMock{segmentMock: newSegmentMock(), blocked: 2}
+			table = New(mock).MustTable("example", OrderExample{})
+			count int32
+		)
+
+		err := table.Scan().
+			TotalSegments(3).
+			MaxItems(2).
+			Each(func(item Item) (bool, error) {
+				atomic.AddInt32(&count, 1)
+				return true, nil
+			})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := count, int32(2); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestScan_Modify(t *testing.T) {
+	var (
+		mock  = &Mock{}
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	err := table.Scan().
+		Modify(func(input *dynamodb.ScanInput) {
+			input.ReturnConsumedCapacity = aws.String(dynamodb.ReturnConsumedCapacityIndexes)
+		}).
+		Each(func(Item) (bool, error) { return true, nil })
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(mock.scanInput.ReturnConsumedCapacity), dynamodb.ReturnConsumedCapacityIndexes; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestScan_Count(t *testing.T) {
+	var (
+		mock  = &countMock{count: 3, scannedCount: 10}
+		table = New(mock).MustTable("example", Example{})
+		stats Stats
+	)
+
+	count, scanned, err := table.Scan().TotalSegments(4).Stats(&stats).Count(context.Background())
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := count, int64(12); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := scanned, int64(40); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats.Pages, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for _, got := range mock.selects {
+		if want := dynamodb.SelectCount; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		scan := table.Scan()
+		if _, _, err := scan.Count(context.Background()); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		mock.selects = nil
+		if err := scan.EachWithContext(context.Background(), func(Item) (bool, error) { return true, nil }); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := mock.selects, []string{""}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if scan.counts != nil {
+			t.Fatalf("got %v; want nil", scan.counts)
+		}
+	})
+
+	t.Run("err", func(t *testing.T) {
+		if _, _, err := table.Scan().Filter("#ID = ? and #Name = ?", "x").Count(context.Background()); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func BenchmarkScan_Each(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	// pageMock ignores the segment so each segment reads every page
+	testCases := []struct {
+		segments  int64
+		workers   int
+		queueSize int
+	}{
+		{segments: 1},
+		{segments: 4},
+		{segments: 4, workers: 4},
+		{segments: 4, workers: 4, queueSize: 100},
+	}
+	for _, tc := range testCases {
+		segments := tc.segments
+		name := fmt.Sprintf("segments=%v/workers=%v/queue=%v", tc.segments, tc.workers, tc.queueSize)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var count int64
+				scan := table.Scan().TotalSegments(segments)
+				if tc.workers > 0 {
+					scan = scan.Workers(tc.workers).QueueSize(tc.queueSize)
+				}
+				err := scan.EachWithContext(ctx, func(item Item) (bool, error) {
+					var v Example
+					if err := item.Unmarshal(&v); err != nil {
+						return false, err
+					}
+					atomic.AddInt64(&count, 1)
+					return true, nil
+				})
+				if err != nil {
+					b.Fatalf("got %v; want nil", err)
+				}
+				if want := 1000 * segments; count != want {
+					b.Fatalf("got %v; want %v", count, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScan_ReuseItems(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(2, 2)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	t.Run("reused", func(t *testing.T) {
+		var (
+			first Item
+			count int
+		)
+		err := table.Scan().ReuseItems(true).EachWithContext(ctx, func(item Item) (bool, error) {
+			if first == nil {
+				first = item
+			}
+			if item != first {
+				t.Fatalf("got distinct items; want the same item reused")
+			}
+			count++
+			return true, nil
+		})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if count != 4 {
+			t.Fatalf("got %v; want 4", count)
+		}
+	})
+
+	t.Run("workers", func(t *testing.T) {
+		err := table.Scan().ReuseItems(true).Workers(2).EachWithContext(ctx, func(item Item) (bool, error) {
+			return true, nil
+		})
+		if err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
+
+func BenchmarkScan_EachReuse(b *testing.B) {
+	var (
+		ctx   = context.Background()
+		mock  = newPageMock(10, 100)
+		table = New(mock).MustTable("example", Example{})
+	)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var count int
+		err := table.Scan().ReuseItems(true).EachWithContext(ctx, func(item Item) (bool, error) {
+			count++
+			return true, nil
+		})
+		if err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+		if count != 1000 {
+			b.Fatalf("got %v; want 1000", count)
+		}
+	}
+}
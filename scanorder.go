@@ -0,0 +1,145 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ScanOrder determines the order in which a parallel scan passes items to the callback
+type ScanOrder int
+
+const (
+	// ScanOrderAny invokes the callback concurrently from each segment.  This is the default.
+	ScanOrderAny ScanOrder = iota
+	// ScanOrderSegment invokes the callback from one segment at a time.  Segments are
+	// interleaved, but the items of each segment are passed in the order read.
+	ScanOrderSegment
+	// ScanOrderKey buffers every item and, once all segments are read, passes them in
+	// ascending key order; by index key then primary key when scanning an index.  All
+	// items are held in memory so ScanOrderKey is best suited to bounded exports.
+	ScanOrderKey
+)
+
+// serialize returns a callback that invokes fn from at most one goroutine at a time
+func serialize(fn func(item Item) (bool, error)) func(item Item) (bool, error) {
+	var mutex sync.Mutex
+	return func(item Item) (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return fn(item)
+	}
+}
+
+// keyBuffer accumulates scanned items so they may be replayed in key order
+type keyBuffer struct {
+	mutex sync.Mutex
+	names []string
+	items []map[string]*dynamodb.AttributeValue
+}
+
+// newKeyBuffer returns a keyBuffer that orders items by the keys of the named index, if
+// any, followed by the keys of the table
+func newKeyBuffer(spec *tableSpec, indexName string) *keyBuffer {
+	var keys []*keySpec
+	if index := spec.index(indexName); index != nil {
+		keys = append(keys, index.HashKey, index.RangeKey)
+	}
+	keys = append(keys, spec.HashKey, spec.RangeKey)
+
+	var names []string
+loop:
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		for _, name := range names {
+			if name == key.AttributeName {
+				continue loop
+			}
+		}
+		names = append(names, key.AttributeName)
+	}
+
+	return &keyBuffer{names: names}
+}
+
+// add is a scan callback that buffers the item
+func (k *keyBuffer) add(item Item) (bool, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.items = append(k.items, item.Raw())
+	return true, nil
+}
+
+// each sorts the buffered items and passes them to fn
+func (k *keyBuffer) each(ctx context.Context, fn func(item Item) (bool, error)) error {
+	sort.SliceStable(k.items, func(i, j int) bool {
+		for _, name := range k.names {
+			if c := compareAttributeValues(k.items[i][name], k.items[j][name]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	item := baseItem{ctx: ctx}
+	for _, raw := range k.items {
+		item.raw = raw
+		ok, err := fn(item)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// compareAttributeValues compares scalar attribute values returning -1, 0, or 1.  Missing
+// values sort first.
+func compareAttributeValues(a, b *dynamodb.AttributeValue) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.S != nil && b.S != nil:
+		return strings.Compare(*a.S, *b.S)
+	case a.N != nil && b.N != nil:
+		x, _, errX := big.ParseFloat(*a.N, 10, 128, big.ToNearestEven)
+		y, _, errY := big.ParseFloat(*b.N, 10, 128, big.ToNearestEven)
+		if errX != nil || errY != nil {
+			return strings.Compare(*a.N, *b.N)
+		}
+		return x.Cmp(y)
+	case a.B != nil && b.B != nil:
+		return bytes.Compare(a.B, b.B)
+	default:
+		return 0
+	}
+}
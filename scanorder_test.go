@@ -0,0 +1,188 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type OrderExample struct {
+	ID  string `ddb:"hash"`
+	Seq int    `ddb:"range"`
+}
+
+// segmentMock returns the items assigned to each segment, one item per page
+type segmentMock struct {
+	*Mock
+	segments map[int64][]OrderExample
+}
+
+func (m *segmentMock) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	var (
+		items = m.segments[aws.Int64Value(input.Segment)]
+		index int
+	)
+	if v, ok := input.ExclusiveStartKey["index"]; ok {
+		index, _ = strconv.Atoi(aws.StringValue(v.N))
+	}
+
+	item, err := marshalMap(items[index])
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}
+	if index+1 < len(items) {
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{
+			"index": {N: aws.String(strconv.Itoa(index + 1))},
+		}
+	}
+	return output, nil
+}
+
+func newSegmentMock() *segmentMock {
+	return &segmentMock{
+		Mock: &Mock{},
+		segments: map[int64][]OrderExample{
+			0: {{ID: "b", Seq: 10}, {ID: "a", Seq: 2}, {ID: "c", Seq: 1}},
+			1: {{ID: "a", Seq: 10}, {ID: "b", Seq: 9}},
+			2: {{ID: "a", Seq: 9}},
+		},
+	}
+}
+
+func TestScan_Order(t *testing.T) {
+	t.Run("segment", func(t *testing.T) {
+		var (
+			mock   = newSegmentMock()
+			table  = New(mock).MustTable("example", OrderExample{})
+			active int32
+			got    = map[int64][]OrderExample{}
+		)
+
+		segmentOf := map[OrderExample]int64{}
+		for segment, items := range mock.segments {
+			for _, item := range items {
+				segmentOf[item] = segment
+			}
+		}
+
+		err := table.Scan().
+			TotalSegments(3).
+			Order(ScanOrderSegment).
+			Each(func(item Item) (bool, error) {
+				if n := atomic.AddInt32(&active, 1); n != 1 {
+					t.Errorf("got %v concurrent callbacks; want 1", n)
+				}
+				defer atomic.AddInt32(&active, -1)
+				time.Sleep(time.Millisecond)
+
+				var v OrderExample
+				if err := item.Unmarshal(&v); err != nil {
+					return false, err
+				}
+				segment := segmentOf[v]
+				got[segment] = append(got[segment], v)
+				return true, nil
+			})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !reflect.DeepEqual(got, mock.segments) {
+			t.Fatalf("got %v; want %v", got, mock.segments)
+		}
+	})
+
+	t.Run("key", func(t *testing.T) {
+		var (
+			table = New(newSegmentMock()).MustTable("example", OrderExample{})
+			got   []OrderExample
+		)
+
+		err := table.Scan().
+			TotalSegments(3).
+			Order(ScanOrderKey).
+			Each(func(item Item) (bool, error) {
+				var v OrderExample
+				if err := item.Unmarshal(&v); err != nil {
+					return false, err
+				}
+				got = append(got, v)
+				return len(got) < 5, nil
+			})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		want := []OrderExample{
+			{ID: "a", Seq: 2},
+			{ID: "a", Seq: 9},
+			{ID: "a", Seq: 10},
+			{ID: "b", Seq: 9},
+			{ID: "b", Seq: 10},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func Test_compareAttributeValues(t *testing.T) {
+	testCases := map[string]struct {
+		A, B *dynamodb.AttributeValue
+		Want int
+	}{
+		"nil": {
+			B:    &dynamodb.AttributeValue{S: aws.String("a")},
+			Want: -1,
+		},
+		"string": {
+			A:    &dynamodb.AttributeValue{S: aws.String("b")},
+			B:    &dynamodb.AttributeValue{S: aws.String("a")},
+			Want: 1,
+		},
+		"number": {
+			A:    &dynamodb.AttributeValue{N: aws.String("9")},
+			B:    &dynamodb.AttributeValue{N: aws.String("10")},
+			Want: -1,
+		},
+		"decimal": {
+			A:    &dynamodb.AttributeValue{N: aws.String("1.50")},
+			B:    &dynamodb.AttributeValue{N: aws.String("1.5")},
+			Want: 0,
+		},
+		"binary": {
+			A:    &dynamodb.AttributeValue{B: []byte{1, 2}},
+			B:    &dynamodb.AttributeValue{B: []byte{1}},
+			Want: 1,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got, want := compareAttributeValues(tc.A, tc.B), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"sync"
+)
+
+// workQueue decouples reading scan segments from processing items.  Segments push
+// items onto a bounded queue and block while it is full; a fixed number of workers
+// drain the queue, invoking the callback.
+type workQueue struct {
+	items chan Item
+	done  chan struct{} // done is closed once a worker stops the scan
+	once  sync.Once
+	wg    sync.WaitGroup
+	err   error // err holds the first error returned by the callback
+}
+
+func newWorkQueue(workers, size int, fn func(item Item) (bool, error)) *workQueue {
+	w := &workQueue{
+		items: make(chan Item, size),
+		done:  make(chan struct{}),
+	}
+
+	w.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer w.wg.Done()
+
+			for item := range w.items {
+				if w.stopped() {
+					continue // drain the queue so segments are not blocked
+				}
+
+				ok, err := fn(item)
+				if err != nil {
+					w.stop(err)
+				} else if !ok {
+					w.stop(nil)
+				}
+			}
+		}()
+	}
+
+	return w
+}
+
+func (w *workQueue) stop(err error) {
+	w.once.Do(func() {
+		w.err = err
+		close(w.done)
+	})
+}
+
+func (w *workQueue) stopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// push returns a callback that enqueues items, blocking while the queue is full.  The
+// callback returns false once the scan has been stopped by a worker.
+func (w *workQueue) push(ctx context.Context) func(item Item) (bool, error) {
+	return func(item Item) (bool, error) {
+		select {
+		case w.items <- item:
+			return true, nil
+		case <-w.done:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// wait closes the queue once all segments have been read, waits for the workers to
+// finish, and returns the first error returned by the callback
+func (w *workQueue) wait() error {
+	close(w.items)
+	w.wg.Wait()
+	return w.err
+}
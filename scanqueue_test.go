@@ -0,0 +1,129 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// countingScanMock counts the pages read
+type countingScanMock struct {
+	*segmentMock
+	reads int32
+}
+
+func (m *countingScanMock) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	atomic.AddInt32(&m.reads, 1)
+	return m.segmentMock.ScanWithContext(ctx, input, opts...)
+}
+
+func TestScan_Workers(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		var (
+			table         = New(newSegmentMock()).MustTable("example", OrderExample{})
+			active, count int32
+		)
+
+		err := table.Scan().
+			TotalSegments(3).
+			Workers(2).
+			Each(func(item Item) (bool, error) {
+				if n := atomic.AddInt32(&active, 1); n > 2 {
+					t.Errorf("got %v concurrent callbacks; want at most 2", n)
+				}
+				defer atomic.AddInt32(&active, -1)
+				time.Sleep(time.Millisecond)
+
+				atomic.AddInt32(&count, 1)
+				return true, nil
+			})
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := count, int32(6); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("back-pressure", func(t *testing.T) {
+		var (
+			mock    = &countingScanMock{segmentMock: newSegmentMock()}
+			table   = New(mock).MustTable("example", OrderExample{})
+			release = make(chan struct{})
+			done    = make(chan error, 1)
+		)
+
+		go func() {
+			done <- table.Scan().
+				TotalSegments(3).
+				Workers(1).
+				QueueSize(1).
+				Each(func(item Item) (bool, error) {
+					<-release
+					return true, nil
+				})
+		}()
+
+		// at most 1 item processing, 1 queued, and 1 held by each segment so the
+		// final page may not be read until the callback is released
+		time.Sleep(50 * time.Millisecond)
+		if got, want := atomic.LoadInt32(&mock.reads), int32(6); got >= want {
+			t.Fatalf("got %v reads; want less than %v", got, want)
+		}
+
+		close(release)
+		if err := <-done; err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := atomic.LoadInt32(&mock.reads), int32(6); got != want {
+			t.Fatalf("got %v reads; want %v", got, want)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		var (
+			table = New(newSegmentMock()).MustTable("example", OrderExample{})
+			boom  = errors.New("boom")
+		)
+
+		err := table.Scan().
+			TotalSegments(3).
+			Workers(2).
+			Each(func(item Item) (bool, error) {
+				return false, boom
+			})
+		if err != boom {
+			t.Fatalf("got %v; want %v", err, boom)
+		}
+	})
+
+	t.Run("with order", func(t *testing.T) {
+		table := New(newSegmentMock()).MustTable("example", OrderExample{})
+		err := table.Scan().
+			Workers(2).
+			Order(ScanOrderKey).
+			Each(func(item Item) (bool, error) { return true, nil })
+		if err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}
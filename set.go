@@ -2,6 +2,7 @@ package ddb
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strconv"
 
@@ -121,3 +122,83 @@ func (ss *StringSet) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeVal
 	*ss = vv
 	return nil
 }
+
+// makeSet returns values as a StringSet or Int64Set.  values may either be individual
+// strings or integers, or a single StringSet, Int64Set, []string, or []int64.
+func makeSet(values []interface{}) (interface{}, error) {
+	if len(values) == 1 {
+		switch v := values[0].(type) {
+		case StringSet:
+			return v, nil
+		case []string:
+			return StringSet(v), nil
+		case Int64Set:
+			return v, nil
+		case []int64:
+			return Int64Set(v), nil
+		}
+	}
+
+	var (
+		ss StringSet
+		ii Int64Set
+	)
+	for _, value := range values {
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.String:
+			ss = append(ss, v.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			ii = append(ii, v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			ii = append(ii, int64(v.Uint()))
+		default:
+			return nil, fmt.Errorf("unable to add %T to set: values must be strings or integers", value)
+		}
+	}
+
+	switch {
+	case len(ss) > 0 && len(ii) > 0:
+		return nil, fmt.Errorf("unable to make set: values must either be all strings or all integers")
+	case len(ii) > 0:
+		return ii, nil
+	default:
+		return ss, nil
+	}
+}
+
+// setLen returns the number of elements in a set returned by makeSet
+func setLen(set interface{}) int {
+	return reflect.ValueOf(set).Len()
+}
+
+// AddToSet adds values to the set attribute identified by name e.g.
+//
+//	AddToSet("#Tags", "red", "blue")
+//
+// generates ADD #Tags :v where :v is the string set, [red, blue].  values may either
+// be strings or integers, or a single StringSet or Int64Set.  The set is created if it
+// does not exist.  As DynamoDB does not permit empty sets, no values is a no-op.
+func (u *Update) AddToSet(name string, values ...interface{}) *Update {
+	set, err := makeSet(values)
+	if err != nil {
+		u.err = err
+		return u
+	}
+	if setLen(set) == 0 {
+		return u
+	}
+	return u.Add(name+" ?", set)
+}
+
+// RemoveFromSet removes values from the set attribute identified by name; see AddToSet
+func (u *Update) RemoveFromSet(name string, values ...interface{}) *Update {
+	set, err := makeSet(values)
+	if err != nil {
+		u.err = err
+		return u
+	}
+	if setLen(set) == 0 {
+		return u
+	}
+	return u.Delete(name+" ?", set)
+}
@@ -63,3 +63,59 @@ func TestContainsRegexp(t *testing.T) {
 		t.Fatalf("got %v; want %v", got, false)
 	}
 }
+
+func TestUpdate_AddToSet(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+
+	t.Run("strings", func(t *testing.T) {
+		input, err := table.Update("abc").Range("def").
+			AddToSet("#Tags", "red", "blue").
+			RemoveFromSet("#Old", StringSet{"green"}).
+			UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := *input.UpdateExpression, "Add #n1 :v1 Delete #n2 :v2"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(input.ExpressionAttributeValues[":v1"].SS), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := *input.ExpressionAttributeValues[":v2"].SS[0], "green"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("integers", func(t *testing.T) {
+		input, err := table.Update("abc").Range("def").
+			AddToSet("#Scores", 1, int64(2)).
+			UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(input.ExpressionAttributeValues[":v1"].NS), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		input, err := table.Update("abc").Range("def").
+			Set("#a = ?", "x").
+			AddToSet("#Tags").
+			RemoveFromSet("#Tags", []string{}).
+			UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := *input.UpdateExpression, "Set #n1 = :v1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		_, err := table.Update("abc").Range("def").AddToSet("#Tags", "a", 1).UpdateItemInput()
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
@@ -0,0 +1,231 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Logger is satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// slowLogAPI decorates the dynamodb api, logging calls that take longer than threshold
+type slowLogAPI struct {
+	dynamodbiface.DynamoDBAPI
+	threshold time.Duration
+	logger    Logger
+	clock     Clock // clock times each call
+}
+
+func newSlowLogAPI(api dynamodbiface.DynamoDBAPI, threshold time.Duration, logger Logger, clock Clock) *slowLogAPI {
+	return &slowLogAPI{
+		DynamoDBAPI: api,
+		threshold:   threshold,
+		logger:      logger,
+		clock:       clock,
+	}
+}
+
+// slowCall describes a completed dynamodb call
+type slowCall struct {
+	operation string
+	tableName *string
+	expr      []*string // expr holds the key condition, filter, update, and condition expressions
+	count     int64
+	capacity  *dynamodb.ConsumedCapacity
+	err       error
+}
+
+func (s *slowLogAPI) log(started time.Time, call slowCall) {
+	elapsed := s.clock.Now().Sub(started)
+	if elapsed <= s.threshold {
+		return
+	}
+
+	var summary []string
+	for _, expr := range call.expr {
+		if v := aws.StringValue(expr); v != "" {
+			summary = append(summary, v)
+		}
+	}
+
+	capacity := "-"
+	if c := call.capacity; c != nil && c.CapacityUnits != nil {
+		capacity = strconv.FormatFloat(*c.CapacityUnits, 'f', -1, 64)
+	}
+
+	s.logger.Printf("ddb: slow %v on %v took %v; expr=%q items=%v capacity=%v err=%v",
+		call.operation,
+		aws.StringValue(call.tableName),
+		elapsed,
+		strings.Join(summary, "; "),
+		call.count,
+		capacity,
+		call.err,
+	)
+}
+
+func (s *slowLogAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.DeleteItemWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "DeleteItem",
+		tableName: input.TableName,
+		expr:      []*string{input.ConditionExpression},
+		count:     1,
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.GetItemWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "GetItem",
+		tableName: input.TableName,
+		expr:      []*string{input.ProjectionExpression},
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+		if output.Item != nil {
+			call.count = 1
+		}
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.PutItemWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "PutItem",
+		tableName: input.TableName,
+		expr:      []*string{input.ConditionExpression},
+		count:     1,
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.QueryWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "Query",
+		tableName: input.TableName,
+		expr:      []*string{input.KeyConditionExpression, input.FilterExpression},
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+		call.count = aws.Int64Value(output.Count)
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.ScanWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "Scan",
+		tableName: input.TableName,
+		expr:      []*string{input.FilterExpression},
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+		call.count = aws.Int64Value(output.Count)
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.UpdateItemWithContext(ctx, input, opts...)
+
+	call := slowCall{
+		operation: "UpdateItem",
+		tableName: input.TableName,
+		expr:      []*string{input.UpdateExpression, input.ConditionExpression},
+		count:     1,
+		err:       err,
+	}
+	if output != nil {
+		call.capacity = output.ConsumedCapacity
+	}
+	s.log(started, call)
+
+	return output, err
+}
+
+func (s *slowLogAPI) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.TransactGetItemsWithContext(ctx, input, opts...)
+
+	s.log(started, slowCall{
+		operation: "TransactGetItems",
+		tableName: aws.String("-"),
+		count:     int64(len(input.TransactItems)),
+		err:       err,
+	})
+
+	return output, err
+}
+
+func (s *slowLogAPI) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	started := s.clock.Now()
+	output, err := s.DynamoDBAPI.TransactWriteItemsWithContext(ctx, input, opts...)
+
+	s.log(started, slowCall{
+		operation: "TransactWriteItems",
+		tableName: aws.String("-"),
+		count:     int64(len(input.TransactItems)),
+		err:       err,
+	})
+
+	return output, err
+}
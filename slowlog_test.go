@@ -0,0 +1,71 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (b *bufferLogger) Printf(format string, v ...interface{}) {
+	b.lines = append(b.lines, fmt.Sprintf(format, v...))
+}
+
+func TestDDB_WithSlowLogThreshold(t *testing.T) {
+	var (
+		logger = &bufferLogger{}
+		mock   = &Mock{queryItems: []interface{}{QueryExample{ID: "abc", Date: "1"}}, readUnits: 1}
+		clock  = &fakeClock{now: time.Unix(1600000000, 0)}
+		db     = New(mock).WithClock(clock).WithSlowLogThreshold(time.Second, logger)
+		table  = db.MustTable("example", QueryExample{})
+	)
+
+	t.Run("fast", func(t *testing.T) {
+		clock.step = time.Millisecond
+
+		var got []QueryExample
+		if err := table.Query("#ID = ?", "abc").FindAll(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := len(logger.lines); got != 0 {
+			t.Fatalf("got %v; want 0", got)
+		}
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		clock.step = 2 * time.Second
+
+		var got []QueryExample
+		if err := table.Query("#ID = ?", "abc").Filter("#Date = ?", "1").FindAll(&got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(logger.lines), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		line := logger.lines[0]
+		for _, want := range []string{"Query", "example", "2s", "#n1 = :v1", "#n2 = :v2", "items=1"} {
+			if !strings.Contains(line, want) {
+				t.Fatalf("got %v; want to contain %v", line, want)
+			}
+		}
+	})
+}
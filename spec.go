@@ -17,6 +17,7 @@ package ddb
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -39,12 +40,18 @@ const (
 )
 
 const (
-	optionKeysOnly = "keys_only"
+	optionKeysOnly   = "keys_only"
+	optionTimeFormat = "timefmt="
+	optionAuto       = "auto="
+	optionDefault    = "default="
+	optionCompose    = "compose="
+	optionFlag       = "flag"
 )
 
 type keySpec struct {
 	AttributeName string
 	AttributeType string
+	TimeFormat    string // TimeFormat holds optional layout used to encode time.Time key values
 }
 
 type attributeSpec struct {
@@ -68,6 +75,43 @@ type tableSpec struct {
 	Attributes []*attributeSpec
 	Globals    []*indexSpec
 	Locals     []*indexSpec
+	AutoKeys   []autoKey      // AutoKeys holds key fields populated on Put when blank
+	Defaults   []fieldDefault // Defaults holds values assigned on Put to zero fields
+	Composites []compositeKey // Composites holds key fields computed from other fields
+	Flags      []string       // Flags holds the attributes of bool fields stored only when true
+	Model      reflect.Type   // Model holds the struct type of the table model
+}
+
+// isFlag returns true if the attribute is a flag, a bool stored only when true so that
+// indexes keyed on it remain sparse
+func (spec *tableSpec) isFlag(attributeName string) bool {
+	return containsString(spec.Flags, attributeName)
+}
+
+// attribute returns the attribute whose attribute or field name matches name
+func (spec *tableSpec) attribute(name string) *attributeSpec {
+	for _, attr := range spec.Attributes {
+		if attr.AttributeName == name {
+			return attr
+		}
+	}
+	for _, attr := range spec.Attributes {
+		if attr.FieldName == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+// unknownAttribute returns an ErrUnknownAttribute error listing the known attributes
+func unknownAttribute(name string, attributes []*attributeSpec) error {
+	known := make([]string, 0, len(attributes))
+	for _, attr := range attributes {
+		known = append(known, attr.AttributeName)
+	}
+	sort.Strings(known)
+
+	return errorf(ErrUnknownAttribute, "attribute, %v, is not defined by the model; known attributes are %v", name, strings.Join(known, ", "))
 }
 
 func (spec *tableSpec) lsi(indexName string) *indexSpec {
@@ -100,6 +144,87 @@ func (spec *tableSpec) gsi(indexName string) *indexSpec {
 	return gsi
 }
 
+// index returns the global or local secondary index with the given name or nil if
+// no such index exists
+func (spec *tableSpec) index(indexName string) *indexSpec {
+	for _, m := range [][]*indexSpec{spec.Globals, spec.Locals} {
+		for _, index := range m {
+			if index.IndexName == indexName {
+				return index
+			}
+		}
+	}
+	return nil
+}
+
+// rangeKey returns the range key for the named index or the table range key if
+// indexName is blank
+func (spec *tableSpec) rangeKey(indexName string) *keySpec {
+	if indexName == "" {
+		return spec.RangeKey
+	}
+	if index := spec.index(indexName); index != nil {
+		return index.RangeKey
+	}
+	return nil
+}
+
+// keyAttributes returns the names of the attributes that make up the primary key
+// of the table and, when indexName is not blank, the keys of the named index
+func (spec *tableSpec) keyAttributes(indexName string) ([]string, error) {
+	var names []string
+	add := func(keys ...*keySpec) {
+		for _, key := range keys {
+			if key != nil {
+				names = append(names, key.AttributeName)
+			}
+		}
+	}
+
+	add(spec.HashKey, spec.RangeKey)
+	if indexName != "" {
+		index := spec.index(indexName)
+		if index == nil {
+			return nil, fmt.Errorf("index, %v, not defined for table, %v", indexName, spec.TableName)
+		}
+		add(index.HashKey, index.RangeKey)
+	}
+
+	return names, nil
+}
+
+// unprojected returns the attributes of names that are not projected into the named
+// index.  nil is returned if the index is undefined or projects all attributes.
+func (spec *tableSpec) unprojected(indexName string, names []string) []string {
+	index := spec.index(indexName)
+	if index == nil || (len(index.Attributes) == 0 && !index.KeysOnly) {
+		return nil
+	}
+
+	projected, _ := spec.keyAttributes(indexName)
+	for _, attr := range index.Attributes {
+		projected = append(projected, attr.AttributeName)
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !containsString(projected, name) && !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// isLocal returns true if the named index is a local secondary index
+func (spec *tableSpec) isLocal(indexName string) bool {
+	for _, lsi := range spec.Locals {
+		if lsi.IndexName == indexName {
+			return true
+		}
+	}
+	return false
+}
+
 func inspect(tableName string, model interface{}) (*tableSpec, error) {
 	t, v := reflect.TypeOf(model), reflect.ValueOf(model)
 	if t.Kind() == reflect.Ptr {
@@ -111,6 +236,7 @@ func inspect(tableName string, model interface{}) (*tableSpec, error) {
 
 	spec := tableSpec{
 		TableName: tableName,
+		Model:     t,
 	}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -140,17 +266,77 @@ func inspect(tableName string, model interface{}) (*tableSpec, error) {
 
 		for _, tag := range strings.Split(tags, tagSeparator) {
 			tag = strings.TrimSpace(tag)
+
+			if text := tagOptionValue(tag, optionDefault); text != "" {
+				value, err := parseDefault(field, text)
+				if err != nil {
+					return nil, err
+				}
+				spec.Defaults = append(spec.Defaults, fieldDefault{
+					FieldName: field.Name,
+					Value:     value,
+				})
+			}
+
+			if hasTagOption(tag, optionFlag) {
+				if field.Type.Kind() != reflect.Bool {
+					return nil, fmt.Errorf("flag option requires bool field: %v is %v", field.Name, field.Type)
+				}
+				spec.Flags = append(spec.Flags, attr.AttributeName)
+			}
+
+			if template := tagOptionValue(tag, optionCompose); template != "" {
+				if field.Type.Kind() != reflect.String {
+					return nil, fmt.Errorf("compose option requires string field: %v is %v", field.Name, field.Type)
+				}
+				parts, err := parseComposite(template)
+				if err != nil {
+					return nil, fmt.Errorf("invalid compose option on field %v: %w", field.Name, err)
+				}
+				for _, part := range parts {
+					if part.fieldName == "" {
+						continue
+					}
+					if _, ok := t.FieldByName(part.fieldName); !ok {
+						return nil, fmt.Errorf("compose option on field %v references unknown field, %v", field.Name, part.fieldName)
+					}
+				}
+				spec.Composites = append(spec.Composites, compositeKey{
+					FieldName:     field.Name,
+					AttributeName: attr.AttributeName,
+					Parts:         parts,
+				})
+			}
+
+			switch firstOption(tag) {
+			case tagHashKey, tagRangeKey:
+				if generator := tagOptionValue(tag, optionAuto); generator != "" {
+					if _, ok := autoGenerators[generator]; !ok {
+						return nil, fmt.Errorf("unsupported auto option, %v, on field %v", generator, field.Name)
+					}
+					if field.Type.Kind() != reflect.String {
+						return nil, fmt.Errorf("auto option requires string field: %v is %v", field.Name, field.Type)
+					}
+					spec.AutoKeys = append(spec.AutoKeys, autoKey{
+						FieldName: field.Name,
+						Generator: generator,
+					})
+				}
+			}
+
 			switch {
-			case tag == tagHashKey:
+			case firstOption(tag) == tagHashKey:
 				spec.HashKey = &keySpec{
 					AttributeName: attr.AttributeName,
 					AttributeType: attr.AttributeType,
+					TimeFormat:    tagOptionValue(tag, optionTimeFormat),
 				}
 
-			case tag == tagRangeKey:
+			case firstOption(tag) == tagRangeKey:
 				spec.RangeKey = &keySpec{
 					AttributeName: attr.AttributeName,
 					AttributeType: attr.AttributeType,
+					TimeFormat:    tagOptionValue(tag, optionTimeFormat),
 				}
 
 			case strings.HasPrefix(tag, tagGsiHash):
@@ -175,6 +361,7 @@ func inspect(tableName string, model interface{}) (*tableSpec, error) {
 				gsi.RangeKey = &keySpec{
 					AttributeName: attr.AttributeName,
 					AttributeType: attr.AttributeType,
+					TimeFormat:    tagOptionValue(tag, optionTimeFormat),
 				}
 
 			case strings.HasPrefix(tag, tagGsi):
@@ -194,6 +381,7 @@ func inspect(tableName string, model interface{}) (*tableSpec, error) {
 				lsi.RangeKey = &keySpec{
 					AttributeName: attr.AttributeName,
 					AttributeType: attr.AttributeType,
+					TimeFormat:    tagOptionValue(tag, optionTimeFormat),
 				}
 
 			case strings.HasPrefix(tag, tagLsi):
@@ -224,18 +412,22 @@ func getAttrType(field reflect.StructField, value reflect.Value) (string, error)
 	}
 
 	if field.IsExported() {
+		if m, ok := lookupMarshaler(field.Type); ok {
+			item, err := m.marshal(value.Interface())
+			if err != nil {
+				return "", err
+			}
+			if t, ok := scalarAttributeType(item); ok {
+				return t, nil
+			}
+		}
 		if v, ok := value.Interface().(dynamodbattribute.Marshaler); ok {
 			item, err := dynamodbattribute.Marshal(v)
 			if err != nil {
 				return "", err
 			}
-			switch {
-			case item.N != nil:
-				return dynamodb.ScalarAttributeTypeN, nil
-			case item.S != nil:
-				return dynamodb.ScalarAttributeTypeS, nil
-			case item.B != nil:
-				return dynamodb.ScalarAttributeTypeB, nil
+			if t, ok := scalarAttributeType(item); ok {
+				return t, nil
 			}
 		}
 	}
@@ -243,6 +435,22 @@ func getAttrType(field reflect.StructField, value reflect.Value) (string, error)
 	return "Unknown", nil
 }
 
+// scalarAttributeType returns the scalar type of the attribute value, if any
+func scalarAttributeType(item *dynamodb.AttributeValue) (string, bool) {
+	switch {
+	case item == nil:
+		return "", false
+	case item.N != nil:
+		return dynamodb.ScalarAttributeTypeN, true
+	case item.S != nil:
+		return dynamodb.ScalarAttributeTypeS, true
+	case item.B != nil:
+		return dynamodb.ScalarAttributeTypeB, true
+	default:
+		return "", false
+	}
+}
+
 func firstOption(tag string) string {
 	segments := strings.Split(tag, ",")
 	return strings.TrimSpace(segments[0])
@@ -257,6 +465,16 @@ func hasTagOption(tag, option string) bool {
 	return false
 }
 
+// tagOptionValue returns the value of the option with the given prefix e.g. timefmt=
+func tagOptionValue(tag, prefix string) string {
+	for _, item := range strings.Split(tag, ",") {
+		if item = strings.TrimSpace(item); strings.HasPrefix(item, prefix) {
+			return item[len(prefix):]
+		}
+	}
+	return ""
+}
+
 func getAttrName(field reflect.StructField) (string, bool) {
 	if v, ok := field.Tag.Lookup("dynamodbav"); ok {
 		v = strings.TrimSpace(v)
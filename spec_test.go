@@ -130,3 +130,53 @@ func TestInspectCustomMarshal(t *testing.T) {
 		t.Fatalf("got %v; want nil", spec.RangeKey)
 	}
 }
+
+func TestInspectFlag(t *testing.T) {
+	type Flagged struct {
+		ID      string `ddb:"hash"`
+		Pending bool   `ddb:"flag" dynamodbav:"pending"`
+	}
+
+	spec, err := inspect("example", Flagged{})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := spec.Flags, []string{"pending"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	type Invalid struct {
+		ID     string `ddb:"hash"`
+		Status string `ddb:"flag"`
+	}
+	if _, err := inspect("example", Invalid{}); err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
+
+func TestInspectTimeFormat(t *testing.T) {
+	type Sample struct {
+		ID   string `ddb:"hash"`
+		Date string `ddb:"range,timefmt=2006-01-02"`
+		Alt  string `ddb:"gsi_hash:index"`
+		At   int64  `ddb:"gsi_range:index,timefmt=20060102"`
+	}
+
+	spec, err := inspect("example", Sample{})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := spec.RangeKey.TimeFormat, "2006-01-02"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := spec.HashKey.TimeFormat, ""; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := spec.rangeKey("index").TimeFormat, "20060102"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := spec.rangeKey("missing"); got != nil {
+		t.Fatalf("got %v; want nil", got)
+	}
+}
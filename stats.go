@@ -0,0 +1,116 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Stats captures per request telemetry for any of the builders via their Stats
+// method.  ScannedCount holds the number of items evaluated by DynamoDB and Count the
+// number returned; a large difference between the two suggests a filter doing the
+// work of a key condition.  Stats is safe to share between requests and across the
+// segments of a parallel scan.  Requests run within a transaction are not recorded.
+type Stats struct {
+	Attempts     int64            // Attempts holds the number of requests sent to DynamoDB, including retries
+	Retries      int64            // Retries holds the number of requests retried by ddb or the aws sdk
+	Pages        int64            // Pages holds the number of pages read by queries and scans
+	Count        int64            // Count holds the number of items returned
+	ScannedCount int64            // ScannedCount holds the number of items evaluated by queries and scans
+	Duration     time.Duration    // Duration holds the elapsed time of the requests, measured by the clock of the DDB
+	Capacity     ConsumedCapacity // Capacity holds the capacity consumed by the requests
+}
+
+// attempt records a request sent to DynamoDB.  retry is true if the request repeats one
+// that was throttled.
+func (s *Stats) attempt(retry bool) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.Attempts, 1)
+	if retry {
+		atomic.AddInt64(&s.Retries, 1)
+	}
+}
+
+// options returns the request options that record the retries made by the aws sdk
+func (s *Stats) options() []request.Option {
+	if s == nil {
+		return nil
+	}
+	return []request.Option{
+		func(r *request.Request) {
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				if n := int64(r.RetryCount); n > 0 {
+					atomic.AddInt64(&s.Attempts, n)
+					atomic.AddInt64(&s.Retries, n)
+				}
+			})
+		},
+	}
+}
+
+// add credits the page read with count and scannedCount items
+func (s *Stats) add(count, scannedCount *int64) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.Pages, 1)
+	if count != nil {
+		atomic.AddInt64(&s.Count, *count)
+	}
+	if scannedCount != nil {
+		atomic.AddInt64(&s.ScannedCount, *scannedCount)
+	}
+}
+
+// found records an item returned outside of a page e.g. by Get
+func (s *Stats) found() {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.Count, 1)
+}
+
+// consumed records the capacity consumed by a request
+func (s *Stats) consumed(in *dynamodb.ConsumedCapacity) {
+	if s == nil {
+		return
+	}
+	s.Capacity.add(in)
+}
+
+// since records the time elapsed on clock since start; intended to be deferred so the
+// virtual time of a test clock, including backoff, is reflected in Duration
+func (s *Stats) since(clock Clock, start time.Time) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64((*int64)(&s.Duration), int64(clock.Now().Sub(start)))
+}
+
+// FilterRatio returns the fraction of scanned items that were filtered out, between 0
+// and 1
+func (s *Stats) FilterRatio() float64 {
+	scanned := atomic.LoadInt64(&s.ScannedCount)
+	if scanned == 0 {
+		return 0
+	}
+	return float64(scanned-atomic.LoadInt64(&s.Count)) / float64(scanned)
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// countMock returns pages of count items from scannedCount evaluated
+type countMock struct {
+	dynamodbiface.DynamoDBAPI
+	count        int64
+	scannedCount int64
+
+	mutex   sync.Mutex
+	selects []string // selects holds the Select of each scan input
+}
+
+func (c *countMock) QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error) {
+	return &dynamodb.QueryOutput{Count: aws.Int64(c.count), ScannedCount: aws.Int64(c.scannedCount)}, nil
+}
+
+func (c *countMock) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	c.mutex.Lock()
+	c.selects = append(c.selects, aws.StringValue(input.Select))
+	c.mutex.Unlock()
+
+	return &dynamodb.ScanOutput{Count: aws.Int64(c.count), ScannedCount: aws.Int64(c.scannedCount)}, nil
+}
+
+func TestStats(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		table = New(&countMock{count: 3, scannedCount: 10}).MustTable("example", Example{})
+		stats Stats
+	)
+
+	callback := func(item Item) (bool, error) { return true, nil }
+	if err := table.Query("#ID = ?", "abc").Stats(&stats).EachWithContext(ctx, callback); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Scan().TotalSegments(2).Stats(&stats).EachWithContext(ctx, callback); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	for label, tc := range map[string]struct{ Got, Want int64 }{
+		"attempts": {Got: stats.Attempts, Want: 3},
+		"retries":  {Got: stats.Retries, Want: 0},
+		"pages":    {Got: stats.Pages, Want: 3},
+		"count":    {Got: stats.Count, Want: 9},
+		"scanned":  {Got: stats.ScannedCount, Want: 30},
+	} {
+		if tc.Got != tc.Want {
+			t.Fatalf("%v: got %v; want %v", label, tc.Got, tc.Want)
+		}
+	}
+	if got, want := stats.FilterRatio(), 0.7; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := (&Stats{}).FilterRatio(); got != 0 {
+		t.Fatalf("got %v; want 0", got)
+	}
+}
+
+func TestStats_Retries(t *testing.T) {
+	var (
+		mock  = &throttleMock{Mock: &Mock{}, pages: 2, fail: map[int]int{1: 2}}
+		table = New(mock).WithPageRetry(3, noBackoff).MustTable("example", Example{})
+		stats Stats
+		ids   []string
+	)
+
+	if err := table.Query("#ID = ?", "abc").Stats(&stats).EachWithContext(context.Background(), collect(&ids)); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := stats.Attempts, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats.Retries, int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats.Pages, int64(2); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestStats_First(t *testing.T) {
+	var (
+		item     = QueryExample{ID: "abc", Date: "2019-03-10"}
+		mock     = &Mock{queryItems: []interface{}{item, item, item}, readUnits: 1}
+		table    = New(mock).MustTable("example", QueryExample{})
+		stats    Stats
+		consumed ConsumedCapacity
+	)
+
+	var got QueryExample
+	if err := table.Query("#ID = ?", "abc").Stats(&stats).ConsumedCapacity(&consumed).First(&got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	for label, tc := range map[string]struct{ Got, Want int64 }{
+		"attempts": {Got: stats.Attempts, Want: 1},
+		"pages":    {Got: stats.Pages, Want: 1},
+		"count":    {Got: stats.Count, Want: 3},
+		"capacity": {Got: stats.Capacity.ReadUnits, Want: 1},
+		"request":  {Got: consumed.ReadUnits, Want: 1},
+		"table":    {Got: table.ConsumedCapacity().ReadUnits, Want: 1},
+	} {
+		if tc.Got != tc.Want {
+			t.Fatalf("%v: got %v; want %v", label, tc.Got, tc.Want)
+		}
+	}
+}
+
+func TestStats_Clock(t *testing.T) {
+	var (
+		clock = &fakeClock{now: time.Unix(1600000000, 0), step: time.Second}
+		mock  = &Mock{getItem: Example{ID: "abc"}}
+		table = New(mock).WithClock(clock).MustTable("example", Example{})
+		stats Stats
+	)
+
+	var v Example
+	if err := table.Get("abc").Stats(&stats).ScanWithContext(context.Background(), &v); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := stats.Duration, time.Second; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestStats_Item(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = &Mock{getItem: Example{ID: "abc"}, readUnits: 1, writeUnits: 2}
+		table = New(mock).MustTable("example", Example{})
+		stats Stats
+	)
+
+	if err := table.Put(Example{ID: "abc"}).Stats(&stats).RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	var v Example
+	if err := table.Get("abc").Stats(&stats).ScanWithContext(ctx, &v); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Delete("abc").Stats(&stats).RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Update("abc").Set("#Name = ?", "name").Stats(&stats).RunWithContext(ctx); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if got, want := stats.Attempts, int64(4); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats.Count, int64(1); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := stats.Capacity.WriteUnits, int64(8); got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if stats.Duration <= 0 {
+		t.Fatalf("got %v; want > 0", stats.Duration)
+	}
+	if got := mock.putInput.ReturnConsumedCapacity; got == nil {
+		t.Fatalf("got nil; want ReturnConsumedCapacity")
+	}
+}
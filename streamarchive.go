@@ -0,0 +1,58 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+)
+
+const (
+	// ttlPrincipalID identifies DynamoDB as the principal of TTL removals
+	ttlPrincipalID = "dynamodb.amazonaws.com"
+	// ttlIdentityType is the type of the principal of TTL removals
+	ttlIdentityType = "Service"
+)
+
+// IsExpired returns true if the record is the removal of an item by TTL
+func (r Record) IsExpired() bool {
+	return r.EventName == dynamodbstreams.OperationTypeRemove &&
+		r.UserIdentity != nil &&
+		r.UserIdentity.PrincipalID == ttlPrincipalID &&
+		r.UserIdentity.Type == ttlIdentityType
+}
+
+// Archiver receives the records of items removed by TTL e.g. to write the OldImage to
+// cold storage such as S3
+type Archiver interface {
+	Archive(ctx context.Context, record Record) error
+}
+
+// ArchiveExpired returns a RecordHandler that passes the records of items removed by
+// TTL to archiver and all other records, including items deleted by the application,
+// to handler.  If handler is nil, other records are ignored.  Archiving requires a
+// StreamViewType that includes old images.
+func ArchiveExpired(archiver Archiver, handler RecordHandler) RecordHandler {
+	return func(ctx context.Context, record Record) error {
+		if record.IsExpired() {
+			return archiver.Archive(ctx, record)
+		}
+		if handler == nil {
+			return nil
+		}
+		return handler(ctx, record)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// archiverFunc adapts a func to Archiver
+type archiverFunc func(ctx context.Context, record Record) error
+
+func (fn archiverFunc) Archive(ctx context.Context, record Record) error {
+	return fn(ctx, record)
+}
+
+func TestArchiveExpired(t *testing.T) {
+	const expired = `{
+  "eventID": "1",
+  "eventName": "REMOVE",
+  "userIdentity": {"type": "Service", "principalId": "dynamodb.amazonaws.com"},
+  "dynamodb": {"OldImage": {"ID": {"S": "abc"}}, "SequenceNumber": "100"}
+}`
+
+	var record Record
+	if err := json.Unmarshal([]byte(expired), &record); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !record.IsExpired() {
+		t.Fatalf("got false; want true")
+	}
+
+	var archived, handled []string
+	handler := ArchiveExpired(
+		archiverFunc(func(ctx context.Context, record Record) error {
+			archived = append(archived, record.EventID)
+			return nil
+		}),
+		func(ctx context.Context, record Record) error {
+			handled = append(handled, record.EventID)
+			return nil
+		},
+	)
+
+	records := []Record{
+		record,
+		{EventID: "2", EventName: "REMOVE"},
+		{EventID: "3", EventName: "INSERT"},
+	}
+	for _, r := range records {
+		if err := handler(context.Background(), r); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+	if got, want := len(archived), 1; got != want || archived[0] != "1" {
+		t.Fatalf("got %v; want [1]", archived)
+	}
+	if got, want := len(handled), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("nil handler", func(t *testing.T) {
+		handler := ArchiveExpired(archiverFunc(func(context.Context, Record) error { return nil }), nil)
+		if err := handler(context.Background(), Record{EventName: "INSERT"}); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+}
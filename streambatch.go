@@ -0,0 +1,59 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+)
+
+// EventHandler processes the records of a stream event, returning the records that
+// failed as a Lambda partial batch response
+type EventHandler func(ctx context.Context, event Event) (BatchResponse, error)
+
+// BatchItemFailure identifies a record for Lambda to retry by its sequence number
+type BatchItemFailure struct {
+	ItemIdentifier string `json:"itemIdentifier"`
+}
+
+// BatchResponse is a Lambda partial batch response; requires ReportBatchItemFailures
+// to be enabled on the event source mapping
+type BatchResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+// failed returns a response reporting the record as a batch item failure
+func failed(record Record) BatchResponse {
+	return BatchResponse{
+		BatchItemFailures: []BatchItemFailure{{ItemIdentifier: record.Change.SequenceNumber}},
+	}
+}
+
+// ReportBatchItemFailures returns an EventHandler that invokes handler for each record
+// of the event in order.  Processing stops at the first record to fail, which is
+// reported as a batch item failure; Lambda then checkpoints the records before it and
+// resumes the shard from the failed record rather than reprocessing the whole batch.
+// Later records are left unprocessed as Lambda would deliver them again regardless.
+//
+//	lambda.Start(ddb.ReportBatchItemFailures(handler))
+func ReportBatchItemFailures(handler RecordHandler) EventHandler {
+	return func(ctx context.Context, event Event) (BatchResponse, error) {
+		for _, record := range event.Records {
+			if err := handler(ctx, record); err != nil {
+				return failed(record), nil
+			}
+		}
+		return BatchResponse{}, nil
+	}
+}
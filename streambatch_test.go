@@ -0,0 +1,74 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestReportBatchItemFailures(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		event = Event{
+			Records: []Record{
+				{EventID: "a", Change: Change{SequenceNumber: "1"}},
+				{EventID: "b", Change: Change{SequenceNumber: "2"}},
+				{EventID: "c", Change: Change{SequenceNumber: "3"}},
+			},
+		}
+	)
+
+	testCases := map[string]struct {
+		Fail        string
+		WantHandled []string
+		Want        BatchResponse
+	}{
+		"ok": {
+			WantHandled: []string{"a", "b", "c"},
+		},
+		"failure": {
+			Fail:        "b",
+			WantHandled: []string{"a", "b"},
+			Want:        BatchResponse{BatchItemFailures: []BatchItemFailure{{ItemIdentifier: "2"}}},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			var handled []string
+			handler := ReportBatchItemFailures(func(ctx context.Context, record Record) error {
+				handled = append(handled, record.EventID)
+				if record.EventID == tc.Fail {
+					return io.EOF
+				}
+				return nil
+			})
+
+			got, err := handler(ctx, event)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Fatalf("got %#v; want %#v", got, tc.Want)
+			}
+			if !reflect.DeepEqual(handled, tc.WantHandled) {
+				t.Fatalf("got %v; want %v", handled, tc.WantHandled)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordHandler processes a single stream record
+type RecordHandler func(ctx context.Context, record Record) error
+
+// DedupeStore remembers the ids of stream records that have been processed
+type DedupeStore interface {
+	// Seen returns true if the record id has been marked as processed
+	Seen(ctx context.Context, id string) (bool, error)
+	// Mark records the id as processed.  Marking an id more than once is not an error.
+	Mark(ctx context.Context, id string) error
+}
+
+// DedupeRecords wraps handler so that records already processed, as remembered by
+// store, are skipped.  As Lambda delivers stream records at least once, retries of a
+// partially processed batch would otherwise process records again.  Records are marked
+// only after handler succeeds so failed records are retried; a record whose handler
+// succeeds but whose mark fails may still be processed twice.
+//
+//	handler := ddb.DedupeRecords(process, ddb.NewTableDedupeStore(table, 24*time.Hour))
+//	for _, record := range event.Records {
+//		if err := handler(ctx, record); err != nil {
+//			return err
+//		}
+//	}
+func DedupeRecords(handler RecordHandler, store DedupeStore) RecordHandler {
+	return func(ctx context.Context, record Record) error {
+		id := recordID(record)
+		if id == "" {
+			return handler(ctx, record)
+		}
+
+		seen, err := store.Seen(ctx, id)
+		if err != nil {
+			return fmt.Errorf("unable to dedupe record, %v: %w", id, err)
+		}
+		if seen {
+			return nil
+		}
+
+		if err := handler(ctx, record); err != nil {
+			return err
+		}
+
+		if err := store.Mark(ctx, id); err != nil {
+			return fmt.Errorf("unable to mark record, %v, as processed: %w", id, err)
+		}
+		return nil
+	}
+}
+
+// recordID returns the EventID of the record or, if blank, the stream arn and
+// sequence number of the change
+func recordID(record Record) string {
+	if record.EventID != "" {
+		return record.EventID
+	}
+	if seq := record.Change.SequenceNumber; seq != "" {
+		return record.EventSourceARN + "/" + seq
+	}
+	return ""
+}
+
+// ProcessedRecord is the model of the table used by NewTableDedupeStore.  Enable TTL on
+// the expires attribute so that processed record ids are eventually removed e.g.
+//
+//	table := db.MustTable("processed", ddb.ProcessedRecord{})
+type ProcessedRecord struct {
+	ID      string       `ddb:"hash" dynamodbav:"id"`
+	Expires EpochSeconds `dynamodbav:"expires"`
+}
+
+// tableDedupeStore remembers processed record ids in a table of ProcessedRecord
+type tableDedupeStore struct {
+	table *Table
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewTableDedupeStore returns a DedupeStore backed by table, a table of
+// ProcessedRecord.  Ids are remembered for ttl, which should exceed the 24 hour
+// retention of the stream.  As DynamoDB may take some time to delete expired items,
+// ids may be remembered for longer than ttl.
+func NewTableDedupeStore(table *Table, ttl time.Duration) DedupeStore {
+	return &tableDedupeStore{
+		table: table,
+		ttl:   ttl,
+		now:   table.ddb.clock.Now,
+	}
+}
+
+// Seen implements DedupeStore
+func (s *tableDedupeStore) Seen(ctx context.Context, id string) (bool, error) {
+	return s.table.Get(id).ConsistentRead(true).Exists(ctx)
+}
+
+// Mark implements DedupeStore using a conditional put so the first mark of an id wins
+func (s *tableDedupeStore) Mark(ctx context.Context, id string) error {
+	record := ProcessedRecord{
+		ID:      id,
+		Expires: EpochSeconds(s.now().Add(s.ttl).Unix()),
+	}
+	err := s.table.Put(record).AttributeNotExists("id").RunWithContext(ctx)
+	if IsConditionFailedError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// memoryDedupeStore is an in memory DedupeStore
+type memoryDedupeStore struct {
+	ids map[string]bool
+	err error
+}
+
+func (m *memoryDedupeStore) Seen(_ context.Context, id string) (bool, error) {
+	return m.ids[id], m.err
+}
+
+func (m *memoryDedupeStore) Mark(_ context.Context, id string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.ids[id] = true
+	return nil
+}
+
+func TestDedupeRecords(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		store   = &memoryDedupeStore{ids: map[string]bool{}}
+		handled []string
+		fail    = map[string]bool{}
+	)
+
+	handler := DedupeRecords(func(ctx context.Context, record Record) error {
+		if fail[record.EventID] {
+			return io.EOF
+		}
+		handled = append(handled, recordID(record))
+		return nil
+	}, store)
+
+	records := []Record{
+		{EventID: "a"},
+		{EventID: "b"},
+		{EventID: "a"},
+		{EventSourceARN: "arn", Change: Change{SequenceNumber: "1"}},
+		{EventSourceARN: "arn", Change: Change{SequenceNumber: "1"}},
+	}
+	for _, record := range records {
+		if err := handler(ctx, record); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	}
+	if got, want := handled, []string{"a", "b", "arn/1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("failed records are retried", func(t *testing.T) {
+		fail["c"] = true
+		if err := handler(ctx, Record{EventID: "c"}); !errors.Is(err, io.EOF) {
+			t.Fatalf("got %v; want %v", err, io.EOF)
+		}
+		if store.ids["c"] {
+			t.Fatalf("got true; want false")
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		store := &memoryDedupeStore{err: io.ErrUnexpectedEOF}
+		handler := DedupeRecords(func(context.Context, Record) error { return nil }, store)
+		if err := handler(ctx, Record{EventID: "d"}); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("got %v; want %v", err, io.ErrUnexpectedEOF)
+		}
+	})
+}
+
+func TestTableDedupeStore(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		now   = time.Unix(1000, 0)
+		mock  = &Mock{}
+		table = New(mock).MustTable("processed", ProcessedRecord{})
+		store = NewTableDedupeStore(table, time.Hour).(*tableDedupeStore)
+	)
+	store.now = func() time.Time { return now }
+
+	seen, err := store.Seen(ctx, "abc")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if seen {
+		t.Fatalf("got true; want false")
+	}
+	if got, want := aws.BoolValue(mock.getInput.ConsistentRead), true; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	if err := store.Mark(ctx, "abc"); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(mock.putInput.Item["expires"].N), "4600"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(mock.putInput.ConditionExpression), "attribute_not_exists(#n1)"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	mock.getItem = ProcessedRecord{ID: "abc"}
+	seen, err = store.Seen(ctx, "abc")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !seen {
+		t.Fatalf("got false; want true")
+	}
+
+	t.Run("already marked", func(t *testing.T) {
+		mock := &Mock{err: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "boom", nil)}
+		store := NewTableDedupeStore(New(mock).MustTable("processed", ProcessedRecord{}), time.Hour)
+		if err := store.Mark(ctx, "abc"); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RecordFailure holds a record that could not be processed
+type RecordFailure struct {
+	Record   Record // Record that failed
+	Err      error  // Err holds the error returned by the final attempt
+	Attempts int    // Attempts holds the number of times the record was attempted
+}
+
+// DeadLetterQueue receives records that could not be processed e.g. another table, via
+// NewTableDeadLetterQueue, or an SQS queue
+type DeadLetterQueue interface {
+	Send(ctx context.Context, failure RecordFailure) error
+}
+
+// DeadLetterRecords returns an EventHandler that invokes handler for each record of the
+// event, attempting each record up to n times with backoff(attempt) between attempts.
+// Records that still fail are sent to dlq and processing continues with the next
+// record.  If a failure cannot be sent to dlq, the record is reported as a batch item
+// failure and the remaining records are left unprocessed as Lambda resumes the shard
+// from the failed record.
+func DeadLetterRecords(handler RecordHandler, dlq DeadLetterQueue, n int, backoff func(attempt int) time.Duration) EventHandler {
+	if n < 1 {
+		panic(fmt.Errorf("DeadLetterRecords requires n >= 1: got %v", n))
+	}
+	if backoff == nil {
+		backoff = getTimeout
+	}
+	retry := retryPolicy{
+		attempts:  n,
+		backoff:   backoff,
+		retryable: func(error) bool { return true },
+	}
+
+	return func(ctx context.Context, event Event) (BatchResponse, error) {
+		for _, record := range event.Records {
+			var attempts int
+			err := retry.do(ctx, func() error {
+				attempts++
+				return handler(ctx, record)
+			})
+			if err == nil {
+				continue
+			}
+			if ctx.Err() == nil {
+				failure := RecordFailure{Record: record, Err: err, Attempts: attempts}
+				if dlq.Send(ctx, failure) == nil {
+					continue
+				}
+			}
+
+			return failed(record), nil
+		}
+		return BatchResponse{}, nil
+	}
+}
+
+// DeadLetter is the model of the table used by NewTableDeadLetterQueue
+type DeadLetter struct {
+	ID       string       `ddb:"hash" dynamodbav:"id"`
+	Record   string       `dynamodbav:"record"` // Record holds the json encoded Record
+	Error    string       `dynamodbav:"error"`
+	Attempts int          `dynamodbav:"attempts"`
+	FailedAt EpochSeconds `dynamodbav:"failedAt"`
+}
+
+// tableDeadLetterQueue writes failed records to a table of DeadLetter
+type tableDeadLetterQueue struct {
+	table *Table
+	now   func() time.Time
+}
+
+// NewTableDeadLetterQueue returns a DeadLetterQueue that writes failed records to table,
+// a table of DeadLetter, keyed by record id
+func NewTableDeadLetterQueue(table *Table) DeadLetterQueue {
+	return &tableDeadLetterQueue{
+		table: table,
+		now:   table.ddb.clock.Now,
+	}
+}
+
+// Send implements DeadLetterQueue
+func (q *tableDeadLetterQueue) Send(ctx context.Context, failure RecordFailure) error {
+	data, err := json.Marshal(failure.Record)
+	if err != nil {
+		return fmt.Errorf("unable to encode record: %w", err)
+	}
+
+	item := DeadLetter{
+		ID:       recordID(failure.Record),
+		Record:   string(data),
+		Attempts: failure.Attempts,
+		FailedAt: EpochSeconds(q.now().Unix()),
+	}
+	if failure.Err != nil {
+		item.Error = failure.Err.Error()
+	}
+	return q.table.Put(item).RunWithContext(ctx)
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// memoryDeadLetterQueue is an in memory DeadLetterQueue
+type memoryDeadLetterQueue struct {
+	failures []RecordFailure
+	err      error
+}
+
+func (m *memoryDeadLetterQueue) Send(_ context.Context, failure RecordFailure) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.failures = append(m.failures, failure)
+	return nil
+}
+
+func TestDeadLetterRecords(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		event = Event{
+			Records: []Record{
+				{EventID: "a", Change: Change{SequenceNumber: "1"}},
+				{EventID: "b", Change: Change{SequenceNumber: "2"}},
+				{EventID: "c", Change: Change{SequenceNumber: "3"}},
+			},
+		}
+		noDelay = func(int) time.Duration { return 0 }
+	)
+
+	t.Run("retries then sends to dlq", func(t *testing.T) {
+		var (
+			dlq      = &memoryDeadLetterQueue{}
+			attempts = map[string]int{}
+		)
+		handler := DeadLetterRecords(func(ctx context.Context, record Record) error {
+			attempts[record.EventID]++
+			switch {
+			case record.EventID == "a" && attempts["a"] < 2:
+				return io.ErrUnexpectedEOF // recovers on retry
+			case record.EventID == "b":
+				return io.EOF
+			}
+			return nil
+		}, dlq, 3, noDelay)
+
+		response, err := handler(ctx, event)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got := response.BatchItemFailures; len(got) != 0 {
+			t.Fatalf("got %v; want none", got)
+		}
+		if got, want := attempts, map[string]int{"a": 2, "b": 3, "c": 1}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(dlq.failures), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got := dlq.failures[0]; got.Record.EventID != "b" || got.Err != io.EOF || got.Attempts != 3 {
+			t.Fatalf("got %#v; want record b after 3 attempts", got)
+		}
+	})
+
+	t.Run("dlq failure", func(t *testing.T) {
+		var (
+			dlq     = &memoryDeadLetterQueue{err: io.ErrClosedPipe}
+			handled []string
+		)
+		handler := DeadLetterRecords(func(ctx context.Context, record Record) error {
+			handled = append(handled, record.EventID)
+			if record.EventID == "b" {
+				return io.EOF
+			}
+			return nil
+		}, dlq, 1, noDelay)
+
+		response, err := handler(ctx, event)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		want := BatchResponse{BatchItemFailures: []BatchItemFailure{{ItemIdentifier: "2"}}}
+		if !reflect.DeepEqual(response, want) {
+			t.Fatalf("got %#v; want %#v", response, want)
+		}
+		if got, want := handled, []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := string(data), `{"batchItemFailures":[{"itemIdentifier":"2"}]}`; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestTableDeadLetterQueue(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		mock  = &Mock{}
+		table = New(mock).MustTable("dlq", DeadLetter{})
+		dlq   = NewTableDeadLetterQueue(table).(*tableDeadLetterQueue)
+	)
+	dlq.now = func() time.Time { return time.Unix(1000, 0) }
+
+	failure := RecordFailure{
+		Record:   Record{EventID: "abc", EventName: "INSERT"},
+		Err:      io.EOF,
+		Attempts: 2,
+	}
+	if err := dlq.Send(ctx, failure); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var got DeadLetter
+	if err := unmarshalMap(mock.putInput.Item, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got.ID != "abc" || got.Error != "EOF" || got.Attempts != 2 || got.FailedAt != 1000 {
+		t.Fatalf("got %#v; want dead letter for abc", got)
+	}
+
+	var record Record
+	if err := json.Unmarshal([]byte(got.Record), &record); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !reflect.DeepEqual(record, failure.Record) {
+		t.Fatalf("got %#v; want %#v", record, failure.Record)
+	}
+	if got, want := aws.StringValue(mock.putInput.TableName), "dlq"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
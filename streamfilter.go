@@ -0,0 +1,310 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Filter matches stream records against a Lambda event source filter pattern e.g.
+//
+//	{"eventName": ["INSERT"], "dynamodb": {"NewImage": {"Status": {"S": ["open"]}}}}
+//
+// Patterns are evaluated against the json form of the record as delivered to Lambda.
+// Each field of the pattern must match; each array lists alternatives of which one
+// must match.  Supported matches are literal strings, numbers, and booleans, and the
+// prefix, suffix, equals-ignore-case, anything-but, numeric, and exists operators.  As
+// with Lambda, numeric matches json numbers only and so never matches the N members of
+// an image, which DynamoDB encodes as strings.  Null literals are rejected as the json
+// form of a record holds no null values; use exists to match absent fields.
+type Filter struct {
+	pattern map[string]interface{}
+}
+
+// NewFilter returns a Filter for the json filter pattern
+func NewFilter(pattern string) (*Filter, error) {
+	var p map[string]interface{}
+	if err := json.Unmarshal([]byte(pattern), &p); err != nil {
+		return nil, fmt.Errorf("invalid filter pattern: %w", err)
+	}
+	if err := checkPattern(p); err != nil {
+		return nil, fmt.Errorf("invalid filter pattern: %w", err)
+	}
+	return &Filter{pattern: p}, nil
+}
+
+// MustFilter is identical to NewFilter, but panics on an invalid pattern
+func MustFilter(pattern string) *Filter {
+	f, err := NewFilter(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Match returns true if the record matches the filter pattern
+func (f *Filter) Match(record Record) (bool, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, fmt.Errorf("unable to encode record: %w", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("unable to decode record: %w", err)
+	}
+
+	return matchPattern(f.pattern, withoutNulls(doc)), nil
+}
+
+// MatchAny returns true if the record matches any of the filters, as Lambda combines
+// the filters of an event source mapping
+func MatchAny(record Record, filters ...*Filter) (bool, error) {
+	for _, f := range filters {
+		ok, err := f.Match(record)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// withoutNulls removes null fields, such as the unset members of an AttributeValue,
+// which are absent from the records delivered to Lambda
+func withoutNulls(v interface{}) interface{} {
+	switch doc := v.(type) {
+	case map[string]interface{}:
+		for k, item := range doc {
+			if item == nil {
+				delete(doc, k)
+				continue
+			}
+			doc[k] = withoutNulls(item)
+		}
+	case []interface{}:
+		for i, item := range doc {
+			doc[i] = withoutNulls(item)
+		}
+	}
+	return v
+}
+
+// checkPattern verifies each field of the pattern is either a nested pattern or an
+// array of supported matches
+func checkPattern(pattern map[string]interface{}) error {
+	for key, v := range pattern {
+		switch p := v.(type) {
+		case map[string]interface{}:
+			if err := checkPattern(p); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, cond := range p {
+				if err := checkCondition(cond); err != nil {
+					return fmt.Errorf("%v: %w", key, err)
+				}
+			}
+		default:
+			return fmt.Errorf("%v: want object or array, got %v", key, v)
+		}
+	}
+	return nil
+}
+
+func checkCondition(cond interface{}) error {
+	if cond == nil {
+		return fmt.Errorf("null literals are unsupported; use exists")
+	}
+	op, ok := cond.(map[string]interface{})
+	if !ok {
+		return nil // literal
+	}
+	if len(op) != 1 {
+		return fmt.Errorf("want a single operator, got %v", cond)
+	}
+
+	for name, arg := range op {
+		switch name {
+		case "prefix", "suffix", "equals-ignore-case":
+			if _, ok := arg.(string); !ok {
+				return fmt.Errorf("%v requires a string, got %v", name, arg)
+			}
+		case "exists":
+			if _, ok := arg.(bool); !ok {
+				return fmt.Errorf("exists requires a bool, got %v", arg)
+			}
+		case "anything-but":
+			items, ok := arg.([]interface{})
+			if !ok {
+				items = []interface{}{arg}
+			}
+			for _, item := range items {
+				if err := checkCondition(item); err != nil {
+					return err
+				}
+			}
+		case "numeric":
+			if _, err := parseNumeric(arg); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported operator, %v", name)
+		}
+	}
+	return nil
+}
+
+// numericBound holds a single comparison of a numeric condition e.g. ">", 0
+type numericBound struct {
+	op    string
+	value float64
+}
+
+func parseNumeric(arg interface{}) ([]numericBound, error) {
+	items, ok := arg.([]interface{})
+	if !ok || len(items) == 0 || len(items)%2 != 0 {
+		return nil, fmt.Errorf("numeric requires operator and value pairs, got %v", arg)
+	}
+
+	var bounds []numericBound
+	for i := 0; i < len(items); i += 2 {
+		op, _ := items[i].(string)
+		value, ok := items[i+1].(float64)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("numeric requires a number, got %v", items[i+1])
+		case op != "=" && op != "<" && op != "<=" && op != ">" && op != ">=":
+			return nil, fmt.Errorf("unsupported numeric operator, %v", items[i])
+		}
+		bounds = append(bounds, numericBound{op: op, value: value})
+	}
+	return bounds, nil
+}
+
+// matchPattern returns true if doc matches each field of the pattern
+func matchPattern(pattern map[string]interface{}, doc interface{}) bool {
+	fields, _ := doc.(map[string]interface{})
+	for key, v := range pattern {
+		value, present := fields[key]
+		switch p := v.(type) {
+		case map[string]interface{}:
+			if !matchPattern(p, value) {
+				return false
+			}
+		case []interface{}:
+			if !matchConditions(p, value, present) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchConditions returns true if any condition matches the value or, when the value
+// is an array, any element of the value
+func matchConditions(conds []interface{}, value interface{}, present bool) bool {
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	for _, cond := range conds {
+		if op, ok := cond.(map[string]interface{}); ok {
+			if exists, ok := op["exists"]; ok {
+				if exists == present {
+					return true
+				}
+				continue
+			}
+		}
+		if !present {
+			continue
+		}
+		for _, v := range values {
+			if matchCondition(cond, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchCondition(cond, value interface{}) bool {
+	op, ok := cond.(map[string]interface{})
+	if !ok {
+		return cond == value
+	}
+
+	for name, arg := range op {
+		s, isString := value.(string)
+		switch name {
+		case "prefix":
+			return isString && strings.HasPrefix(s, arg.(string))
+		case "suffix":
+			return isString && strings.HasSuffix(s, arg.(string))
+		case "equals-ignore-case":
+			return isString && strings.EqualFold(s, arg.(string))
+		case "anything-but":
+			switch but := arg.(type) {
+			case []interface{}:
+				for _, item := range but {
+					if matchCondition(item, value) {
+						return false
+					}
+				}
+				return true
+			default:
+				return !matchCondition(but, value)
+			}
+		case "numeric":
+			return matchNumeric(arg, value)
+		}
+	}
+	return false
+}
+
+// matchNumeric returns true if value is a json number within the bounds of arg
+func matchNumeric(arg, value interface{}) bool {
+	n, ok := value.(float64)
+	if !ok {
+		return false
+	}
+
+	bounds, err := parseNumeric(arg)
+	if err != nil {
+		return false
+	}
+	for _, b := range bounds {
+		var ok bool
+		switch b.op {
+		case "=":
+			ok = n == b.value
+		case "<":
+			ok = n < b.value
+		case "<=":
+			ok = n <= b.value
+		case ">":
+			ok = n > b.value
+		case ">=":
+			ok = n >= b.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
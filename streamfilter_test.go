@@ -0,0 +1,146 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestFilter_Match(t *testing.T) {
+	record := Record{
+		EventName: "INSERT",
+		Change: Change{
+			ApproximateCreationDateTime: 1590277509,
+			Keys: map[string]*dynamodb.AttributeValue{
+				"ID": {S: aws.String("order#123")},
+			},
+			NewImage: map[string]*dynamodb.AttributeValue{
+				"ID":     {S: aws.String("order#123")},
+				"Status": {S: aws.String("Open")},
+				"Amount": {N: aws.String("25")},
+				"Tags":   {SS: aws.StringSlice([]string{"a", "b"})},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		Pattern string
+		Want    bool
+	}{
+		"event name": {
+			Pattern: `{"eventName": ["INSERT", "MODIFY"]}`,
+			Want:    true,
+		},
+		"event name mismatch": {
+			Pattern: `{"eventName": ["REMOVE"]}`,
+		},
+		"nested literal": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": {"S": ["Open"]}}}}`,
+			Want:    true,
+		},
+		"all fields must match": {
+			Pattern: `{"eventName": ["INSERT"], "dynamodb": {"NewImage": {"Status": {"S": ["Closed"]}}}}`,
+		},
+		"prefix": {
+			Pattern: `{"dynamodb": {"Keys": {"ID": {"S": [{"prefix": "order#"}]}}}}`,
+			Want:    true,
+		},
+		"suffix": {
+			Pattern: `{"dynamodb": {"Keys": {"ID": {"S": [{"suffix": "#999"}]}}}}`,
+		},
+		"equals ignore case": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": {"S": [{"equals-ignore-case": "open"}]}}}}`,
+			Want:    true,
+		},
+		"anything but": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": {"S": [{"anything-but": ["Closed", "Void"]}]}}}}`,
+			Want:    true,
+		},
+		"anything but prefix": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": {"S": [{"anything-but": {"prefix": "Op"}}]}}}}`,
+		},
+		"numeric": {
+			Pattern: `{"dynamodb": {"ApproximateCreationDateTime": [{"numeric": [">", 1500000000, "<=", 1600000000]}]}}`,
+			Want:    true,
+		},
+		"numeric out of range": {
+			Pattern: `{"dynamodb": {"ApproximateCreationDateTime": [{"numeric": ["<", 1500000000]}]}}`,
+		},
+		"numeric ignores numeric strings": {
+			Pattern: `{"dynamodb": {"NewImage": {"Amount": {"N": [{"numeric": [">", 10, "<=", 25]}]}}}}`,
+		},
+		"exists": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": [{"exists": true}]}}}`,
+			Want:    true,
+		},
+		"not exists": {
+			Pattern: `{"dynamodb": {"OldImage": {"Status": [{"exists": false}]}}}`,
+			Want:    true,
+		},
+		"unset attribute members are absent": {
+			Pattern: `{"dynamodb": {"NewImage": {"Status": {"N": [{"exists": false}]}}}}`,
+			Want:    true,
+		},
+		"array value": {
+			Pattern: `{"dynamodb": {"NewImage": {"Tags": {"SS": ["b"]}}}}`,
+			Want:    true,
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			got, err := MustFilter(tc.Pattern).Match(record)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+
+	t.Run("match any", func(t *testing.T) {
+		got, err := MatchAny(record, MustFilter(`{"eventName": ["REMOVE"]}`), MustFilter(`{"eventName": ["INSERT"]}`))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !got {
+			t.Fatalf("got false; want true")
+		}
+	})
+}
+
+func TestNewFilter(t *testing.T) {
+	invalid := map[string]string{
+		"not json":          `{`,
+		"literal field":     `{"eventName": "INSERT"}`,
+		"unknown operator":  `{"eventName": [{"contains": "IN"}]}`,
+		"numeric pairs":     `{"eventName": [{"numeric": [">"]}]}`,
+		"numeric operator":  `{"eventName": [{"numeric": ["!=", 1]}]}`,
+		"prefix type":       `{"eventName": [{"prefix": 1}]}`,
+		"null literal":      `{"eventName": [null]}`,
+		"null anything but": `{"eventName": [{"anything-but": [null]}]}`,
+	}
+	for label, pattern := range invalid {
+		t.Run(label, func(t *testing.T) {
+			if _, err := NewFilter(pattern); err == nil {
+				t.Fatalf("got nil; want err")
+			}
+		})
+	}
+}
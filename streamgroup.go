@@ -0,0 +1,71 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// KeyGroup holds the records of an event that modified a single item
+type KeyGroup struct {
+	Keys    map[string]*dynamodb.AttributeValue // Keys holds the primary key of the item
+	Records []Record                            // Records holds the changes to the item ordered by sequence number
+}
+
+// GroupByKey returns the records of the event grouped by the primary key of the item
+// modified.  Groups are returned in the order each item first appears and the records
+// of each group are ordered by sequence number, allowing handlers to process the
+// changes to each item in order even when the batch interleaves items.
+func (e Event) GroupByKey() []KeyGroup {
+	var (
+		groups  []KeyGroup
+		indexes = map[string]int{}
+	)
+	for _, record := range e.Records {
+		id := record.EventSourceARN
+		if data, err := json.Marshal(record.Change.Keys); err == nil {
+			id += string(data) // json orders map keys so equal keys encode identically
+		}
+
+		i, ok := indexes[id]
+		if !ok {
+			i = len(groups)
+			indexes[id] = i
+			groups = append(groups, KeyGroup{Keys: record.Change.Keys})
+		}
+		groups[i].Records = append(groups[i].Records, record)
+	}
+
+	for _, group := range groups {
+		records := group.Records
+		sort.SliceStable(records, func(i, j int) bool {
+			return lessSequenceNumber(records[i].Change.SequenceNumber, records[j].Change.SequenceNumber)
+		})
+	}
+
+	return groups
+}
+
+// lessSequenceNumber returns true if sequence number a precedes b.  Sequence numbers are
+// decimal strings that may exceed the range of int64 so are compared by length first.
+func lessSequenceNumber(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
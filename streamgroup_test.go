@@ -0,0 +1,69 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestEvent_GroupByKey(t *testing.T) {
+	record := func(id, seq string) Record {
+		return Record{
+			EventID: id + seq,
+			Change: Change{
+				Keys:           map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+				SequenceNumber: seq,
+			},
+		}
+	}
+
+	event := Event{
+		Records: []Record{
+			record("a", "100"),
+			record("b", "200"),
+			record("a", "99"),
+			record("b", "300"),
+			record("a", "1000"),
+		},
+	}
+
+	groups := event.GroupByKey()
+	if got, want := len(groups), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var got [][]string
+	for _, group := range groups {
+		var ids []string
+		for _, r := range group.Records {
+			ids = append(ids, r.EventID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{
+		{"a99", "a100", "a1000"},
+		{"b200", "b300"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(groups[1].Keys["id"].S), "b"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
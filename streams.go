@@ -16,9 +16,12 @@ package ddb
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
@@ -34,7 +37,23 @@ func (e EpochSeconds) MarshalJSON() ([]byte, error) {
 func (e *EpochSeconds) UnmarshalJSON(data []byte) error {
 	var v float64
 	if err := json.Unmarshal(data, &v); err != nil {
-		return nil
+		return err
+	}
+	*e = EpochSeconds(v)
+	return nil
+}
+
+// MarshalDynamoDBAttributeValue implements dynamodbattribute.Marshaler
+func (e EpochSeconds) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	item.N = aws.String(strconv.FormatInt(int64(e), 10))
+	return nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements dynamodbattribute.Unmarshaler
+func (e *EpochSeconds) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	v, err := parseEpoch(item)
+	if err != nil {
+		return err
 	}
 	*e = EpochSeconds(v)
 	return nil
@@ -45,6 +64,62 @@ func (e EpochSeconds) Time() time.Time {
 	return time.Unix(int64(e), 0)
 }
 
+// EpochMillis expresses time in unix milliseconds
+type EpochMillis int64
+
+// MarshalJSON implements json.Marshaler
+func (e EpochMillis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(e))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (e *EpochMillis) UnmarshalJSON(data []byte) error {
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = EpochMillis(v)
+	return nil
+}
+
+// MarshalDynamoDBAttributeValue implements dynamodbattribute.Marshaler
+func (e EpochMillis) MarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	item.N = aws.String(strconv.FormatInt(int64(e), 10))
+	return nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements dynamodbattribute.Unmarshaler
+func (e *EpochMillis) UnmarshalDynamoDBAttributeValue(item *dynamodb.AttributeValue) error {
+	v, err := parseEpoch(item)
+	if err != nil {
+		return err
+	}
+	*e = EpochMillis(v)
+	return nil
+}
+
+// Time returns time.Time
+func (e EpochMillis) Time() time.Time {
+	return time.Unix(0, int64(e)*int64(time.Millisecond))
+}
+
+// parseEpoch reads the numeric value of the attribute; null or missing values are zero
+func parseEpoch(item *dynamodb.AttributeValue) (int64, error) {
+	if item == nil || item.N == nil {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(*item.N, 10, 64)
+	if err != nil {
+		f, e := strconv.ParseFloat(*item.N, 64)
+		if e != nil {
+			return 0, fmt.Errorf("failed to parse epoch, %v: %w", *item.N, err)
+		}
+		v = int64(f)
+	}
+	return v, nil
+}
+
 // Window refers to the tumbling window
 // https://aws.amazon.com/blogs/compute/using-aws-lambda-for-streaming-analytics/
 type Window struct {
@@ -55,9 +130,15 @@ type Window struct {
 // Change represents the change performed
 type Change struct {
 	// The approximate date and time when the stream record was created, in UNIX
-	// epoch time (http://www.epochconverter.com/) format.
+	// epoch time (http://www.epochconverter.com/) format.  Records delivered via
+	// Kinesis express this in the unit given by ApproximateCreationDateTimePrecision;
+	// use CreatedAt to read it independent of delivery.
 	ApproximateCreationDateTime EpochSeconds `json:"ApproximateCreationDateTime,omitempty"`
 
+	// ApproximateCreationDateTimePrecision is one of MILLISECOND or MICROSECOND for
+	// records delivered via Kinesis and blank for records read from dynamodb streams
+	ApproximateCreationDateTimePrecision string `json:"ApproximateCreationDateTimePrecision,omitempty"`
+
 	// Keys for dynamodb modified dynamodb item
 	Keys map[string]*dynamodb.AttributeValue `json:"Keys,omitempty"`
 
@@ -78,6 +159,24 @@ type Change struct {
 	StreamViewType string `json:"StreamViewType"`
 }
 
+const (
+	precisionMillisecond = "MILLISECOND"
+	precisionMicrosecond = "MICROSECOND"
+)
+
+// CreatedAt returns the approximate time the stream record was created
+func (c Change) CreatedAt() time.Time {
+	v := int64(c.ApproximateCreationDateTime)
+	switch c.ApproximateCreationDateTimePrecision {
+	case precisionMillisecond:
+		return EpochMillis(v).Time()
+	case precisionMicrosecond:
+		return time.Unix(0, v*int64(time.Microsecond))
+	default:
+		return EpochSeconds(v).Time()
+	}
+}
+
 // Record holds the metadata for the dynamodb change
 type Record struct {
 	// AWSRegion update occurred within
@@ -95,6 +194,22 @@ type Record struct {
 	EventSourceARN string `json:"eventSourceARN"`
 	// EventVersion number of the stream format
 	EventVersion string `json:"eventVersion"`
+	// RecordFormat will be application/json for records delivered via Kinesis
+	RecordFormat string `json:"recordFormat,omitempty"`
+	// Table holds the name of the table for records delivered via Kinesis; records
+	// read from dynamodb streams only carry the table name within EventSourceARN
+	Table string `json:"tableName,omitempty"`
+	// UserIdentity identifies the principal that made the change; only set for items
+	// removed by TTL
+	UserIdentity *UserIdentity `json:"userIdentity,omitempty"`
+}
+
+// UserIdentity identifies the principal responsible for a stream record
+type UserIdentity struct {
+	// PrincipalID will be dynamodb.amazonaws.com for items removed by TTL
+	PrincipalID string `json:"principalId"`
+	// Type will be Service for items removed by TTL
+	Type string `json:"type"`
 }
 
 // Event record emitted by dynamodb streams.
@@ -127,6 +242,14 @@ type Event struct {
 	Window *Window `json:"window,omitempty"`
 }
 
+// TableName returns the name of the table that generated the record
+func (r Record) TableName() (string, bool) {
+	if r.Table != "" {
+		return r.Table, true
+	}
+	return TableName(r.EventSourceARN)
+}
+
 var reTableName = regexp.MustCompile(`\d{12}:table/([^/]+)/`)
 
 // TableName returns the table name for a given record
@@ -16,8 +16,13 @@ package ddb
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"testing"
 	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 func TestTableName(t *testing.T) {
@@ -127,4 +132,145 @@ func TestEpochSeconds_JSON(t *testing.T) {
 			t.Fatalf("got %v; want %v", got, want)
 		}
 	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var seconds EpochSeconds
+		if err := json.Unmarshal([]byte(`"abc"`), &seconds); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+
+		var millis EpochMillis
+		if err := json.Unmarshal([]byte(`"abc"`), &millis); err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
+func TestEpoch_DynamoDBAttributeValue(t *testing.T) {
+	type T struct {
+		Seconds EpochSeconds
+		Millis  EpochMillis
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		want := T{
+			Seconds: 1590277509,
+			Millis:  1590277509123,
+		}
+		item, err := dynamodbattribute.MarshalMap(want)
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(item["Seconds"].N), "1590277509"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(item["Millis"].N), "1590277509123"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		var got T
+		if err := dynamodbattribute.UnmarshalMap(item, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var got EpochSeconds
+		err := got.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{N: aws.String("abc")})
+		if err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+}
+
+func TestEpochMillis(t *testing.T) {
+	want := EpochMillis(1590277509123)
+	if got := want.Time().UnixNano() / int64(time.Millisecond); got != int64(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var got EpochMillis
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestRecord_Payloads(t *testing.T) {
+	t.Run("lambda", func(t *testing.T) {
+		data, err := ioutil.ReadFile("testdata/streams/lambda.json")
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(event.Records), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		insert, remove := event.Records[0], event.Records[1]
+		if insert.UserIdentity != nil || insert.IsExpired() {
+			t.Fatalf("got %v; want nil", insert.UserIdentity)
+		}
+		if !remove.IsExpired() {
+			t.Fatalf("got false; want true")
+		}
+		if got, want := insert.Change.CreatedAt().Unix(), int64(1590417190); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, _ := insert.TableName(); got != "ExampleTableWithStream" {
+			t.Fatalf("got %v; want ExampleTableWithStream", got)
+		}
+	})
+
+	t.Run("kinesis", func(t *testing.T) {
+		data, err := ioutil.ReadFile("testdata/streams/kinesis.json")
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := record.RecordFormat, "application/json"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := record.Change.CreatedAt(), time.Unix(1590417190, 123*int64(time.Millisecond)); !got.Equal(want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, ok := record.TableName(); !ok || got != "ExampleTableWithStream" {
+			t.Fatalf("got %v; want ExampleTableWithStream", got)
+		}
+		if record.UserIdentity != nil {
+			t.Fatalf("got %v; want nil", record.UserIdentity)
+		}
+		if got, want := aws.StringValue(record.Change.NewImage["Message"].S), "This item has changed"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestChange_CreatedAt(t *testing.T) {
+	want := time.Unix(1590417190, 123456*int64(time.Microsecond))
+	change := Change{
+		ApproximateCreationDateTime:          1590417190123456,
+		ApproximateCreationDateTimePrecision: "MICROSECOND",
+	}
+	if got := change.CreatedAt(); !got.Equal(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
 }
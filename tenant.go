@@ -0,0 +1,423 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+const (
+	tenantSeparator = "#"       // tenantSeparator separates the tenant from the hash key
+	tenantName      = "#tenant" // tenantName holds the placeholder used by tenant filters
+	tenantValue     = ":tenant" // tenantValue holds the placeholder used by tenant filters
+)
+
+// keyEquals matches the equality conditions of a key condition expression
+var keyEquals = regexp.MustCompile(`(#?[A-Za-z0-9_]+)\s*=\s*(:[A-Za-z0-9_]+)`)
+
+// tenantKey qualifies key by the tenant of ctx so coalesced and cached results are never
+// shared between tenants
+func tenantKey(ctx context.Context, tenant func(ctx context.Context) string, key string) string {
+	if tenant == nil {
+		return key
+	}
+	id := tenant(ctx)
+	return strconv.Itoa(len(id)) + ":" + id + key
+}
+
+// tenantAPI decorates the dynamodb api, scoping each request to the table, tableName,
+// to the tenant of the request context by prefixing the hash key with the tenant
+type tenantAPI struct {
+	dynamodbiface.DynamoDBAPI
+	tableName string
+	hashKey   string
+	tenant    func(ctx context.Context) string
+}
+
+// prefix returns the hash key prefix for the tenant of ctx
+func (t *tenantAPI) prefix(ctx context.Context) (string, error) {
+	tenant := t.tenant(ctx)
+	if tenant == "" {
+		return "", &baseError{
+			code:      ErrMissingTenant,
+			message:   fmt.Sprintf("no tenant provided for request to table, %v", t.tableName),
+			tableName: t.tableName,
+		}
+	}
+	if strings.Contains(tenant, tenantSeparator) {
+		// a tenant containing the separator would share a prefix with another tenant e.g.
+		// tenant a, key b#x and tenant a#b, key x
+		return "", &baseError{
+			code:      ErrInvalidTenant,
+			message:   fmt.Sprintf("invalid tenant, %v, for request to table, %v: tenant may not contain %v", tenant, t.tableName, tenantSeparator),
+			tableName: t.tableName,
+		}
+	}
+	return tenant + tenantSeparator, nil
+}
+
+// scope returns a copy of item with the hash key prefixed
+func (t *tenantAPI) scope(item map[string]*dynamodb.AttributeValue, prefix string) (map[string]*dynamodb.AttributeValue, error) {
+	av, ok := item[t.hashKey]
+	if !ok {
+		return item, nil
+	}
+	if av == nil || av.S == nil {
+		return nil, fmt.Errorf("tenant scoping requires a string hash key: %v of table, %v, is not a string", t.hashKey, t.tableName)
+	}
+
+	dup := make(map[string]*dynamodb.AttributeValue, len(item))
+	for k, v := range item {
+		dup[k] = v
+	}
+	dup[t.hashKey] = &dynamodb.AttributeValue{S: aws.String(prefix + *av.S)}
+	return dup, nil
+}
+
+// unscope removes the tenant prefix from the hash key of item
+func (t *tenantAPI) unscope(item map[string]*dynamodb.AttributeValue, prefix string) {
+	if av, ok := item[t.hashKey]; ok && av != nil && av.S != nil && strings.HasPrefix(*av.S, prefix) {
+		item[t.hashKey] = &dynamodb.AttributeValue{S: aws.String(strings.TrimPrefix(*av.S, prefix))}
+	}
+}
+
+// scopeKeyCondition prefixes the values compared to the hash key within the key
+// condition.  ok is false if the key condition does not reference the hash key, as
+// when querying a global secondary index.
+func (t *tenantAPI) scopeKeyCondition(input *dynamodb.QueryInput, prefix string) (ok bool, err error) {
+	values := make(map[string]*dynamodb.AttributeValue, len(input.ExpressionAttributeValues))
+	for k, v := range input.ExpressionAttributeValues {
+		values[k] = v
+	}
+
+	for _, match := range keyEquals.FindAllStringSubmatch(aws.StringValue(input.KeyConditionExpression), -1) {
+		name, placeholder := match[1], match[2]
+		if strings.HasPrefix(name, "#") {
+			name = aws.StringValue(input.ExpressionAttributeNames[name])
+		}
+		if name != t.hashKey {
+			continue
+		}
+
+		av := values[placeholder]
+		if av == nil || av.S == nil {
+			return false, fmt.Errorf("tenant scoping requires a string hash key: %v of table, %v, is not a string", t.hashKey, t.tableName)
+		}
+		values[placeholder] = &dynamodb.AttributeValue{S: aws.String(prefix + *av.S)}
+		ok = true
+	}
+
+	input.ExpressionAttributeValues = values
+	return ok, nil
+}
+
+// tenantFilter returns the filter expression, names, and values with an additional
+// condition that the hash key begins with prefix
+func (t *tenantAPI) tenantFilter(filter *string, names map[string]*string, values map[string]*dynamodb.AttributeValue, prefix string) (*string, map[string]*string, map[string]*dynamodb.AttributeValue) {
+	expr := "begins_with(" + tenantName + ", " + tenantValue + ")"
+	if v := aws.StringValue(filter); v != "" {
+		expr = "(" + v + ") and " + expr
+	}
+
+	dupNames := map[string]*string{tenantName: aws.String(t.hashKey)}
+	for k, v := range names {
+		dupNames[k] = v
+	}
+	dupValues := map[string]*dynamodb.AttributeValue{tenantValue: {S: aws.String(prefix)}}
+	for k, v := range values {
+		dupValues[k] = v
+	}
+
+	return aws.String(expr), dupNames, dupValues
+}
+
+func (t *tenantAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.DeleteItemWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	if scoped.Key, err = t.scope(input.Key, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.DeleteItemWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		t.unscope(output.Attributes, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.GetItemWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	if scoped.Key, err = t.scope(input.Key, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.GetItemWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		t.unscope(output.Item, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.PutItemWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	if scoped.Item, err = t.scope(input.Item, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.PutItemWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		t.unscope(output.Attributes, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.QueryWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	ok, err := t.scopeKeyCondition(&scoped, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		scoped.FilterExpression, scoped.ExpressionAttributeNames, scoped.ExpressionAttributeValues = t.tenantFilter(
+			scoped.FilterExpression, scoped.ExpressionAttributeNames, scoped.ExpressionAttributeValues, prefix)
+	}
+	if scoped.ExclusiveStartKey, err = t.scope(input.ExclusiveStartKey, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.QueryWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		for _, item := range output.Items {
+			t.unscope(item, prefix)
+		}
+		t.unscope(output.LastEvaluatedKey, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.ScanWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	scoped.FilterExpression, scoped.ExpressionAttributeNames, scoped.ExpressionAttributeValues = t.tenantFilter(
+		input.FilterExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues, prefix)
+	if scoped.ExclusiveStartKey, err = t.scope(input.ExclusiveStartKey, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.ScanWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		for _, item := range output.Items {
+			t.unscope(item, prefix)
+		}
+		t.unscope(output.LastEvaluatedKey, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if aws.StringValue(input.TableName) != t.tableName {
+		return t.DynamoDBAPI.UpdateItemWithContext(ctx, input, opts...)
+	}
+
+	prefix, err := t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *input
+	if scoped.Key, err = t.scope(input.Key, prefix); err != nil {
+		return nil, err
+	}
+
+	output, err := t.DynamoDBAPI.UpdateItemWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		t.unscope(output.Attributes, prefix)
+	}
+	return output, err
+}
+
+func (t *tenantAPI) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	var (
+		prefix string
+		scoped = *input
+		owned  = make([]bool, len(input.TransactItems))
+	)
+
+	scoped.TransactItems = make([]*dynamodb.TransactGetItem, len(input.TransactItems))
+	for i, item := range input.TransactItems {
+		scoped.TransactItems[i] = item
+		if item.Get == nil || aws.StringValue(item.Get.TableName) != t.tableName {
+			continue
+		}
+
+		if prefix == "" {
+			v, err := t.prefix(ctx)
+			if err != nil {
+				return nil, err
+			}
+			prefix = v
+		}
+
+		get := *item.Get
+		key, err := t.scope(get.Key, prefix)
+		if err != nil {
+			return nil, err
+		}
+		get.Key = key
+		scoped.TransactItems[i] = &dynamodb.TransactGetItem{Get: &get}
+		owned[i] = true
+	}
+
+	output, err := t.DynamoDBAPI.TransactGetItemsWithContext(ctx, &scoped, opts...)
+	if output != nil {
+		for i, response := range output.Responses {
+			if i < len(owned) && owned[i] && response != nil {
+				t.unscope(response.Item, prefix)
+			}
+		}
+	}
+	return output, err
+}
+
+func (t *tenantAPI) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	var (
+		prefix string
+		scoped = *input
+	)
+
+	scope := func(tableName *string, item map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+		if aws.StringValue(tableName) != t.tableName {
+			return item, nil
+		}
+		if prefix == "" {
+			v, err := t.prefix(ctx)
+			if err != nil {
+				return nil, err
+			}
+			prefix = v
+		}
+		return t.scope(item, prefix)
+	}
+
+	scoped.TransactItems = make([]*dynamodb.TransactWriteItem, len(input.TransactItems))
+	for i, item := range input.TransactItems {
+		dup := *item
+		var err error
+		switch {
+		case item.ConditionCheck != nil:
+			v := *item.ConditionCheck
+			v.Key, err = scope(v.TableName, v.Key)
+			dup.ConditionCheck = &v
+		case item.Delete != nil:
+			v := *item.Delete
+			v.Key, err = scope(v.TableName, v.Key)
+			dup.Delete = &v
+		case item.Put != nil:
+			v := *item.Put
+			v.Item, err = scope(v.TableName, v.Item)
+			dup.Put = &v
+		case item.Update != nil:
+			v := *item.Update
+			v.Key, err = scope(v.TableName, v.Key)
+			dup.Update = &v
+		}
+		if err != nil {
+			return nil, err
+		}
+		scoped.TransactItems[i] = &dup
+	}
+
+	return t.DynamoDBAPI.TransactWriteItemsWithContext(ctx, &scoped, opts...)
+}
+
+// WithTenant returns a copy of the table whose requests are scoped to the tenant
+// returned by fn for the request context.  The hash key, which must be a string, is
+// stored prefixed with the tenant and a separator, #, and the prefix is removed from
+// items read.  Queries of global secondary indexes and scans are filtered to items of
+// the tenant.  Requests for which fn returns blank fail with the code, ErrMissingTenant,
+// and those for which fn returns a tenant containing # fail with ErrInvalidTenant.
+//
+// Transactions are scoped when run via the DDB of the returned table e.g.
+// table.DDB().TransactWriteItemsWithContext(ctx, ...).  Condition and filter
+// expressions that compare the hash key see the prefixed value.
+func (t *Table) WithTenant(fn func(ctx context.Context) string) *Table {
+	if t.spec.HashKey == nil {
+		panic(fmt.Errorf("WithTenant requires a hash key: none defined for %v", t.tableName))
+	}
+
+	db := t.ddb.clone()
+	db.api = &tenantAPI{
+		DynamoDBAPI: db.api,
+		tableName:   t.spec.TableName,
+		hashKey:     t.spec.HashKey.AttributeName,
+		tenant:      fn,
+	}
+	dup := t.clone()
+	dup.ddb = db
+	dup.tenant = fn
+	return dup
+}
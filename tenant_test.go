@@ -0,0 +1,182 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type tenantContextKey struct{}
+
+func tenantOf(ctx context.Context) string {
+	v, _ := ctx.Value(tenantContextKey{}).(string)
+	return v
+}
+
+func TestTable_WithTenant(t *testing.T) {
+	var (
+		ctx   = context.WithValue(context.Background(), tenantContextKey{}, "t1")
+		mock  = &Mock{}
+		table = New(mock).MustTable("example", Example{}).WithTenant(tenantOf)
+	)
+
+	t.Run("put", func(t *testing.T) {
+		if err := table.Put(Example{ID: "abc"}).RunWithContext(ctx); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.putInput.Item["ID"].S), "t1#abc"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mock.getItem = Example{ID: "t1#abc", Name: "name"}
+		defer func() { mock.getItem = nil }()
+
+		var got Example
+		if err := table.Get("abc").ScanWithContext(ctx, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if want := "t1#abc"; aws.StringValue(mock.getInput.Key["ID"].S) != want {
+			t.Fatalf("got %v; want %v", aws.StringValue(mock.getInput.Key["ID"].S), want)
+		}
+		if want := (Example{ID: "abc", Name: "name"}); got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		mock.scanItems = nil
+		if err := table.Scan().EachWithContext(ctx, func(item Item) (bool, error) { return true, nil }); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(mock.scanInput.FilterExpression), "begins_with(#tenant, :tenant)"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(mock.scanInput.ExpressionAttributeValues[":tenant"].S), "t1#"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("missing tenant", func(t *testing.T) {
+		err := table.Put(Example{ID: "abc"}).RunWithContext(context.Background())
+		if !IsMissingTenantError(err) {
+			t.Fatalf("got %v; want ErrMissingTenant", err)
+		}
+	})
+}
+
+func TestTenantAPI_scopeKeyCondition(t *testing.T) {
+	api := &tenantAPI{tableName: "example", hashKey: "ID"}
+
+	input := &dynamodb.QueryInput{
+		KeyConditionExpression:   aws.String("#n1 = :v1 and #n2 > :v2"),
+		ExpressionAttributeNames: map[string]*string{"#n1": aws.String("ID"), "#n2": aws.String("Date")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v1": {S: aws.String("abc")},
+			":v2": {S: aws.String("2020")},
+		},
+	}
+	ok, err := api.scopeKeyCondition(input, "t1#")
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if !ok {
+		t.Fatalf("got false; want true")
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeValues[":v1"].S), "t1#abc"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := aws.StringValue(input.ExpressionAttributeValues[":v2"].S), "2020"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	t.Run("index", func(t *testing.T) {
+		input := &dynamodb.QueryInput{
+			KeyConditionExpression:    aws.String("#n1 = :v1"),
+			ExpressionAttributeNames:  map[string]*string{"#n1": aws.String("Name")},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":v1": {S: aws.String("abc")}},
+		}
+		if ok, err := api.scopeKeyCondition(input, "t1#"); err != nil || ok {
+			t.Fatalf("got %v, %v; want false, nil", ok, err)
+		}
+	})
+}
+
+func TestTable_WithTenantQueryCache(t *testing.T) {
+	var (
+		mock  = &Mock{queryItems: []interface{}{Example{ID: "t1#abc"}}}
+		table = New(mock).MustTable("example", Example{}).WithQueryCache(time.Minute).WithTenant(tenantOf)
+		t1    = context.WithValue(context.Background(), tenantContextKey{}, "t1")
+		t2    = context.WithValue(context.Background(), tenantContextKey{}, "t2")
+	)
+
+	var got []Example
+	if err := table.Query("#ID = ?", "abc").FindAllWithContext(t1, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	mock.queryInput = nil
+	if err := table.Query("#ID = ?", "abc").FindAllWithContext(t2, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if mock.queryInput == nil {
+		t.Fatalf("got cached page; want query for second tenant")
+	}
+	if got, want := aws.StringValue(mock.queryInput.ExpressionAttributeValues[":v1"].S), "t2#abc"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestTable_WithTenantSeparator(t *testing.T) {
+	var (
+		tenantA  = context.WithValue(context.Background(), tenantContextKey{}, "a")
+		tenantAB = context.WithValue(context.Background(), tenantContextKey{}, "a#b")
+		mock     = &Mock{}
+		table    = New(mock).MustTable("example", Example{}).WithTenant(tenantOf)
+	)
+
+	// tenant a may use keys containing the separator
+	if err := table.Put(Example{ID: "b#x"}).RunWithContext(tenantA); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(mock.putInput.Item["ID"].S), "a#b#x"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// tenant a#b would otherwise read tenant a's item, b#x, as its own item, x
+	mock.getInput = nil
+	var got Example
+	err := table.Get("x").ScanWithContext(tenantAB, &got)
+	if !IsInvalidTenantError(err) {
+		t.Fatalf("got %v; want ErrInvalidTenant", err)
+	}
+	if mock.getInput != nil {
+		t.Fatalf("got request to DynamoDB; want none")
+	}
+
+	mock.scanInput = nil
+	err = table.Scan().EachWithContext(tenantAB, func(item Item) (bool, error) { return true, nil })
+	if !IsInvalidTenantError(err) {
+		t.Fatalf("got %v; want ErrInvalidTenant", err)
+	}
+	if mock.scanInput != nil {
+		t.Fatalf("got request to DynamoDB; want none")
+	}
+}
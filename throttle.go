@@ -0,0 +1,89 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// retryPolicy determines how throttled page reads by Query and Scan are retried.  The
+// zero value makes a single attempt.
+type retryPolicy struct {
+	attempts  int                     // attempts holds the max number of times a page read will be attempted
+	backoff   func(int) time.Duration // backoff provides the delay following the given attempt
+	retryable func(error) bool        // retryable, if set, overrides isThrottleError as the test for errors to retry
+	clock     Clock                   // clock, if set, times the backoff; defaults to the system clock
+}
+
+// isThrottleError returns true if err indicates the request was throttled by DynamoDB
+func isThrottleError(err error) bool {
+	return isErrorClass(err, classThrottling)
+}
+
+// isNetworkError returns true if err indicates a transient failure of the connection to
+// DynamoDB, such as a connection reset or timeout, after which an idempotent request
+// may safely be retried
+func isNetworkError(err error) bool {
+	for err != nil {
+		if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() {
+			return true
+		}
+
+		// awserr.Error exposes its cause via OrigErr rather than Unwrap
+		var ae awserr.Error
+		if !errors.As(err, &ae) {
+			return false
+		}
+		err = ae.OrigErr()
+	}
+	return false
+}
+
+// do invokes fn, retrying with backoff so long as fn returns a throttling error, or an
+// error accepted by retryable, and attempts remain
+func (r retryPolicy) do(ctx context.Context, fn func() error) error {
+	retryable := r.retryable
+	if retryable == nil {
+		retryable = isThrottleError
+	}
+	clock := r.clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= r.attempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(r.backoff(attempt)):
+		}
+	}
+}
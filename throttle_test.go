@@ -0,0 +1,337 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// throttleMock returns one item per page, throttling each page the configured number
+// of times before succeeding
+type throttleMock struct {
+	*Mock
+	pages int         // pages holds the total number of pages
+	fail  map[int]int // fail holds the number of times each page will be throttled
+}
+
+func (m *throttleMock) page(startKey map[string]*dynamodb.AttributeValue) (items []map[string]*dynamodb.AttributeValue, lastKey map[string]*dynamodb.AttributeValue, err error) {
+	var page int
+	if v, ok := startKey["page"]; ok {
+		page, _ = strconv.Atoi(aws.StringValue(v.N))
+	}
+
+	if m.fail[page] > 0 {
+		m.fail[page]--
+		return nil, nil, awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+	}
+
+	item, err := marshalMap(Example{ID: strconv.Itoa(page)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if page+1 < m.pages {
+		lastKey = map[string]*dynamodb.AttributeValue{"page": {N: aws.String(strconv.Itoa(page + 1))}}
+	}
+
+	return []map[string]*dynamodb.AttributeValue{item}, lastKey, nil
+}
+
+func (m *throttleMock) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	items, lastKey, err := m.page(input.ExclusiveStartKey)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.QueryOutput{Items: items, LastEvaluatedKey: lastKey}, nil
+}
+
+func (m *throttleMock) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	items, lastKey, err := m.page(input.ExclusiveStartKey)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.ScanOutput{Items: items, LastEvaluatedKey: lastKey}, nil
+}
+
+func noBackoff(int) time.Duration {
+	return 0
+}
+
+// collect returns a callback that records the ids of the items seen
+func collect(ids *[]string) func(item Item) (bool, error) {
+	return func(item Item) (bool, error) {
+		var v Example
+		if err := item.Unmarshal(&v); err != nil {
+			return false, err
+		}
+		*ids = append(*ids, v.ID)
+		return true, nil
+	}
+}
+
+func Test_isThrottleError(t *testing.T) {
+	testCases := map[string]struct {
+		Err  error
+		Want bool
+	}{
+		"nil": {
+			Err: nil,
+		},
+		"other": {
+			Err: io.EOF,
+		},
+		"provisioned": {
+			Err:  awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil),
+			Want: true,
+		},
+		"request limit": {
+			Err:  awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "", nil),
+			Want: true,
+		},
+		"conditional": {
+			Err: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil),
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got, want := isThrottleError(tc.Err), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestQuery_Throttled(t *testing.T) {
+	t.Run("retries in place", func(t *testing.T) {
+		var (
+			mock  = &throttleMock{Mock: &Mock{}, pages: 3, fail: map[int]int{1: 3}}
+			table = New(mock).WithPageRetry(4, noBackoff).MustTable("example", Example{})
+			ids   []string
+		)
+
+		err := table.Query("#ID = ?", "abc").Each(collect(&ids))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := len(ids), 3; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("resumes once exhausted", func(t *testing.T) {
+		var (
+			mock  = &throttleMock{Mock: &Mock{}, pages: 3, fail: map[int]int{1: 2}}
+			table = New(mock).WithPageRetry(2, noBackoff).MustTable("example", Example{})
+			ids   []string
+		)
+
+		err := table.Query("#ID = ?", "abc").Each(collect(&ids))
+		if !IsThrottledError(err) {
+			t.Fatalf("got %v; want ErrThrottled", err)
+		}
+
+		var re *ResumeError
+		if !errors.As(err, &re) {
+			t.Fatalf("got %T; want *ResumeError", err)
+		}
+		if got, want := aws.StringValue(re.ResumeKey["page"].N), "1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(ids), 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		err = table.Query("#ID = ?", "abc").StartToken(re.ResumeToken).Each(collect(&ids))
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := ids, []string{"0", "1", "2"}; len(got) != len(want) || got[1] != want[1] || got[2] != want[2] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
+
+func TestScan_Throttled(t *testing.T) {
+	var (
+		mock  = &throttleMock{Mock: &Mock{}, pages: 3, fail: map[int]int{2: 1}}
+		table = New(mock).WithPageRetry(1, nil).MustTable("example", Example{})
+		ids   []string
+	)
+
+	err := table.Scan().Each(collect(&ids))
+	var re *ResumeError
+	if !errors.As(err, &re) {
+		t.Fatalf("got %v; want *ResumeError", err)
+	}
+	if got, want := len(ids), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	err = table.Scan().StartToken(re.ResumeToken).Each(collect(&ids))
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := len(ids), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	err = table.Scan().StartToken(re.ResumeToken).TotalSegments(2).Each(collect(&ids))
+	if err == nil {
+		t.Fatalf("got nil; want err")
+	}
+}
+
+func Test_isNetworkError(t *testing.T) {
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	testCases := map[string]struct {
+		Err  error
+		Want bool
+	}{
+		"nil": {
+			Err: nil,
+		},
+		"other": {
+			Err: io.EOF,
+		},
+		"reset": {
+			Err:  reset,
+			Want: true,
+		},
+		"request error": {
+			Err:  awserr.New(request.ErrCodeRequestError, "send request failed", reset),
+			Want: true,
+		},
+		"unexpected eof": {
+			Err:  awserr.New(request.ErrCodeSerialization, "failed to read", io.ErrUnexpectedEOF),
+			Want: true,
+		},
+		"timeout": {
+			Err:  &net.DNSError{IsTimeout: true},
+			Want: true,
+		},
+		"throttled": {
+			Err: awserr.New(dynamodb.ErrCodeRequestLimitExceeded, "", nil),
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got, want := isNetworkError(tc.Err), tc.Want; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+// resetMock fails the first failures calls to GetItem and Query with a connection reset
+type resetMock struct {
+	*Mock
+	calls    int
+	failures int
+}
+
+func (r *resetMock) fail() error {
+	r.calls++
+	if r.calls <= r.failures {
+		reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		return awserr.New(request.ErrCodeRequestError, "send request failed", reset)
+	}
+	return nil
+}
+
+func (r *resetMock) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := r.fail(); err != nil {
+		return nil, err
+	}
+	return r.Mock.GetItemWithContext(ctx, input, opts...)
+}
+
+func (r *resetMock) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := r.fail(); err != nil {
+		return nil, err
+	}
+	return r.Mock.QueryWithContext(ctx, input, opts...)
+}
+
+func TestDDB_WithNetworkRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get", func(t *testing.T) {
+		var (
+			mock  = &resetMock{Mock: &Mock{getItem: Example{ID: "abc"}}, failures: 2}
+			table = New(mock).WithNetworkRetry(3, noBackoff).MustTable("example", Example{})
+			got   Example
+		)
+		if err := table.Get("abc").ScanWithContext(ctx, &got); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := mock.calls, 3; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var (
+			mock  = &resetMock{Mock: &Mock{}, failures: 1}
+			table = New(mock).WithNetworkRetry(2, noBackoff).MustTable("example", Example{})
+		)
+		if err := table.Query("#ID = ?", "abc").EachWithContext(ctx, func(Item) (bool, error) { return true, nil }); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := mock.calls, 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		var (
+			mock  = &resetMock{Mock: &Mock{}, failures: 3}
+			table = New(mock).WithNetworkRetry(2, noBackoff).MustTable("example", Example{})
+		)
+		if _, err := table.Get("abc").Exists(ctx); !isNetworkError(err) {
+			t.Fatalf("got %v; want network error", err)
+		}
+		if got, want := mock.calls, 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		var (
+			mock  = &resetMock{Mock: &Mock{}, failures: 1}
+			table = New(mock).MustTable("example", Example{})
+		)
+		if _, err := table.Get("abc").Exists(ctx); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+		if got, want := mock.calls, 1; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// timeoutOperations holds the operations that accept a default timeout
+var timeoutOperations = map[string]struct{}{
+	"CreateTable":        {},
+	"DeleteItem":         {},
+	"DeleteTable":        {},
+	"GetItem":            {},
+	"PutItem":            {},
+	"Query":              {},
+	"Scan":               {},
+	"TransactGetItems":   {},
+	"TransactWriteItems": {},
+	"UpdateItem":         {},
+}
+
+// timeoutAPI decorates the dynamodb api, applying timeout to calls of operation whose
+// context has no deadline
+type timeoutAPI struct {
+	dynamodbiface.DynamoDBAPI
+	operation string
+	timeout   time.Duration
+}
+
+func (t *timeoutAPI) withTimeout(ctx aws.Context, operation string) (aws.Context, context.CancelFunc) {
+	if operation != t.operation {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+func (t *timeoutAPI) CreateTableWithContext(ctx aws.Context, input *dynamodb.CreateTableInput, opts ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "CreateTable")
+	defer cancel()
+	return t.DynamoDBAPI.CreateTableWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "DeleteItem")
+	defer cancel()
+	return t.DynamoDBAPI.DeleteItemWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) DeleteTableWithContext(ctx aws.Context, input *dynamodb.DeleteTableInput, opts ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "DeleteTable")
+	defer cancel()
+	return t.DynamoDBAPI.DeleteTableWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "GetItem")
+	defer cancel()
+	return t.DynamoDBAPI.GetItemWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "PutItem")
+	defer cancel()
+	return t.DynamoDBAPI.PutItemWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "Query")
+	defer cancel()
+	return t.DynamoDBAPI.QueryWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "Scan")
+	defer cancel()
+	return t.DynamoDBAPI.ScanWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "TransactGetItems")
+	defer cancel()
+	return t.DynamoDBAPI.TransactGetItemsWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "TransactWriteItems")
+	defer cancel()
+	return t.DynamoDBAPI.TransactWriteItemsWithContext(ctx, input, opts...)
+}
+
+func (t *timeoutAPI) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	ctx, cancel := t.withTimeout(ctx, "UpdateItem")
+	defer cancel()
+	return t.DynamoDBAPI.UpdateItemWithContext(ctx, input, opts...)
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// deadlineMock records the deadline of the context passed to each call
+type deadlineMock struct {
+	dynamodbiface.DynamoDBAPI
+	deadline time.Time
+	ok       bool
+}
+
+func (d *deadlineMock) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	d.deadline, d.ok = ctx.Deadline()
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (d *deadlineMock) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	d.deadline, d.ok = ctx.Deadline()
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestDDB_WithDefaultTimeout(t *testing.T) {
+	var (
+		mock  = &deadlineMock{}
+		db    = New(mock).WithDefaultTimeout("GetItem", time.Minute)
+		table = db.MustTable("example", Example{})
+	)
+
+	t.Run("no deadline", func(t *testing.T) {
+		started := time.Now()
+		if _, err := table.Get("abc").Exists(context.Background()); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !mock.ok {
+			t.Fatalf("got no deadline; want deadline")
+		}
+		if got := mock.deadline.Sub(started); got < time.Minute || got > 2*time.Minute {
+			t.Fatalf("got %v; want about 1m", got)
+		}
+	})
+
+	t.Run("caller deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		want, _ := ctx.Deadline()
+		if _, err := table.Get("abc").Exists(ctx); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if !mock.deadline.Equal(want) {
+			t.Fatalf("got %v; want %v", mock.deadline, want)
+		}
+	})
+
+	t.Run("other operation", func(t *testing.T) {
+		if err := table.Put(Example{ID: "abc"}).Run(); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if mock.ok {
+			t.Fatalf("got deadline; want none")
+		}
+	})
+
+	t.Run("unsupported operation", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("got nil; want panic")
+			}
+		}()
+		New(mock).WithDefaultTimeout("Nope", time.Second)
+	})
+}
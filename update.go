@@ -17,13 +17,38 @@ package ddb
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
+// SetAllOption customizes the behavior of Update.SetAll
+type SetAllOption interface {
+	ApplySetAll(o *setAllOptions)
+}
+
+type setAllOptions struct {
+	zeroValues  bool
+	removeIfNil bool
+}
+
+type setAllFunc func(o *setAllOptions)
+
+func (fn setAllFunc) ApplySetAll(o *setAllOptions) {
+	fn(o)
+}
+
+// WithZeroValues includes zero valued fields in SetAll
+func WithZeroValues() SetAllOption {
+	return setAllFunc(func(o *setAllOptions) {
+		o.zeroValues = true
+	})
+}
+
 // Update encapsulates the UpdateItem action
 type Update struct {
 	api                                 dynamodbiface.DynamoDBAPI
@@ -33,11 +58,20 @@ type Update struct {
 	consistentRead                      bool
 	request                             *ConsumedCapacity
 	table                               *ConsumedCapacity
+	capacity                            string
 	err                                 error
 	expr                                *expression
 	newValues                           interface{}
 	oldValues                           interface{}
 	returnValuesOnConditionCheckFailure string
+	modify                              []func(*dynamodb.UpdateItemInput)
+	noContext                           contextFactory
+	stats                               *Stats
+	clock                               Clock // clock times the request for Stats
+	conflicts                           *contention
+	conditions                          *conditions
+	guards                              []string // guards describes the bounds applied by Increment and Decrement
+	hooked                              bool     // hooked is true once BeforeUpdate has been applied
 }
 
 func (u *Update) returnValues() (string, error) {
@@ -71,11 +105,47 @@ func (u *Update) Condition(expr string, values ...interface{}) *Update {
 	return u
 }
 
+// ConditionNamed adds the condition defined on the table as name; see Table.DefineCondition
+func (u *Update) ConditionNamed(name string) *Update {
+	condition, err := u.conditions.lookup(name)
+	if err != nil {
+		u.err = err
+		return u
+	}
+	return u.Condition(condition.expr, condition.values...)
+}
+
+// AttributeExists adds a condition that the attribute, name, exists e.g. #Field
+func (u *Update) AttributeExists(name string) *Update {
+	expr, values := attributeFunc("attribute_exists", name)
+	return u.Condition(expr, values...)
+}
+
+// AttributeNotExists adds a condition that the attribute, name, does not exist e.g. #Field
+func (u *Update) AttributeNotExists(name string) *Update {
+	expr, values := attributeFunc("attribute_not_exists", name)
+	return u.Condition(expr, values...)
+}
+
 func (u *Update) ConsumedCapacity(capture *ConsumedCapacity) *Update {
 	u.request = capture
 	return u
 }
 
+// Stats captures the attempts, duration, and consumed capacity of the request to the
+// property provided
+func (u *Update) Stats(capture *Stats) *Update {
+	u.stats = capture
+	return u
+}
+
+// ReturnConsumedCapacity overrides the ReturnConsumedCapacity for this request; one of
+// dynamodb.ReturnConsumedCapacityNone, Total, or Indexes
+func (u *Update) ReturnConsumedCapacity(v string) *Update {
+	u.capacity = v
+	return u
+}
+
 // Delete deletes elements from a set
 func (u *Update) Delete(expr string, values ...interface{}) *Update {
 	if err := u.expr.Delete(expr, values...); err != nil {
@@ -85,8 +155,27 @@ func (u *Update) Delete(expr string, values ...interface{}) *Update {
 	return u
 }
 
+// tableSpec implements specSource
+func (u *Update) tableSpec() *tableSpec {
+	return u.spec
+}
+
+// tableCapacity implements capacitySink
+func (u *Update) tableCapacity() (string, *ConsumedCapacity) {
+	return u.spec.TableName, u.table
+}
+
+// tableContention implements contentionSink
+func (u *Update) tableContention() *contention {
+	return u.conflicts
+}
+
 // Tx returns *dynamodb.TransactWriteItem suitable for use in a transaction
 func (u *Update) Tx() (*dynamodb.TransactWriteItem, error) {
+	if err := beforeUpdate(u.noContext.background(), u); err != nil {
+		return nil, err
+	}
+
 	input, err := u.UpdateItemInput()
 	if err != nil {
 		return nil, err
@@ -147,26 +236,42 @@ func (u *Update) RunWithContext(ctx context.Context) error {
 	if u.err != nil {
 		return u.err
 	}
+	if err := beforeUpdate(ctx, u); err != nil {
+		return err
+	}
 
 	input, err := u.UpdateItemInput()
 	if err != nil {
 		return err
 	}
 
-	output, err := u.api.UpdateItemWithContext(ctx, input)
+	defer u.stats.since(u.clock, u.clock.Now())
+	u.stats.attempt(false)
+	output, err := u.api.UpdateItemWithContext(ctx, input, u.stats.options()...)
 	if err != nil {
+		u.conflicts.record(err)
+		err = wrapAWSError(err, "UpdateItem", u.spec, "", input.Key)
+		if len(u.guards) > 0 && isErrorClass(err, classConditionFailed) {
+			return wrapf(err, ErrConditionFailed, "update failed condition: %v", strings.Join(u.guards, ", "))
+		}
 		return err
 	}
 
 	if m := output.Attributes; m != nil {
 		if u.oldValues != nil {
-			if err := dynamodbattribute.UnmarshalMap(m, u.oldValues); err != nil {
+			if err := unmarshalMap(m, u.oldValues); err != nil {
 				return fmt.Errorf("update unable to unmarshal old values: %v", err)
 			}
+			if err := afterUpdate(ctx, u.oldValues); err != nil {
+				return err
+			}
 		} else if u.newValues != nil {
-			if err := dynamodbattribute.UnmarshalMap(m, u.newValues); err != nil {
+			if err := unmarshalMap(m, u.newValues); err != nil {
 				return fmt.Errorf("update unable to unmarshal new values: %v", err)
 			}
+			if err := afterUpdate(ctx, u.newValues); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -174,12 +279,13 @@ func (u *Update) RunWithContext(ctx context.Context) error {
 	if u.request != nil {
 		u.request.add(output.ConsumedCapacity)
 	}
+	u.stats.consumed(output.ConsumedCapacity)
 
 	return nil
 }
 
 func (u *Update) Run() error {
-	return u.RunWithContext(defaultContext)
+	return u.RunWithContext(u.noContext.context("Update.Run"))
 }
 
 func (u *Update) Set(expr string, values ...interface{}) *Update {
@@ -190,6 +296,166 @@ func (u *Update) Set(expr string, values ...interface{}) *Update {
 	return u
 }
 
+// MergeMap sets the keys of m within the map attribute identified by path e.g.
+//
+//	MergeMap("#Attrs", map[string]interface{}{"color": "red"})
+//
+// generates SET #Attrs.#k = :v for each key, leaving other keys in the map
+// untouched.  The map attribute must already exist.
+func (u *Update) MergeMap(path string, m map[string]interface{}) *Update {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		u.Set(path+".#? = ?", k, m[k])
+	}
+
+	return u
+}
+
+// WithRemoveIfNil generates a REMOVE clause for each nil pointer field in SetAll, allowing
+// a PATCH to clear fields as well as set them
+func WithRemoveIfNil() SetAllOption {
+	return setAllFunc(func(o *setAllOptions) {
+		o.removeIfNil = true
+	})
+}
+
+// SetAll generates a SET clause for each non-key field of the struct, v.  By default,
+// zero valued fields are skipped; use WithZeroValues to include them or WithRemoveIfNil
+// to REMOVE nil pointer fields.  Fields tagged as flags, e.g. `ddb:"flag"`, are SET when
+// true and always REMOVEd when false.
+func (u *Update) SetAll(v interface{}, opts ...SetAllOption) *Update {
+	var options setAllOptions
+	for _, opt := range opts {
+		opt.ApplySetAll(&options)
+	}
+
+	if u.expr.validate != nil {
+		if err := u.expr.validate(v); err != nil {
+			u.err = err
+			return u
+		}
+	}
+
+	value, v, ok := addressableStruct(v)
+	if !ok {
+		u.err = fmt.Errorf("SetAll requires a struct or pointer to struct: got %T", v)
+		return u
+	}
+
+	// composite keys are only written when all of their components are present; with
+	// zero values, incomplete composite keys are removed to keep sparse indexes sparse
+	var incomplete []string
+	for _, c := range u.spec.Composites {
+		key, ok := c.composeFrom(value)
+		if !ok {
+			incomplete = append(incomplete, c.AttributeName)
+			continue
+		}
+		value.FieldByName(c.FieldName).SetString(key)
+	}
+
+	item, err := u.expr.encoder.marshalMap(v)
+	if err != nil {
+		u.err = wrapf(err, ErrUnableToMarshalItem, "unable to encode %T", v)
+		return u
+	}
+
+	skip := func(name string) bool {
+		for _, key := range []*keySpec{u.spec.HashKey, u.spec.RangeKey} {
+			if key != nil && key.AttributeName == name {
+				return true
+			}
+		}
+		return containsString(incomplete, name)
+	}
+
+	u.setFields(value, item, options, skip)
+	if options.zeroValues {
+		for _, name := range incomplete {
+			u.Remove("#?", name)
+		}
+	}
+
+	return u
+}
+
+// setFields adds a SET clause for each encoded field of value, descending into
+// embedded structs which dynamodbattribute flattens
+func (u *Update) setFields(value reflect.Value, item map[string]*dynamodb.AttributeValue, options setAllOptions, skip func(string) bool) *Update {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, ok := getAttrName(field)
+		if !ok {
+			continue
+		}
+
+		fv := value.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == field.Name {
+			u.setFields(fv, item, options, skip)
+			continue
+		}
+
+		if skip(name) {
+			continue
+		}
+
+		if u.spec.isFlag(name) {
+			set, ok, err := flagValue(name, fv)
+			if err != nil {
+				u.err = err
+				return u
+			}
+			if ok && !set {
+				u.Remove("#?", name) // flags are only stored when true
+				continue
+			}
+		}
+
+		if options.removeIfNil && fv.Kind() == reflect.Ptr && fv.IsNil() {
+			u.Remove("#?", name)
+			continue
+		}
+
+		av, ok := item[name]
+		if !ok {
+			continue
+		}
+		if !options.zeroValues && fv.IsZero() {
+			continue
+		}
+
+		u.Set("#? = ?", name, av)
+	}
+
+	return u
+}
+
+// flagValue returns the value of fv, the bool or *bool field of the flag attribute,
+// name.  ok is false when fv is a nil *bool.
+func flagValue(name string, fv reflect.Value) (value, ok bool, err error) {
+	switch {
+	case fv.Kind() == reflect.Bool:
+		return fv.Bool(), true, nil
+	case fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Bool:
+		if fv.IsNil() {
+			return false, false, nil
+		}
+		return fv.Elem().Bool(), true, nil
+	default:
+		return false, false, errorf(ErrValidation, "flag attribute, %v, requires a bool or *bool: got %v", name, fv.Type())
+	}
+}
+
 func (u *Update) UpdateItemInput() (*dynamodb.UpdateItemInput, error) {
 	if u.err != nil {
 		return nil, u.err
@@ -205,29 +471,60 @@ func (u *Update) UpdateItemInput() (*dynamodb.UpdateItemInput, error) {
 		return nil, err
 	}
 
+	if err := u.expr.checkOverlappingPaths(); err != nil {
+		return nil, err
+	}
+
 	var (
 		conditionExpression = u.expr.ConditionExpression()
 		updateExpression    = u.expr.UpdateExpression()
 	)
 
-	return &dynamodb.UpdateItemInput{
+	input := dynamodb.UpdateItemInput{
 		ConditionExpression:       conditionExpression,
 		ExpressionAttributeNames:  u.expr.Names,
 		ExpressionAttributeValues: u.expr.Values,
 		Key:                       key,
-		ReturnConsumedCapacity:    aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		ReturnConsumedCapacity:    returnConsumedCapacity(u.capacity),
 		ReturnValues:              aws.String(returnValues),
 		TableName:                 aws.String(u.spec.TableName),
 		UpdateExpression:          updateExpression,
-	}, nil
+	}
+	for _, fn := range u.modify {
+		fn(&input)
+	}
+
+	return &input, nil
 }
 
+// Modify registers a function to alter the UpdateItemInput immediately before it is submitted,
+// allowing parameters not yet supported by ddb to be used.  When called multiple times, the
+// functions are applied in order.
+func (u *Update) Modify(fn func(input *dynamodb.UpdateItemInput)) *Update {
+	u.modify = append(u.modify, fn)
+	return u
+}
+
+// Update updates the item with the given hash key.  hashKey may also be a key struct
+// or key map; see Table.Get.
 func (t *Table) Update(hashKey interface{}) *Update {
+	expr := t.newExpression()
+	if validator := t.ddb.validator; validator != nil {
+		expr.validate = func(v interface{}) error {
+			return validate(validator, t.spec.TableName, v)
+		}
+	}
+
 	return &Update{
-		api:     t.ddb.api,
-		spec:    t.spec,
-		hashKey: hashKey,
-		table:   t.consumed,
-		expr:    newExpression(t.spec.Attributes...),
+		api:        t.ddb.api,
+		spec:       t.spec,
+		hashKey:    hashKey,
+		table:      t.consumed,
+		conflicts:  t.conflicts,
+		conditions: t.conditions,
+		capacity:   t.ddb.capacity,
+		noContext:  t.ddb.noContext,
+		clock:      t.ddb.clock,
+		expr:       expr,
 	}
 }
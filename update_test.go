@@ -15,8 +15,10 @@
 package ddb
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
@@ -101,7 +103,7 @@ func TestUpdate_NewValues(t *testing.T) {
 			oldValues UpdateTable
 		)
 
-		err := table.Update("key").
+		err := table.Update("key").Range("range").
 			OldValues(&oldValues).
 			Run()
 		if err != nil {
@@ -122,7 +124,7 @@ func TestUpdate_NewValues(t *testing.T) {
 			newValues UpdateTable
 		)
 
-		err := table.Update("key").
+		err := table.Update("key").Range("range").
 			NewValues(&newValues).
 			Run()
 		if err != nil {
@@ -253,6 +255,189 @@ func TestUpdate_Set(t *testing.T) {
 	})
 }
 
+func TestUpdate_MergeMap(t *testing.T) {
+	const tableName = "example"
+
+	t.Run("ok", func(t *testing.T) {
+		table := New(nil).MustTable(tableName, UpdateTable{})
+		update := table.Update("hello").Range("world")
+
+		// When
+		update.MergeMap("#Attrs", map[string]interface{}{
+			"color": "red",
+			"size":  3,
+		})
+		if update.err != nil {
+			t.Fatalf("got %v; want nil", update.err)
+		}
+
+		input, err := update.UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/update_merge_map_ok.json")
+	})
+}
+
+func TestUpdate_SetAll(t *testing.T) {
+	const tableName = "example"
+
+	t.Run("ok", func(t *testing.T) {
+		table := New(nil).MustTable(tableName, UpdateTable{})
+		update := table.Update("hello").Range("world")
+
+		// When
+		update.SetAll(UpdateTable{ID: "ignored", A: "abc", Count: 0})
+		if update.err != nil {
+			t.Fatalf("got %v; want nil", update.err)
+		}
+
+		input, err := update.UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/update_set_all_ok.json")
+	})
+
+	t.Run("zero values", func(t *testing.T) {
+		table := New(nil).MustTable(tableName, UpdateTable{})
+		update := table.Update("hello").Range("world")
+
+		// When
+		update.SetAll(&UpdateTable{A: "abc"}, WithZeroValues())
+		if update.err != nil {
+			t.Fatalf("got %v; want nil", update.err)
+		}
+
+		input, err := update.UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+
+		assertEqual(t, input, "testdata/update_set_all_zero.json")
+	})
+
+	t.Run("remove if nil", func(t *testing.T) {
+		type Patch struct {
+			ID    string  `ddb:"hash"`
+			Name  *string `dynamodbav:"name"`
+			Email *string `dynamodbav:"email"`
+			Count int
+		}
+
+		var (
+			table = New(nil).MustTable(tableName, Patch{})
+			name  = "abc"
+			patch = Patch{Name: &name}
+		)
+
+		input, err := table.Update("hello").SetAll(patch, WithRemoveIfNil()).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.UpdateExpression), "Set #n1 = :v1 Remove #n2"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := aws.StringValue(input.ExpressionAttributeNames["#n2"]), "email"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		input, err = table.Update("hello").SetAll(patch).UpdateItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.UpdateExpression), "Set #n1 = :v1"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		table := New(nil).MustTable(tableName, UpdateTable{})
+		update := table.Update("hello").Range("world")
+
+		update.SetAll("abc")
+		if update.err == nil {
+			t.Fatalf("got nil; want not nil")
+		}
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		type Flagged struct {
+			ID       string `ddb:"hash"`
+			Pending  bool   `ddb:"flag"`
+			Archived bool   `ddb:"flag" dynamodbav:"archived,omitempty"`
+		}
+
+		table := New(nil).MustTable(tableName, Flagged{})
+		testCases := map[string]struct {
+			Item Flagged
+			Want string
+		}{
+			"set": {
+				Item: Flagged{Pending: true},
+				Want: "Set #n1 = :v1 Remove #n2",
+			},
+			"remove": {
+				Item: Flagged{Archived: true},
+				Want: "Set #n2 = :v1 Remove #n1",
+			},
+		}
+
+		for label, tc := range testCases {
+			t.Run(label, func(t *testing.T) {
+				input, err := table.Update("hello").SetAll(tc.Item).UpdateItemInput()
+				if err != nil {
+					t.Fatalf("got %v; want nil", err)
+				}
+				if got, want := aws.StringValue(input.UpdateExpression), tc.Want; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			})
+		}
+
+		t.Run("pointers", func(t *testing.T) {
+			type Patch struct {
+				Pending  *bool
+				Archived *bool `dynamodbav:"archived"`
+			}
+
+			yes, no := true, false
+			testCases := map[string]struct {
+				Item Patch
+				Want string
+			}{
+				"set":    {Item: Patch{Pending: &yes}, Want: "Set #n1 = :v1 Remove #n2"},
+				"remove": {Item: Patch{Pending: &no, Archived: &yes}, Want: "Set #n2 = :v1 Remove #n1"},
+			}
+
+			for label, tc := range testCases {
+				t.Run(label, func(t *testing.T) {
+					input, err := table.Update("hello").SetAll(tc.Item, WithRemoveIfNil()).UpdateItemInput()
+					if err != nil {
+						t.Fatalf("got %v; want nil", err)
+					}
+					if got, want := aws.StringValue(input.UpdateExpression), tc.Want; got != want {
+						t.Fatalf("got %v; want %v", got, want)
+					}
+				})
+			}
+		})
+
+		t.Run("type mismatch", func(t *testing.T) {
+			type Patch struct {
+				Pending string
+			}
+
+			_, err := table.Update("hello").SetAll(Patch{Pending: "yes"}).UpdateItemInput()
+			if !IsValidationError(err) {
+				t.Fatalf("got %v; want ErrValidation", err)
+			}
+		})
+	})
+}
+
 func TestUpdate_Run(t *testing.T) {
 	const tableName = "example"
 
@@ -280,8 +465,68 @@ func TestUpdate_Run(t *testing.T) {
 		update := table.Update("hello").Range("world")
 		update.Set("#a = ?", 123)
 		err := update.Run()
-		if err != original {
+		if !errors.Is(err, original) {
 			t.Fatalf("got %v; want %v", err, original)
 		}
 	})
 }
+
+func TestUpdate_Modify(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+	input, err := table.Update("abc").
+		Range("def").
+		Set("#a = ?", "a").
+		Modify(func(input *dynamodb.UpdateItemInput) {
+			input.ReturnItemCollectionMetrics = aws.String(dynamodb.ReturnItemCollectionMetricsSize)
+		}).
+		UpdateItemInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := *input.ReturnItemCollectionMetrics, dynamodb.ReturnItemCollectionMetricsSize; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func TestUpdate_OverlappingPaths(t *testing.T) {
+	table := New(nil).MustTable("example", UpdateTable{})
+
+	testCases := map[string]struct {
+		Update *Update
+		Want   string
+	}{
+		"set and remove": {
+			Update: table.Update("hello").Range("world").Set("#a = ?", "abc").Remove("#a"),
+			Want:   "OverlappingPaths: attribute, a, appears in both Set and Remove",
+		},
+		"add and delete": {
+			Update: table.Update("hello").Range("world").Add("#Count ?", 1).Delete("#Count ?", 1),
+			Want:   "OverlappingPaths: attribute, Count, appears in both Add and Delete",
+		},
+		"nested": {
+			Update: table.Update("hello").Range("world").Set("#a.#b = ?", "abc").Remove("#a"),
+			Want:   "OverlappingPaths: attribute, a.b, appears in both Set and Remove",
+		},
+		"distinct": {
+			Update: table.Update("hello").Range("world").Set("#a = list_append(#a, ?), #Count = ?", []string{"x"}, 1).Remove("#ab, #b"),
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			_, err := tc.Update.UpdateItemInput()
+			if tc.Want == "" {
+				if err != nil {
+					t.Fatalf("got %v; want nil", err)
+				}
+				return
+			}
+			if !IsOverlappingPathsError(err) {
+				t.Fatalf("got %v; want OverlappingPaths", err)
+			}
+			if got := err.Error(); got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// maxUserFilterClauses bounds the number of clauses accepted by UserFilter
+const maxUserFilterClauses = 16
+
+type filterTokenKind int
+
+const (
+	filterIdent filterTokenKind = iota
+	filterOperator
+	filterString
+	filterNumber
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+// userFilterOperators maps the operators accepted from end users to their expression
+// template where #? and ? are bound to the field and value respectively
+var userFilterOperators = map[string]string{
+	"=":           "#? = ?",
+	"!=":          "#? <> ?",
+	"<>":          "#? <> ?",
+	"<":           "#? < ?",
+	"<=":          "#? <= ?",
+	">":           "#? > ?",
+	">=":          "#? >= ?",
+	"begins_with": "begins_with(#?, ?)",
+	"contains":    "contains(#?, ?)",
+}
+
+func tokenizeUserFilter(input string) ([]filterToken, error) {
+	var (
+		tokens []filterToken
+		runes  = []rune(input)
+	)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			start := i
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, errorf(ErrInvalidFilter, "unterminated string at position %v", start)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, filterToken{kind: filterString, text: sb.String(), pos: start})
+
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			text := string(runes[start:i])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, errorf(ErrInvalidFilter, "invalid number, %v, at position %v", text, start)
+			}
+			tokens = append(tokens, filterToken{kind: filterNumber, text: text, pos: start})
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i++; i < len(runes) && isNameRune(runes[i]); i++ {
+			}
+			tokens = append(tokens, filterToken{kind: filterIdent, text: string(runes[start:i]), pos: start})
+
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			for i++; i < len(runes) && strings.ContainsRune("=<>", runes[i]); i++ {
+			}
+			text := string(runes[start:i])
+			if _, ok := userFilterOperators[text]; !ok {
+				return nil, errorf(ErrInvalidFilter, "invalid operator, %v, at position %v", text, start)
+			}
+			tokens = append(tokens, filterToken{kind: filterOperator, text: text, pos: start})
+
+		default:
+			return nil, errorf(ErrInvalidFilter, "unexpected character, %q, at position %v", r, i)
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseUserFilter translates a filter of the form, field op value [and|or field op value
+// ...], into an expression with bound names and values.  Fields must be attributes of
+// the table and, if allowed is not empty, one of allowed.
+func parseUserFilter(spec *tableSpec, input string, allowed []string) (string, []interface{}, error) {
+	tokens, err := tokenizeUserFilter(input)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) == 0 {
+		return "", nil, errorf(ErrInvalidFilter, "filter is empty")
+	}
+
+	var (
+		sb      strings.Builder
+		values  []interface{}
+		clauses int
+	)
+	for i := 0; i < len(tokens); {
+		if clauses++; clauses > maxUserFilterClauses {
+			return "", nil, errorf(ErrInvalidFilter, "filter may contain at most %v clauses", maxUserFilterClauses)
+		}
+		if i+3 > len(tokens) {
+			return "", nil, errorf(ErrInvalidFilter, "incomplete clause at position %v", tokens[i].pos)
+		}
+
+		field, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		if field.kind != filterIdent {
+			return "", nil, errorf(ErrInvalidFilter, "expected field at position %v", field.pos)
+		}
+		attr, err := filterableAttribute(spec, field.text, allowed)
+		if err != nil {
+			return "", nil, err
+		}
+
+		template, ok := userFilterOperators[strings.ToLower(op.text)]
+		if !ok || (op.kind != filterOperator && op.kind != filterIdent) {
+			return "", nil, errorf(ErrInvalidFilter, "invalid operator, %v, at position %v", op.text, op.pos)
+		}
+
+		v, err := userFilterValue(attr, value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sb.WriteString(template)
+		values = append(values, attr.AttributeName, v)
+		i += 3
+
+		if i < len(tokens) {
+			switch conj := tokens[i]; strings.ToLower(conj.text) {
+			case "and", "or":
+				if conj.kind != filterIdent || i+1 == len(tokens) {
+					return "", nil, errorf(ErrInvalidFilter, "incomplete clause at position %v", conj.pos)
+				}
+				sb.WriteString(" " + strings.ToLower(conj.text) + " ")
+				i++
+			default:
+				return "", nil, errorf(ErrInvalidFilter, "expected and or or at position %v", conj.pos)
+			}
+		}
+	}
+
+	return sb.String(), values, nil
+}
+
+func filterableAttribute(spec *tableSpec, name string, allowed []string) (*attributeSpec, error) {
+	if len(allowed) > 0 {
+		var ok bool
+		for _, v := range allowed {
+			if v == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, errorf(ErrInvalidFilter, "field, %v, may not be filtered", name)
+		}
+	}
+
+	for _, attr := range spec.Attributes {
+		if attr.AttributeName == name {
+			return attr, nil
+		}
+	}
+
+	return nil, errorf(ErrInvalidFilter, "field, %v, may not be filtered", name)
+}
+
+// userFilterValue converts the token to a value appropriate for the attribute type
+func userFilterValue(attr *attributeSpec, token filterToken) (interface{}, error) {
+	invalid := func() (interface{}, error) {
+		return nil, errorf(ErrInvalidFilter, "invalid value, %v, for field, %v, at position %v", token.text, attr.AttributeName, token.pos)
+	}
+
+	switch attr.AttributeType {
+	case dynamodb.ScalarAttributeTypeS:
+		if token.kind != filterString && token.kind != filterNumber {
+			return invalid()
+		}
+		return token.text, nil
+
+	case dynamodb.ScalarAttributeTypeN:
+		if token.kind != filterNumber {
+			return invalid()
+		}
+		return dynamodbattribute.Number(token.text), nil
+
+	case dynamodb.ScalarAttributeTypeB: // bool fields are reported as B by inspect
+		if v, err := strconv.ParseBool(token.text); err == nil && token.kind == filterIdent {
+			return v, nil
+		}
+		return invalid()
+
+	default:
+		switch token.kind {
+		case filterString:
+			return token.text, nil
+		case filterNumber:
+			return dynamodbattribute.Number(token.text), nil
+		default:
+			if v, err := strconv.ParseBool(token.text); err == nil {
+				return v, nil
+			}
+			return invalid()
+		}
+	}
+}
+
+// UserFilter adds a filter supplied by an end user e.g. from a query string, of the form
+//
+//	Status = "open" and Amount >= 10
+//
+// Supported operators are =, != (or <>), <, <=, >, >=, begins_with, and contains; clauses
+// may be combined with and / or with and binding tighter.  Names and values are always
+// bound so the input cannot alter the structure of the expression.  Fields must be
+// attributes of the model and, if allowed is not empty, one of allowed.  Invalid filters
+// return an error with the code, ErrInvalidFilter.
+func (q *Query) UserFilter(input string, allowed ...string) *Query {
+	expr, values, err := parseUserFilter(q.spec, input, allowed)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	return q.Filter(expr, values...)
+}
+
+// UserFilter adds a filter supplied by an end user; see Query.UserFilter
+func (s *Scan) UserFilter(input string, allowed ...string) *Scan {
+	expr, values, err := parseUserFilter(s.spec, input, allowed)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	return s.Filter(expr, values...)
+}
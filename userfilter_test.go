@@ -0,0 +1,142 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type FilterExample struct {
+	ID     string `ddb:"hash"`
+	Status string
+	Amount int
+	Active bool
+	Secret string
+}
+
+func Test_parseUserFilter(t *testing.T) {
+	spec, err := inspect("example", FilterExample{})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	testCases := map[string]struct {
+		Input   string
+		Allowed []string
+		Expr    string
+		Values  []interface{}
+	}{
+		"equal": {
+			Input:  `Status = "open"`,
+			Expr:   "#? = ?",
+			Values: []interface{}{"Status", "open"},
+		},
+		"and or": {
+			Input: `Status != 'closed' AND Amount >= 10 or Active = true`,
+			Expr:  "#? <> ? and #? >= ? or #? = ?",
+			Values: []interface{}{
+				"Status", "closed",
+				"Amount", dynamodbattribute.Number("10"),
+				"Active", true,
+			},
+		},
+		"begins_with": {
+			Input:  `Status begins_with "op\"en"`,
+			Expr:   "begins_with(#?, ?)",
+			Values: []interface{}{"Status", `op"en`},
+		},
+		"negative number": {
+			Input:  `Amount < -1.5`,
+			Expr:   "#? < ?",
+			Values: []interface{}{"Amount", dynamodbattribute.Number("-1.5")},
+		},
+		"allowed": {
+			Input:   `Amount > 1`,
+			Allowed: []string{"Status", "Amount"},
+			Expr:    "#? > ?",
+			Values:  []interface{}{"Amount", dynamodbattribute.Number("1")},
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			expr, values, err := parseUserFilter(spec, tc.Input, tc.Allowed)
+			if err != nil {
+				t.Fatalf("got %v; want nil", err)
+			}
+			if got, want := expr, tc.Expr; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := values, tc.Values; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	invalid := map[string]struct {
+		Input   string
+		Allowed []string
+	}{
+		"empty":            {Input: ` `},
+		"unknown field":    {Input: `Missing = "a"`},
+		"not allowed":      {Input: `Secret = "a"`, Allowed: []string{"Status"}},
+		"number as string": {Input: `Amount = "a"`},
+		"bare word":        {Input: `Status = open`},
+		"bad bool":         {Input: `Active = 1`},
+		"bad operator":     {Input: `Status =! "a"`},
+		"unknown operator": {Input: `Status like "a"`},
+		"injection":        {Input: `Status = "a") or (ID = "b"`},
+		"dangling and":     {Input: `Status = "a" and`},
+		"missing and":      {Input: `Status = "a" Amount = 1`},
+		"incomplete":       {Input: `Status =`},
+		"unterminated":     {Input: `Status = "a`},
+	}
+
+	for label, tc := range invalid {
+		t.Run(label, func(t *testing.T) {
+			_, _, err := parseUserFilter(spec, tc.Input, tc.Allowed)
+			if !IsInvalidFilterError(err) {
+				t.Fatalf("got %v; want ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestQuery_UserFilter(t *testing.T) {
+	table := New(nil).MustTable("example", FilterExample{})
+
+	input, err := table.Query("#ID = ?", "abc").
+		UserFilter(`Status begins_with "op" and Amount > 3`).
+		QueryInput()
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(input.FilterExpression), "begins_with(#n2, :v2) and #n3 > :v3"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := input.ExpressionAttributeValues[":v3"], (&dynamodb.AttributeValue{N: aws.String("3")}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	_, err = table.Scan().UserFilter(`Nope = 1`).ScanInput(0, 1)
+	if !IsInvalidFilterError(err) {
+		t.Fatalf("got %v; want ErrInvalidFilter", err)
+	}
+}
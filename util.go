@@ -15,8 +15,13 @@
 package ddb
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 // getMetadata accepts the key and spec for a given table and returns the corresponding hashKey, rangeKey, and tableName
@@ -28,13 +33,150 @@ func getMetadata(key map[string]*dynamodb.AttributeValue, spec *tableSpec) (hash
 	return hashKey, rangeKey, spec.TableName
 }
 
+// itemKey returns the attributes of item named by names, or nil if names is empty or
+// any of the attributes are missing
+func itemKey(names []string, item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if len(names) == 0 {
+		return nil
+	}
+
+	key := make(map[string]*dynamodb.AttributeValue, len(names))
+	for _, name := range names {
+		av, ok := item[name]
+		if !ok {
+			return nil
+		}
+		key[name] = av
+	}
+	return key
+}
+
+// resumePoint tracks the key from which an iteration may be resumed: the key of the
+// last item passed to the callback or, before any item, the start key of the page.
+// The key of an item is only built when requested to avoid an allocation per item.
+type resumePoint struct {
+	names []string
+	start map[string]*dynamodb.AttributeValue
+	item  map[string]*dynamodb.AttributeValue
+}
+
+// reset resumes from the start key of a page
+func (r *resumePoint) reset(start map[string]*dynamodb.AttributeValue) {
+	r.start, r.item = start, nil
+}
+
+// advance resumes after item provided item holds each of the key attributes
+func (r *resumePoint) advance(item map[string]*dynamodb.AttributeValue) {
+	if len(r.names) == 0 {
+		return
+	}
+	for _, name := range r.names {
+		if _, ok := item[name]; !ok {
+			return
+		}
+	}
+	r.item = item
+}
+
+// key returns the key to resume from
+func (r *resumePoint) key() map[string]*dynamodb.AttributeValue {
+	if r.item != nil {
+		return itemKey(r.names, r.item)
+	}
+	return r.start
+}
+
+// formatKeyTime encodes tm using the time format of the key.  Without a time format,
+// numeric keys are encoded as epoch seconds and all others as RFC3339
+func formatKeyTime(key *keySpec, tm time.Time) interface{} {
+	switch {
+	case key.TimeFormat != "":
+		return tm.UTC().Format(key.TimeFormat)
+	case key.AttributeType == dynamodb.ScalarAttributeTypeN:
+		return tm.Unix()
+	default:
+		return tm.UTC().Format(time.RFC3339)
+	}
+}
+
+// addressableStruct returns an addressable struct value for v.  When v is a struct
+// rather than a pointer to a struct, a pointer to a copy of v is returned.
+func addressableStruct(v interface{}) (reflect.Value, interface{}, bool) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, v, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, v, false
+	}
+	if !value.CanAddr() {
+		dup := reflect.New(value.Type())
+		dup.Elem().Set(value)
+		return dup.Elem(), dup.Interface(), true
+	}
+	return value, v, true
+}
+
+// isMissingKey returns true if v holds no key value; either nil or a blank string, which
+// DynamoDB never accepts as a key.  Other zero values, such as 0, are valid keys when
+// supplied explicitly.
+func isMissingKey(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return true
+	}
+	return value.Kind() == reflect.String && value.Len() == 0
+}
+
+// makeKey returns the primary key of the table.  Each key defined by the table is
+// required; a missing key fails with ErrMissingKey naming the attribute.
 func makeKey(spec *tableSpec, hashKey, rangeKey interface{}) (map[string]*dynamodb.AttributeValue, error) {
-	hk, err := marshal(hashKey)
+	if isKeyStruct(hashKey) {
+		if rangeKey != nil {
+			return nil, fmt.Errorf("range key may not be combined with key struct, %T", hashKey)
+		}
+		hk, rk, err := splitKeyStruct(spec, hashKey)
+		if err != nil {
+			return nil, err
+		}
+		hashKey, rangeKey = hk, rk
+	}
+	if item, ok := hashKey.(map[string]*dynamodb.AttributeValue); ok {
+		if rangeKey != nil {
+			return nil, fmt.Errorf("range key may not be combined with key map")
+		}
+		hashKey, rangeKey = splitKeyMap(spec, item)
+	}
+
+	if key := spec.HashKey; key != nil && isMissingKey(hashKey) {
+		return nil, errorf(ErrMissingKey, "missing hash key, %v, for table, %v", key.AttributeName, spec.TableName)
+	}
+	if key := spec.RangeKey; key != nil && isMissingKey(rangeKey) {
+		return nil, errorf(ErrMissingKey, "missing range key, %v, for table, %v", key.AttributeName, spec.TableName)
+	}
+
+	if tm, ok := hashKey.(time.Time); ok && spec.HashKey != nil {
+		hashKey = formatKeyTime(spec.HashKey, tm)
+	}
+	if tm, ok := rangeKey.(time.Time); ok && spec.RangeKey != nil {
+		rangeKey = formatKeyTime(spec.RangeKey, tm)
+	}
+
+	hk, err := marshalKey(hashKey)
 	if err != nil {
 		return nil, wrapf(err, ErrUnableToMarshalItem, "unable to encode hash key, %v", hashKey)
 	}
 
-	rk, err := marshal(rangeKey)
+	rk, err := marshalKey(rangeKey)
 	if err != nil {
 		return nil, wrapf(err, ErrUnableToMarshalItem, "unable to encode range key, %v", rangeKey)
 	}
@@ -50,24 +192,46 @@ func makeKey(spec *tableSpec, hashKey, rangeKey interface{}) (map[string]*dynamo
 	return keys, nil
 }
 
-func marshal(item interface{}) (*dynamodb.AttributeValue, error) {
-	switch v := item.(type) {
-	case *dynamodb.AttributeValue:
-		return v, nil
-	case map[string]*dynamodb.AttributeValue:
-		return &dynamodb.AttributeValue{M: v}, nil
-	case []*dynamodb.AttributeValue:
-		return &dynamodb.AttributeValue{L: v}, nil
-	default:
-		return dynamodbattribute.Marshal(item)
+// makeKeyProjection returns a projection expression and the corresponding attribute
+// names that limit the response to the key attributes of the table
+func makeKeyProjection(spec *tableSpec) (*string, map[string]*string) {
+	var (
+		expr  = newExpression(spec.Attributes...)
+		names []string
+	)
+	if key := spec.HashKey; key != nil {
+		names = append(names, expr.addExpressionAttributeName(key.AttributeName))
 	}
+	if key := spec.RangeKey; key != nil {
+		names = append(names, expr.addExpressionAttributeName(key.AttributeName))
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	return aws.String(strings.Join(names, ", ")), expr.Names
+}
+
+func marshal(item interface{}) (*dynamodb.AttributeValue, error) {
+	return encoder{}.marshal(item)
 }
 
 func marshalMap(item interface{}) (map[string]*dynamodb.AttributeValue, error) {
-	switch v := item.(type) {
-	case map[string]*dynamodb.AttributeValue:
-		return v, nil
-	default:
-		return dynamodbattribute.MarshalMap(item)
+	return encoder{}.marshalMap(item)
+}
+
+// projectNames returns a projection expression, #?, #?, ..., binding the attribute names
+func projectNames(names []string) (string, []interface{}) {
+	var (
+		expr   strings.Builder
+		values = make([]interface{}, 0, len(names))
+	)
+	for i, name := range names {
+		if i > 0 {
+			expr.WriteString(comma)
+		}
+		expr.WriteString("#?")
+		values = append(values, name)
 	}
+	return expr.String(), values
 }
@@ -18,6 +18,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -42,6 +43,121 @@ func Test_makeKey(t *testing.T) {
 	assertEqual(t, item, "testdata/keys.json")
 }
 
+func Test_makeKeyMissing(t *testing.T) {
+	type Numeric struct {
+		Hash  string `ddb:"hash"`
+		Range int64  `ddb:"range"`
+	}
+
+	spec, err := inspect("numeric", Numeric{})
+	if err != nil {
+		t.Fatalf("got %#v; want nil", err)
+	}
+
+	var nilString *string
+	testCases := map[string]struct {
+		HashKey  interface{}
+		RangeKey interface{}
+		Missing  string // Missing, if set, is the message of the expected ErrMissingKey
+	}{
+		"ok": {
+			HashKey:  "abc",
+			RangeKey: int64(1),
+		},
+		"zero range": {
+			HashKey:  "abc",
+			RangeKey: int64(0),
+		},
+		"nil hash": {
+			RangeKey: int64(1),
+			Missing:  "missing hash key, Hash, for table, numeric",
+		},
+		"blank hash": {
+			HashKey:  "",
+			RangeKey: int64(1),
+			Missing:  "missing hash key, Hash, for table, numeric",
+		},
+		"nil pointer hash": {
+			HashKey:  nilString,
+			RangeKey: int64(1),
+			Missing:  "missing hash key, Hash, for table, numeric",
+		},
+		"omitted range": {
+			HashKey: "abc",
+			Missing: "missing range key, Range, for table, numeric",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			_, err := makeKey(spec, tc.HashKey, tc.RangeKey)
+			if tc.Missing == "" {
+				if err != nil {
+					t.Fatalf("got %v; want nil", err)
+				}
+				return
+			}
+
+			if !IsMissingKeyError(err) {
+				t.Fatalf("got %v; want ErrMissingKey", err)
+			}
+			if got, want := err.(Error).Message(), tc.Missing; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_makeKeyTime(t *testing.T) {
+	type Sample struct {
+		Hash  string `ddb:"hash"`
+		Range string `ddb:"range,timefmt=2006-01-02"`
+	}
+
+	spec, err := inspect("sample", Sample{})
+	if err != nil {
+		t.Fatalf("got %#v; want nil", err)
+	}
+
+	tm := time.Date(2020, time.May, 23, 18, 45, 9, 0, time.UTC)
+	item, err := makeKey(spec, "abc", tm)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got, want := aws.StringValue(item["Range"].S), "2020-05-23"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+}
+
+func Test_formatKeyTime(t *testing.T) {
+	tm := time.Date(2020, time.May, 23, 18, 45, 9, 0, time.UTC)
+	testCases := map[string]struct {
+		Key  keySpec
+		Want interface{}
+	}{
+		"layout": {
+			Key:  keySpec{AttributeType: "S", TimeFormat: "2006-01"},
+			Want: "2020-05",
+		},
+		"number": {
+			Key:  keySpec{AttributeType: "N"},
+			Want: int64(1590259509),
+		},
+		"string": {
+			Key:  keySpec{AttributeType: "S"},
+			Want: "2020-05-23T18:45:09Z",
+		},
+	}
+
+	for label, tc := range testCases {
+		t.Run(label, func(t *testing.T) {
+			if got := formatKeyTime(&tc.Key, tm); got != tc.Want {
+				t.Fatalf("got %v; want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
 func Test_marshal(t *testing.T) {
 	t.Run("map", func(t *testing.T) {
 		want := map[string]*dynamodb.AttributeValue{
@@ -174,3 +290,17 @@ func TestDynamodbMarshal(t *testing.T) {
 		}
 	})
 }
+
+func Benchmark_makeKey(b *testing.B) {
+	spec, err := inspect("example", UpdateTable{})
+	if err != nil {
+		b.Fatalf("got %v; want nil", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := makeKey(spec, "abc", "2020-01-01"); err != nil {
+			b.Fatalf("got %v; want nil", err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"reflect"
+)
+
+// Validator validates values before they are marshalled by Put and Update
+type Validator interface {
+	Validate(v interface{}) error
+}
+
+// ValidatorFunc adapts a func to the Validator interface.  The Struct method
+// of github.com/go-playground/validator can be used as is:
+//
+//	validate := validator.New()
+//	db := ddb.New(api).WithValidator(ddb.ValidatorFunc(validate.Struct))
+type ValidatorFunc func(v interface{}) error
+
+// Validate implements Validator
+func (fn ValidatorFunc) Validate(v interface{}) error {
+	return fn(v)
+}
+
+type fielder interface {
+	Field() string
+}
+
+type tagger interface {
+	Tag() string
+}
+
+// validate invokes the validator, if any, and converts failures into a *ValidationError
+func validate(validator Validator, tableName string, v interface{}) error {
+	if validator == nil {
+		return nil
+	}
+
+	err := validator.Validate(v)
+	if err == nil {
+		return nil
+	}
+	if IsValidationError(err) {
+		return err
+	}
+
+	return &ValidationError{
+		baseError: &baseError{
+			code:      ErrValidation,
+			message:   "validation failed for " + reflect.TypeOf(v).String(),
+			cause:     err,
+			tableName: tableName,
+		},
+		Fields: fieldErrors(err),
+	}
+}
+
+// isStructValue returns true if v is a struct or pointer to struct
+func isStructValue(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// fieldErrors extracts field details from errors that implement Field() string,
+// or slices of such errors e.g. validator.ValidationErrors
+func fieldErrors(err error) []FieldError {
+	toFieldError := func(err error) (FieldError, bool) {
+		f, ok := err.(fielder)
+		if !ok {
+			return FieldError{}, false
+		}
+		fe := FieldError{
+			Field:   f.Field(),
+			Message: err.Error(),
+		}
+		if t, ok := err.(tagger); ok {
+			fe.Tag = t.Tag()
+		}
+		return fe, true
+	}
+
+	if fe, ok := toFieldError(err); ok {
+		return []FieldError{fe}
+	}
+
+	var fields []FieldError
+	if v := reflect.ValueOf(err); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if e, ok := v.Index(i).Interface().(error); ok {
+				if fe, ok := toFieldError(e); ok {
+					fields = append(fields, fe)
+				}
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fieldErr mimics validator.FieldError from github.com/go-playground/validator
+type fieldErr struct {
+	field string
+	tag   string
+}
+
+func (f fieldErr) Error() string { return f.field + " failed on " + f.tag }
+func (f fieldErr) Field() string { return f.field }
+func (f fieldErr) Tag() string   { return f.tag }
+
+// fieldErrs mimics validator.ValidationErrors
+type fieldErrs []fieldErr
+
+func (f fieldErrs) Error() string { return "validation failed" }
+
+func requireField(v interface{}) error {
+	if p, ok := v.(*PutTable); ok && p.Field == "" {
+		return fieldErrs{{field: "Field", tag: "required"}}
+	}
+	if p, ok := v.(PutTable); ok && p.Field == "" {
+		return fieldErrs{{field: "Field", tag: "required"}}
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	var (
+		mock  = &Mock{}
+		db    = New(mock).WithValidator(ValidatorFunc(requireField))
+		table = db.MustTable("example", PutTable{})
+	)
+
+	t.Run("put ok", func(t *testing.T) {
+		err := table.Put(PutTable{ID: "abc", Field: "def"}).Run()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("put fails", func(t *testing.T) {
+		mock.putInput = nil
+		err := table.Put(PutTable{ID: "abc"}).Run()
+		if !IsValidationError(err) {
+			t.Fatalf("got %v; want ErrValidation", err)
+		}
+		if mock.putInput != nil {
+			t.Fatalf("got %v; want nil", mock.putInput)
+		}
+
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("got %T; want *ValidationError", err)
+		}
+		want := []FieldError{{Field: "Field", Tag: "required", Message: "Field failed on required"}}
+		if got := ve.Fields; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+		if got, want := ve.TableName(), "example"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("update struct value", func(t *testing.T) {
+		err := table.Update("abc").Set("#Field = ?", PutTable{}).Run()
+		if !IsValidationError(err) {
+			t.Fatalf("got %v; want ErrValidation", err)
+		}
+	})
+
+	t.Run("update scalar value", func(t *testing.T) {
+		err := table.Update("abc").Set("#Field = ?", "").Run()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+}
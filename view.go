@@ -0,0 +1,89 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"fmt"
+)
+
+// View returns a copy of the table for an alternate, typically narrower, model of the
+// items in the table e.g.
+//
+//	summaries := orders.MustView(OrderSummary{})
+//
+// Keys and indexes are those of the table so the model need not declare them.  Names in
+// expressions resolve against the model first and then the table model.  Gets and
+// Queries through the view fetch only the attributes of the model and the table keys.
+// Defaults and auto keys are taken from the model.
+func (t *Table) View(model interface{}) (*Table, error) {
+	view, err := inspect(t.tableName, model)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create View: %v", err)
+	}
+
+	spec := *t.spec
+	spec.Attributes = view.Attributes
+	spec.AutoKeys = view.AutoKeys
+	spec.Defaults = view.Defaults
+
+	var projection []string
+	for _, attr := range view.Attributes {
+		projection = append(projection, attr.AttributeName)
+	}
+	for _, key := range []*keySpec{t.spec.HashKey, t.spec.RangeKey} {
+		if key != nil && !containsString(projection, key.AttributeName) {
+			projection = append(projection, key.AttributeName)
+		}
+	}
+
+	// resolve names not defined by the model against the table model
+	for _, attr := range t.spec.Attributes {
+		if !hasAttribute(view.Attributes, attr.AttributeName) {
+			spec.Attributes = append(spec.Attributes, attr)
+		}
+	}
+
+	dup := t.clone()
+	dup.spec = &spec
+	dup.view = projection
+	return dup, nil
+}
+
+// MustView is View, but panics if the view cannot be created
+func (t *Table) MustView(model interface{}) *Table {
+	view, err := t.View(model)
+	if err != nil {
+		panic(err)
+	}
+	return view
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAttribute(attrs []*attributeSpec, name string) bool {
+	for _, attr := range attrs {
+		if attr.AttributeName == name {
+			return true
+		}
+	}
+	return false
+}
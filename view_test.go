@@ -0,0 +1,94 @@
+// Copyright 2020 Matt Ho
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type ViewOrder struct {
+	ID     string `ddb:"hash"`
+	Seq    int64  `ddb:"range"`
+	Total  int64
+	Status string `dynamodbav:"status"`
+	Notes  string
+}
+
+type ViewSummary struct {
+	Status string `dynamodbav:"status"`
+	Total  int64
+}
+
+func TestTable_View(t *testing.T) {
+	var (
+		table = New(nil).MustTable("orders", ViewOrder{})
+		view  = table.MustView(ViewSummary{})
+	)
+
+	t.Run("query", func(t *testing.T) {
+		input, err := view.Query("#ID = ?", "abc").Filter("#Notes <> ?", "x").QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ProjectionExpression), "#n1, #n2, #n3, #n4"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		for placeholder, want := range map[string]string{"#n1": "status", "#n3": "ID", "#n4": "Seq", "#n5": "Notes"} {
+			if got := aws.StringValue(input.ExpressionAttributeNames[placeholder]); got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		input, err := view.Query("#ID = ?", "abc").Filter("#Notes <> ?", "x").Select(dynamodb.SelectCount).QueryInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		assertEqual(t, input, "testdata/query_view_count.json")
+	})
+
+	t.Run("get", func(t *testing.T) {
+		input, err := view.Get("abc").Range(1).GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if got, want := aws.StringValue(input.ProjectionExpression), "#n1, #n2, #n3, #n4"; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		if got, want := len(input.Key), 2; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	})
+
+	t.Run("table unchanged", func(t *testing.T) {
+		input, err := table.Get("abc").Range(1).GetItemInput()
+		if err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if input.ProjectionExpression != nil {
+			t.Fatalf("got %v; want nil", *input.ProjectionExpression)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := table.View("nope"); err == nil {
+			t.Fatalf("got nil; want err")
+		}
+	})
+}